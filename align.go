@@ -0,0 +1,89 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// AlignColumns returns a Transformer that aligns tab-delimited fields by
+// replacing each tab with spaces, padding the field it terminates to
+// columnWidth runes. Fields that are columnWidth runes or wider are not
+// truncated, but followed by a single space. Tabs at the end of a line are
+// removed, so that lines do not end in spaces.
+func AlignColumns(columnWidth int) Transformer {
+	return NewTransformer(&alignColumns{columnWidth: columnWidth})
+}
+
+type alignColumns struct {
+	columnWidth int
+	// width is the number of runes written for the current field.
+	width int
+}
+
+func (a *alignColumns) Reset() { a.width = 0 }
+
+func (a *alignColumns) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if r != '\t' {
+		if s.WriteRune(r) {
+			a.width++
+			if r == '\n' || r == '\r' {
+				a.width = 0
+			}
+		}
+		return
+	}
+
+	// Empty fields are padded to the full column width.
+	n := 1
+	r, size := s.ReadRune()
+	for ; r == '\t'; n++ {
+		r, size = s.ReadRune()
+	}
+	s.UnreadRune()
+	if size == 0 || r == '\n' || r == '\r' {
+		// The rest of the line is empty, or ReadRune has set ErrShortSrc if
+		// more input may follow.
+		return
+	}
+	pad := a.columnWidth - a.width
+	if pad < 1 {
+		pad = 1
+	}
+	if !s.WriteRuneN(' ', pad+(n-1)*a.columnWidth) {
+		return
+	}
+	a.width = 0
+}
+
+// NewTabExpander returns a Rewriter that replaces each tab with one or more
+// spaces to advance to the next tab stop. Tab stops are tabWidth runes apart.
+// Each rune counts as a single column, and LF and CR start a new line. It
+// panics if tabWidth is less than 1.
+func NewTabExpander(tabWidth int) Rewriter {
+	if tabWidth < 1 {
+		panic("textutil: tab width less than 1")
+	}
+	return &tabExpander{tabWidth: tabWidth}
+}
+
+type tabExpander struct {
+	tabWidth int
+	// col is the column of the next rune relative to the previous tab stop.
+	col int
+}
+
+func (t *tabExpander) Reset() { t.col = 0 }
+
+func (t *tabExpander) Rewrite(s State) {
+	switch r, _ := s.ReadRune(); {
+	case r == '\t':
+		if s.WriteRuneN(' ', t.tabWidth-t.col) {
+			t.col = 0
+		}
+	case !s.WriteRune(r):
+	case r == '\n' || r == '\r':
+		t.col = 0
+	default:
+		t.col = (t.col + 1) % t.tabWidth
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestAlignColumns(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "pad short fields",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\tbc\td\nefg\th\ti\n",
+		out:     "a    bc   d\nefg  h    i\n",
+		outFull: "a    bc   d\nefg  h    i\n",
+		t:       AlignColumns(5),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "wide fields",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abcdef\tx\nabc\ty",
+		out:     "abcdef x\nabc  y",
+		outFull: "abcdef x\nabc  y",
+		t:       AlignColumns(5),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("abcdef"),
+	}, {
+		desc:    "rune positions",
+		szDst:   large,
+		atEOF:   true,
+		in:      "héllo\tx\n日本\ty",
+		out:     "héllo  x\n日本     y",
+		outFull: "héllo  x\n日本     y",
+		t:       AlignColumns(7),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("héllo"),
+	}, {
+		desc:    "empty fields",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\t\tb\n\tc",
+		out:     "a     b\n   c",
+		outFull: "a     b\n   c",
+		t:       AlignColumns(3),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "no trailing spaces",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\tb\t\t\nc\t\r\nd\t",
+		out:     "a  b\nc\r\nd",
+		outFull: "a  b\nc\r\nd",
+		t:       AlignColumns(3),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "tabs at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "ab\t\t",
+		out:     "ab",
+		outFull: "ab",
+		err:     transform.ErrShortSrc,
+		t:       AlignColumns(4),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   2,
+	}, {
+		desc:    "short destination",
+		szDst:   4,
+		atEOF:   true,
+		in:      "ab\tc",
+		out:     "ab",
+		outFull: "ab   c",
+		err:     transform.ErrShortDst,
+		t:       AlignColumns(5),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestTabExpander(t *testing.T) {
+	expand := func(n int) Transformer { return NewTransformer(NewTabExpander(n)) }
+	testCases := []transformTest{{
+		desc:    "tab stops",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\ta\tbc\tdef\tghij\tk",
+		out:     "    a   bc  def ghij    k",
+		outFull: "    a   bc  def ghij    k",
+		t:       expand(4),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "multi-byte runes",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u00e9\u00f8\tx\n\u2208\U0001030f\t\ty",
+		out:     "\u00e9\u00f8  x\n\u2208\U0001030f      y",
+		outFull: "\u00e9\u00f8  x\n\u2208\U0001030f      y",
+		t:       expand(4),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("\u00e9\u00f8"),
+	}, {
+		desc:    "new lines",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ab\n\tc\r\td",
+		out:     "ab\n   c\r   d",
+		outFull: "ab\n   c\r   d",
+		t:       expand(3),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "width 1",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\tb\t\t",
+		out:     "a b  ",
+		outFull: "a b  ",
+		t:       expand(1),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no tabs",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc def",
+		out:     "abc def",
+		outFull: "abc def",
+		t:       expand(8),
+	}, {
+		desc:    "short destination",
+		szDst:   4,
+		atEOF:   true,
+		in:      "ab\tc",
+		out:     "ab",
+		outFull: "ab      c",
+		err:     transform.ErrShortDst,
+		t:       expand(8),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestTabExpanderInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("did not panic")
+		}
+	}()
+	NewTabExpander(0)
+}
@@ -0,0 +1,137 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode/utf8"
+
+// NewANSIStripper returns a Rewriter that removes ANSI terminal escape
+// sequences: control sequences (CSI) such as "\x1b[32m" and "\x1b[2J",
+// operating system commands (OSC) terminated by BEL or "\x1b\\", and escape
+// sequences like "\x1b=" and "\x1b(B" that consist of an escape character,
+// optional intermediate bytes and a final byte.
+//
+// An escape character within a sequence discards the sequence so far and
+// starts a new one. Any other sequence that does not match these patterns, or
+// that is not completed before the end of input, is written as is.
+func NewANSIStripper() Rewriter {
+	return &ansiStripper{}
+}
+
+type ansiState int
+
+const (
+	ansiGround       ansiState = iota // not in an escape sequence
+	ansiEsc                           // after the escape character
+	ansiCSI                           // in a control sequence
+	ansiIntermediate                  // after an intermediate byte
+	ansiOSC                           // in an operating system command
+	ansiOSCEsc                        // after an escape character in an OSC
+	ansiFlush                         // writing buf as is
+)
+
+// maxEscapeLen is the maximum length of an escape sequence. Longer sequences
+// are written as is.
+const maxEscapeLen = 256
+
+type ansiStripper struct {
+	state ansiState
+	// buf holds the escape sequence read so far, so that it can be written if
+	// it turns out to be invalid.
+	buf []byte
+	// n is the number of bytes of buf written so far in the ansiFlush state.
+	n int
+}
+
+func (a *ansiStripper) Reset() {
+	a.state = ansiGround
+	a.buf = a.buf[:0]
+	a.n = 0
+}
+
+func (a *ansiStripper) Rewrite(s State) {
+	if a.state == ansiFlush {
+		a.flush(s)
+		return
+	}
+	r, _ := s.ReadRune()
+
+	// The state is only updated once all writes have succeeded. buf always
+	// starts with the escape character, so overwriting it is harmless.
+	state, buf := a.state, a.buf
+	if state == ansiOSCEsc {
+		if r == '\\' {
+			a.state, a.buf = ansiGround, buf[:0]
+			return
+		}
+		// The escape character starts a new sequence.
+		state, buf = ansiEsc, buf[:1]
+	}
+	switch {
+	case state == ansiOSC && r == 0x1b:
+		state, buf = ansiOSCEsc, append(buf, 0x1b)
+	case r == 0x1b:
+		state, buf = ansiEsc, append(buf[:0], 0x1b)
+	case state == ansiGround:
+		s.WriteRune(r)
+		return
+	case state == ansiOSC && r == '\a':
+		state, buf = ansiGround, buf[:0]
+	case state == ansiOSC:
+		buf = utf8.AppendRune(buf, r)
+	case state == ansiEsc && r == '[':
+		state, buf = ansiCSI, append(buf, '[')
+	case state == ansiEsc && r == ']':
+		state, buf = ansiOSC, append(buf, ']')
+	case state == ansiCSI && 0x20 <= r && r <= 0x3f,
+		state != ansiCSI && 0x20 <= r && r <= 0x2f:
+		// Parameter or intermediate byte.
+		buf = append(buf, byte(r))
+		if state == ansiEsc {
+			state = ansiIntermediate
+		}
+	case state == ansiCSI && 0x40 <= r && r <= 0x7e,
+		state != ansiCSI && 0x30 <= r && r <= 0x7e:
+		// Final byte.
+		state, buf = ansiGround, buf[:0]
+	default:
+		// Not a valid escape sequence.
+		a.startFlush(s, utf8.AppendRune(buf, r))
+		return
+	}
+
+	if state != ansiGround {
+		// Write the sequence if it is too long or incomplete at the end of
+		// input. Otherwise PeekRune sets ErrShortSrc at the end of a buffer,
+		// so that we will be called again once more input is available.
+		if _, size := s.PeekRune(); size == 0 && !s.IsAtEOF() {
+			return
+		} else if size == 0 || len(buf) >= maxEscapeLen {
+			a.startFlush(s, buf)
+			return
+		}
+	}
+	a.state, a.buf = state, buf
+}
+
+// startFlush starts writing buf, which includes the rune just read. The rune
+// is unread to ensure Rewrite gets called again: the bytes of buf are written
+// one rune per call to Rewrite, so that writing a long sequence does not
+// require a large destination buffer, and the rune is read again along with
+// the last one.
+func (a *ansiStripper) startFlush(s State, buf []byte) {
+	s.UnreadRune()
+	a.state, a.buf, a.n = ansiFlush, buf, 0
+}
+
+func (a *ansiStripper) flush(s State) {
+	_, size := utf8.DecodeRune(a.buf[a.n:])
+	if !s.WriteBytes(a.buf[a.n : a.n+size]) {
+		return
+	}
+	if a.n += size; a.n == len(a.buf) {
+		s.ReadRune()
+		a.state, a.buf, a.n = ansiGround, a.buf[:0], 0
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestANSIStripper(t *testing.T) {
+	strip := func() Transformer { return NewTransformer(NewANSIStripper()) }
+	testCases := []transformTest{{
+		desc:    "CSI",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x1b[32mb\x1b[0m\x1b[2J\x1b[1;31mc\x1b[?25l",
+		out:     "abc",
+		outFull: "abc",
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "OSC",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x1b]0;title\x07b\x1b]8;;http://example.com\x1b\\link\x1b]8;;\x1b\\",
+		out:     "ablink",
+		outFull: "ablink",
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "simple sequences",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\x1b=a\x1b>b\x1b(Bc\x1b7\x1b8",
+		out:     "abc",
+		outFull: "abc",
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "nested",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x1b[31\x1b[0mb\x1b]0;x\x1b[1mc\x1b\x1b=d",
+		out:     "abcd",
+		outFull: "abcd",
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "invalid",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x1b\nb\x1b[3\u00e9c\x1b(\x01d",
+		out:     "a\x1b\nb\x1b[3\u00e9c\x1b(\x01d",
+		outFull: "a\x1b\nb\x1b[3\u00e9c\x1b(\x01d",
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "incomplete at end of input",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x1b[3",
+		out:     "a\x1b[3",
+		outFull: "a\x1b[3",
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "incomplete at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a\x1b[3",
+		out:     "a",
+		outFull: "a\x1b[3",
+		err:     transform.ErrShortSrc,
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "escape at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "ab\x1b",
+		out:     "ab",
+		outFull: "ab\x1b",
+		err:     transform.ErrShortSrc,
+		t:       strip(),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   2,
+	}, {
+		desc:    "no escapes",
+		szDst:   large,
+		atEOF:   true,
+		in:      "H\u00e9llo [w\u00f8rld]!",
+		out:     "H\u00e9llo [w\u00f8rld]!",
+		outFull: "H\u00e9llo [w\u00f8rld]!",
+		t:       strip(),
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "ab\x1b[1\u00e9",
+		out:     "ab\x1b",
+		outFull: "ab\x1b[1\u00e9",
+		err:     transform.ErrShortDst,
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	in := "\x1b[1mbold\x1b[0m \x1b]0;t\x1b\\x \x1b[3"
+	for _, sz := range [][2]int{{1, 4}, {2, 3}, {5, 8}} {
+		got, err := transformChunks(strip(), in, sz[0], sz[1])
+		if want := "bold x \x1b[3"; got != want || err != nil {
+			t.Errorf("%v: got %q, %v; want %q, <nil>", sz, got, err, want)
+		}
+	}
+
+	long := "\x1b]" + strings.Repeat("x", maxEscapeLen)
+	if got := strip().String(long + "\x07a"); got != long+"\x07a" {
+		t.Errorf("long sequence: got %d bytes; want %d", len(got), len(long)+2)
+	}
+
+	// Sequences that are written as is only require room for a single rune.
+	for _, in := range []string{
+		long + "\x07a",
+		"x\x1b[" + strings.Repeat("1", maxEscapeLen-3) + "\u00e9tail",
+		"x\x1b]" + strings.Repeat("\u00e9", maxEscapeLen/3),
+		"x\x1b[" + strings.Repeat("1", maxEscapeLen/2),
+	} {
+		for _, sz := range [][2]int{{1, 4}, {7, 4}, {100, 5}} {
+			if got, err := transformChunks(strip(), in, sz[0], sz[1]); got != in || err != nil {
+				t.Errorf("%d bytes:%v: got %d bytes, %v; want %d bytes, <nil>", len(in), sz, len(got), err, len(in))
+			}
+		}
+	}
+	in = "x\x1b[" + strings.Repeat("1", 4093) + "\u00e9tail"
+	got, err := ioutil.ReadAll(strip().NewReader(strings.NewReader(in)))
+	if string(got) != in || err != nil {
+		t.Errorf("NewReader: got %d bytes, %v; want %d bytes, <nil>", len(got), err, len(in))
+	}
+}
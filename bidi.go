@@ -0,0 +1,143 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// A BiDiDirection selects the directional isolate used by BiDiIsolate.
+type BiDiDirection int
+
+const (
+	// LeftToRight isolates text with LEFT-TO-RIGHT ISOLATE (U+2066).
+	LeftToRight BiDiDirection = iota
+
+	// RightToLeft isolates text with RIGHT-TO-LEFT ISOLATE (U+2067).
+	RightToLeft
+
+	// AutoDetect isolates text with LEFT-TO-RIGHT ISOLATE or RIGHT-TO-LEFT
+	// ISOLATE depending on the first strong directional character of the
+	// text, or FIRST STRONG ISOLATE (U+2068) if it has none.
+	AutoDetect
+)
+
+const (
+	lri = '\u2066'
+	rli = '\u2067'
+	fsi = '\u2068'
+	pdi = '\u2069'
+)
+
+// BiDiIsolate returns a Transformer that wraps its input in a directional
+// isolate and POP DIRECTIONAL ISOLATE (U+2069). This keeps the bidirectional
+// text of the input from affecting the display of surrounding text, as
+// exploited in "Trojan Source" attacks. Empty input results in an empty
+// isolate.
+//
+// For AutoDetect, the input is buffered until the first strong directional
+// character is found or the end of input is reached. If no strong character is
+// found in the first maxBiDiLookahead bytes, the input is isolated with FIRST
+// STRONG ISOLATE, which leaves the detection to the display of the text.
+func BiDiIsolate(dir BiDiDirection) Transformer {
+	switch dir {
+	case LeftToRight, RightToLeft, AutoDetect:
+	default:
+		panic("textutil: invalid BiDiDirection")
+	}
+	return Transformer{&bidiIsolate{dir: dir}}
+}
+
+type bidiIsolate struct {
+	dir BiDiDirection
+
+	started, done bool
+}
+
+func (b *bidiIsolate) Reset() { b.started, b.done = false, false }
+
+func (b *bidiIsolate) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if !b.started {
+		r := rune(lri)
+		switch b.dir {
+		case RightToLeft:
+			r = rli
+		case AutoDetect:
+			var ok bool
+			if r, ok = firstStrong(src, atEOF); !ok {
+				if len(src) < maxBiDiLookahead {
+					return 0, 0, transform.ErrShortSrc
+				}
+				r = fsi
+			}
+		}
+		if len(dst) < utf8.RuneLen(r) {
+			return 0, 0, transform.ErrShortDst
+		}
+		nDst = utf8.EncodeRune(dst, r)
+		b.started = true
+	}
+	n := copy(dst[nDst:], src)
+	nDst += n
+	nSrc += n
+	if nSrc < len(src) {
+		return nDst, nSrc, transform.ErrShortDst
+	}
+	if atEOF && !b.done {
+		if len(dst)-nDst < utf8.RuneLen(pdi) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], pdi)
+		b.done = true
+	}
+	return nDst, nSrc, nil
+}
+
+// maxBiDiLookahead is the number of bytes of input after which AutoDetect
+// gives up looking for a strong directional character. It is well below the
+// buffer size of transform.Reader and transform.Writer.
+const maxBiDiLookahead = 1024
+
+// firstStrong returns the isolate initiator matching the first strong
+// directional character in src, ignoring characters within isolates. It
+// reports false if more input is needed to determine the isolate.
+func firstStrong(src []byte, atEOF bool) (r rune, ok bool) {
+	depth := 0
+	for p := 0; p < len(src); {
+		if !atEOF && !utf8.FullRune(src[p:]) {
+			return 0, false
+		}
+		prop, size := bidi.Lookup(src[p:])
+		if size == 0 {
+			size = 1
+		}
+		p += size
+		switch prop.Class() {
+		case bidi.L:
+			if depth == 0 {
+				return lri, true
+			}
+		case bidi.R, bidi.AL:
+			if depth == 0 {
+				return rli, true
+			}
+		case bidi.LRI, bidi.RLI, bidi.FSI:
+			depth++
+		case bidi.PDI:
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	return fsi, atEOF
+}
+
+// Span reports that the output always differs from the input.
+func (b *bidiIsolate) Span(src []byte, atEOF bool) (n int, err error) {
+	return 0, transform.ErrEndOfSpan
+}
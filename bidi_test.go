@@ -0,0 +1,179 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestBiDiIsolate(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "left to right",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "\u2066abc\u2069",
+		outFull: "\u2066abc\u2069",
+		t:       BiDiIsolate(LeftToRight),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "right to left",
+		szDst:   large,
+		atEOF:   true,
+		in:      "שלום",
+		out:     "\u2067שלום\u2069",
+		outFull: "\u2067שלום\u2069",
+		t:       BiDiIsolate(RightToLeft),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "empty input",
+		szDst:   large,
+		atEOF:   true,
+		in:      "",
+		out:     "\u2066\u2069",
+		outFull: "\u2066\u2069",
+		t:       BiDiIsolate(LeftToRight),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "auto-detect empty input",
+		szDst:   large,
+		atEOF:   true,
+		in:      "",
+		out:     "\u2068\u2069",
+		outFull: "\u2068\u2069",
+		t:       BiDiIsolate(AutoDetect),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "auto-detect Latin",
+		szDst:   large,
+		atEOF:   true,
+		in:      "12 abc م",
+		out:     "\u206612 abc م\u2069",
+		outFull: "\u206612 abc م\u2069",
+		t:       BiDiIsolate(AutoDetect),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "auto-detect Arabic",
+		szDst:   large,
+		atEOF:   true,
+		in:      "(مرحبا) abc",
+		out:     "\u2067(مرحبا) abc\u2069",
+		outFull: "\u2067(مرحبا) abc\u2069",
+		t:       BiDiIsolate(AutoDetect),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "auto-detect skips isolates",
+		szDst:   large,
+		atEOF:   true,
+		in:      " \u2066abc\u2069 א",
+		out:     "\u2067 \u2066abc\u2069 א\u2069",
+		outFull: "\u2067 \u2066abc\u2069 א\u2069",
+		t:       BiDiIsolate(AutoDetect),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "auto-detect no strong characters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "123",
+		out:     "\u2068123\u2069",
+		outFull: "\u2068123\u2069",
+		t:       BiDiIsolate(AutoDetect),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "auto-detect needs more input",
+		szDst:   large,
+		atEOF:   false,
+		in:      "123 ",
+		out:     "",
+		outFull: "\u2068123 \u2069",
+		err:     transform.ErrShortSrc,
+		t:       BiDiIsolate(AutoDetect),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "more input",
+		szDst:   large,
+		atEOF:   false,
+		in:      "abc",
+		out:     "\u2066abc",
+		outFull: "\u2066abc\u2069",
+		t:       BiDiIsolate(AutoDetect),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   5,
+		atEOF:   true,
+		in:      "abc",
+		out:     "\u2066ab",
+		outFull: "\u2066abc\u2069",
+		err:     transform.ErrShortDst,
+		t:       BiDiIsolate(LeftToRight),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no room for PDI",
+		szDst:   6,
+		atEOF:   true,
+		in:      "abc",
+		out:     "\u2066abc",
+		outFull: "\u2066abc\u2069",
+		err:     transform.ErrShortDst,
+		t:       BiDiIsolate(LeftToRight),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestBiDiIsolateLongNeutral(t *testing.T) {
+	in := strings.Repeat("1 ", 3000) + "\u05d0"
+	want := "\u2068" + in + "\u2069"
+	got, err := ioutil.ReadAll(BiDiIsolate(AutoDetect).NewReader(strings.NewReader(in)))
+	if string(got) != want || err != nil {
+		t.Errorf("NewReader: got %d bytes, %v; want %d bytes, <nil>", len(got), err, len(want))
+	}
+	var buf bytes.Buffer
+	w := BiDiIsolate(AutoDetect).NewWriter(&buf)
+	for p := 0; p < len(in); p += 100 {
+		q := p + 100
+		if q > len(in) {
+			q = len(in)
+		}
+		if _, err := io.WriteString(w, in[p:q]); err != nil {
+			t.Fatalf("NewWriter: Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil || buf.String() != want {
+		t.Errorf("NewWriter: got %d bytes, %v; want %d bytes, <nil>", buf.Len(), err, len(want))
+	}
+
+	// A strong character within the lookahead is still detected.
+	in = strings.Repeat(" ", maxBiDiLookahead-10) + "\u05d0"
+	if got, err := transformChunks(BiDiIsolate(AutoDetect), in, 7, 64); got != "\u2067"+in+"\u2069" || err != nil {
+		t.Errorf("chunks: got %d bytes, %v; want RLI and %d bytes", len(got), err, len(in)+6)
+	}
+}
+
+func TestBiDiIsolateReset(t *testing.T) {
+	iso := BiDiIsolate(AutoDetect)
+	for i := 0; i < 2; i++ {
+		if got, want := iso.String("abc"), "\u2066abc\u2069"; got != want {
+			t.Errorf("%d: got %+q; want %+q", i, got, want)
+		}
+	}
+	dst := make([]byte, large)
+	iso.Reset()
+	nDst, _, _ := iso.Transform(dst, []byte("א"), true)
+	iso.Reset()
+	n, _, _ := iso.Transform(dst[nDst:], []byte("א"), true)
+	if got, want := string(dst[:nDst+n]), "\u2067א\u2069\u2067א\u2069"; got != want {
+		t.Errorf("got %+q; want %+q", got, want)
+	}
+}
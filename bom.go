@@ -0,0 +1,40 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "golang.org/x/text/transform"
+
+// NewBOMStripper returns a Rewriter that removes a byte order mark (U+FEFF) at
+// the start of the input. Any later occurrences of U+FEFF, which denote a zero
+// width no-break space, are left unchanged, as is all other input.
+func NewBOMStripper() Rewriter {
+	return &bomStripper{}
+}
+
+type bomStripper struct {
+	seenFirst bool
+}
+
+func (b *bomStripper) Reset() { b.seenFirst = false }
+
+func (b *bomStripper) Rewrite(s State) {
+	if !b.seenFirst {
+		if r, _ := s.PeekRune(); r == '\ufeff' {
+			if s.IsSpan() {
+				// Removing the byte order mark ends a span. Return before a
+				// following U+FEFF matches the spanned input.
+				s.SetError(transform.ErrEndOfSpan)
+				return
+			}
+			s.Skip()
+		}
+	}
+	// Only mark the start of the input as seen once the rune following the
+	// byte order mark is written. This ensures that a call to Rewrite that
+	// fails in a Span leaves the Rewriter unchanged.
+	if r, size := s.ReadRune(); size > 0 && s.WriteRune(r) {
+		b.seenFirst = true
+	}
+}
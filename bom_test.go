@@ -0,0 +1,93 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestBOMStripper(t *testing.T) {
+	strip := func() Transformer { return NewTransformer(NewBOMStripper()) }
+	testCases := []transformTest{{
+		desc:    "leading BOM",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\ufeffHello",
+		out:     "Hello",
+		outFull: "Hello",
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "later BOMs",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\ufeffa\ufeffb",
+		out:     "a\ufeffb",
+		outFull: "a\ufeffb",
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no BOM",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\ufeffb",
+		out:     "a\ufeffb",
+		outFull: "a\ufeffb",
+		t:       strip(),
+	}, {
+		desc:    "only BOM",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\ufeff",
+		out:     "",
+		outFull: "",
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "BOM at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "\ufeff",
+		out:     "",
+		outFull: "", // Rewrite is not called for empty input.
+		err:     transform.ErrShortSrc,
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   1,
+		atEOF:   true,
+		in:      "\ufeff\u00e9",
+		out:     "",
+		outFull: "\u00e9",
+		err:     transform.ErrShortDst,
+		t:       strip(),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	// A failed Span must not affect a subsequent Transform.
+	tr := strip()
+	if n, err := tr.Span([]byte("\ufeffab"), true); n != 0 || err != transform.ErrEndOfSpan {
+		t.Errorf("Span: got %d, %v; want 0, %v", n, err, transform.ErrEndOfSpan)
+	}
+	dst := make([]byte, 10)
+	if n, _, err := tr.Transform(dst, []byte("\ufeffab"), true); string(dst[:n]) != "ab" || err != nil {
+		t.Errorf("Transform: got %q, %v; want %q, <nil>", dst[:n], err, "ab")
+	}
+	if got, err := spanTransform(tr, "\ufeff\ufeffa"); got != "\ufeffa" || err != nil {
+		t.Errorf("span then transform: got %q, %v; want %q, <nil>", got, err, "\ufeffa")
+	}
+	for _, sz := range [][2]int{{1, 3}, {2, 4}} {
+		got, err := transformChunks(tr, "\ufeff\ufeffa", sz[0], sz[1])
+		if want := "\ufeffa"; got != want || err != nil {
+			t.Errorf("%v: got %q, %v; want %q, <nil>", sz, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode"
+
+// StripBoxDrawing returns a Transformer that removes all characters from the
+// Box Drawing (U+2500–U+257F) and Block Elements (U+2580–U+259F) blocks, as
+// commonly found in the output of terminal user interfaces.
+func StripBoxDrawing() Transformer {
+	return RemoveFunc(isBoxDrawing)
+}
+
+func isBoxDrawing(r rune) bool {
+	// Most text will not contain box-drawing characters: check the lower
+	// bound first to avoid the table lookup.
+	return r >= 0x2500 && unicode.Is(boxDrawing, r)
+}
+
+var boxDrawing = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x2500, Hi: 0x257f, Stride: 1}, // Box Drawing
+		{Lo: 0x2580, Hi: 0x259f, Stride: 1}, // Block Elements
+	},
+}
@@ -0,0 +1,83 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestStripBoxDrawing(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "box",
+		szDst:   large,
+		atEOF:   true,
+		in:      "┌──┐\n│ab│\n└──┘",
+		out:     "\nab\n",
+		outFull: "\nab\n",
+		t:       StripBoxDrawing(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "heavy and double lines",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x━┃┏╋║═╔╬╳╿",
+		out:     "x",
+		outFull: "x",
+		t:       StripBoxDrawing(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "block elements",
+		szDst:   large,
+		atEOF:   true,
+		in:      "[▀▄█▌▐░▒▓▖▟] 50%",
+		out:     "[] 50%",
+		outFull: "[] 50%",
+		t:       StripBoxDrawing(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no drawing characters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Héllo ■□▪ ⎯ ─",
+		out:     "Héllo ■□▪ ⎯ ",
+		outFull: "Héllo ■□▪ ⎯ ",
+		t:       StripBoxDrawing(),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestIsBoxDrawing(t *testing.T) {
+	for r := rune(0); r < 0x3000; r++ {
+		if got, want := isBoxDrawing(r), 0x2500 <= r && r <= 0x259f; got != want {
+			t.Errorf("%U: got %v; want %v", r, got, want)
+		}
+	}
+}
+
+var boxInput = strings.Repeat("│ Thé qüick brøwn føx ├───┤ jumps øver ▒▒▒ │\n", 50)
+
+func BenchmarkStripBoxDrawing(b *testing.B) {
+	benchmarkRemove(b, StripBoxDrawing())
+}
+
+func BenchmarkStripBoxDrawingRange(b *testing.B) {
+	benchmarkRemove(b, RemoveFunc(func(r rune) bool {
+		return 0x2500 <= r && r <= 0x259f
+	}))
+}
+
+func benchmarkRemove(b *testing.B, t Transformer) {
+	src := []byte(boxInput)
+	dst := make([]byte, len(src))
+	for i := 0; i < b.N; i++ {
+		t.Transform(dst, src, true)
+	}
+}
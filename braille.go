@@ -0,0 +1,221 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode/utf8"
+
+// BrailleGrade1Encode returns a Transformer that converts ASCII letters,
+// digits and punctuation to uncontracted (Grade 1) Unified English Braille
+// using the Braille Patterns block (U+2800–U+28FF). Uppercase letters are
+// preceded by the capital indicator ⠠ and each run of digits by the numeric
+// indicator ⠼. Within a run of digits, the letters a–j, which share their
+// cells with the digits, are preceded by the Grade 1 indicator ⠰. Spaces and all
+// other runes are left unchanged.
+func BrailleGrade1Encode() Transformer {
+	return NewTransformer(&brailleEncoder{})
+}
+
+// BrailleGrade1Decode returns a Transformer that converts the output of
+// BrailleGrade1Encode back to ASCII. Braille cells that have no meaning by
+// themselves are left unchanged.
+func BrailleGrade1Decode() Transformer {
+	return NewTransformer(&brailleDecoder{})
+}
+
+const (
+	brailleCapital = '\u2820' // dots 6
+	brailleNumeric = '\u283c' // dots 3456
+	brailleGrade1  = '\u2830' // dots 56
+)
+
+// brailleLetters holds the cells for the letters a through z. The cells of
+// a through j also denote the digits 1 through 9 and 0.
+var brailleLetters = [26]rune{
+	'\u2801', // a: dots 1
+	'\u2803', // b: dots 12
+	'\u2809', // c: dots 14
+	'\u2819', // d: dots 145
+	'\u2811', // e: dots 15
+	'\u280b', // f: dots 124
+	'\u281b', // g: dots 1245
+	'\u2813', // h: dots 125
+	'\u280a', // i: dots 24
+	'\u281a', // j: dots 245
+	'\u2805', // k: dots 13
+	'\u2807', // l: dots 123
+	'\u280d', // m: dots 134
+	'\u281d', // n: dots 1345
+	'\u2815', // o: dots 135
+	'\u280f', // p: dots 1234
+	'\u281f', // q: dots 12345
+	'\u2817', // r: dots 1235
+	'\u280e', // s: dots 234
+	'\u281e', // t: dots 2345
+	'\u2825', // u: dots 136
+	'\u2827', // v: dots 1236
+	'\u283a', // w: dots 2456
+	'\u282d', // x: dots 1346
+	'\u283d', // y: dots 13456
+	'\u2835', // z: dots 1356
+}
+
+// braillePunct holds the cells for ASCII punctuation.
+var braillePunct = map[rune]string{
+	',':  "\u2802",       // dots 2
+	';':  "\u2806",       // dots 23
+	':':  "\u2812",       // dots 25
+	'.':  "\u2832",       // dots 256
+	'!':  "\u2816",       // dots 235
+	'?':  "\u2826",       // dots 236
+	'\'': "\u2804",       // dots 3
+	'-':  "\u2824",       // dots 36
+	'"':  "\u2820\u2836", // dots 6 2356
+	'(':  "\u2810\u2823", // dots 5 126
+	')':  "\u2810\u281c", // dots 5 345
+	'*':  "\u2810\u2814", // dots 5 35
+	'+':  "\u2810\u2816", // dots 5 235
+	'=':  "\u2810\u2836", // dots 5 2356
+	'[':  "\u2828\u2823", // dots 46 126
+	']':  "\u2828\u281c", // dots 46 345
+	'%':  "\u2828\u2834", // dots 46 356
+	'_':  "\u2828\u2824", // dots 46 36
+	'{':  "\u2838\u2823", // dots 456 126
+	'}':  "\u2838\u281c", // dots 456 345
+	'/':  "\u2838\u280c", // dots 456 34
+	'\\': "\u2838\u2821", // dots 456 16
+	'#':  "\u2838\u2839", // dots 456 1456
+	'|':  "\u2838\u2833", // dots 456 1256
+	'<':  "\u2808\u2823", // dots 4 126
+	'>':  "\u2808\u281c", // dots 4 345
+	'&':  "\u2808\u282f", // dots 4 12346
+	'@':  "\u2808\u2801", // dots 4 1
+	'$':  "\u2808\u280e", // dots 4 234
+	'^':  "\u2808\u2822", // dots 4 26
+	'~':  "\u2808\u2814", // dots 4 35
+	'`':  "\u2818\u2821", // dots 45 16
+}
+
+var (
+	brailleLetterDecode = map[rune]rune{}
+	braillePunctDecode  = map[string]rune{}
+)
+
+func init() {
+	for i, c := range brailleLetters {
+		brailleLetterDecode[c] = 'a' + rune(i)
+	}
+	for r, s := range braillePunct {
+		braillePunctDecode[s] = r
+	}
+}
+
+type brailleEncoder struct {
+	// inNumber is set if the last written rune was a digit.
+	inNumber bool
+}
+
+func (b *brailleEncoder) Reset() { b.inNumber = false }
+
+func (b *brailleEncoder) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	var buf [3 * utf8.UTFMax]byte
+	out := buf[:0]
+	switch {
+	case isDigit(r):
+		if !b.inNumber {
+			out = utf8.AppendRune(out, brailleNumeric)
+		}
+		out = utf8.AppendRune(out, brailleLetters[(r-'1'+10)%10])
+	case 'a' <= r && r <= 'z':
+		if b.inNumber && r <= 'j' {
+			out = utf8.AppendRune(out, brailleGrade1)
+		}
+		out = utf8.AppendRune(out, brailleLetters[r-'a'])
+	case 'A' <= r && r <= 'Z':
+		out = utf8.AppendRune(out, brailleCapital)
+		out = utf8.AppendRune(out, brailleLetters[r-'A'])
+	default:
+		if p, ok := braillePunct[r]; ok {
+			out = append(out, p...)
+		} else {
+			out = utf8.AppendRune(out, r)
+		}
+	}
+	if s.WriteBytes(out) {
+		b.inNumber = isDigit(r)
+	}
+}
+
+// isBraillePrefix reports whether r is the first cell of a two-cell sequence.
+func isBraillePrefix(r rune) bool {
+	switch r {
+	case brailleCapital, '\u2808', '\u2810', '\u2818', '\u2828', '\u2838':
+		return true
+	}
+	return false
+}
+
+type brailleDecoder struct {
+	// inNumber is set if the cells a through j denote digits.
+	inNumber bool
+}
+
+func (b *brailleDecoder) Reset() { b.inNumber = false }
+
+func (b *brailleDecoder) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if l, ok := brailleLetterDecode[r]; ok {
+		if b.inNumber && l <= 'j' {
+			s.WriteRune('0' + (l-'a'+1)%10)
+			return
+		}
+		if s.WriteRune(l) {
+			b.inNumber = false
+		}
+		return
+	}
+	switch r {
+	case brailleNumeric:
+		b.inNumber = true
+		return
+	case brailleGrade1:
+		b.inNumber = false
+		return
+	}
+	if p, ok := braillePunctDecode[string(r)]; ok {
+		r = p
+	}
+	if !isBraillePrefix(r) {
+		if s.WriteRune(r) {
+			b.inNumber = false
+		}
+		return
+	}
+
+	// Check for a two-cell sequence. If the input ends prematurely, ReadRune
+	// will have set ErrShortSrc and we will be called again with more input.
+	r2, size := s.ReadRune()
+	var buf [2 * utf8.UTFMax]byte
+	seq := utf8.AppendRune(utf8.AppendRune(buf[:0], r), r2)
+	switch p, ok := braillePunctDecode[string(seq)]; {
+	case ok:
+		if s.WriteRune(p) {
+			b.inNumber = false
+		}
+	case r == brailleCapital:
+		if l, ok := brailleLetterDecode[r2]; ok {
+			if s.WriteRune(l - 'a' + 'A') {
+				b.inNumber = false
+			}
+			return
+		}
+		fallthrough
+	default:
+		s.UnreadRune()
+		if s.WriteRune(r) && size > 0 {
+			b.inNumber = false
+		}
+	}
+}
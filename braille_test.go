@@ -0,0 +1,127 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestBraille(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "braille",
+		out:     "⠃⠗⠁⠊⠇⠇⠑",
+		outFull: "⠃⠗⠁⠊⠇⠇⠑",
+		t:       BrailleGrade1Encode(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "capitals",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hi OK",
+		out:     "⠠⠓⠊ ⠠⠕⠠⠅",
+		outFull: "⠠⠓⠊ ⠠⠕⠠⠅",
+		t:       BrailleGrade1Encode(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "digits",
+		szDst:   large,
+		atEOF:   true,
+		in:      "1890 2a 3k 4B",
+		out:     "⠼⠁⠓⠊⠚ ⠼⠃⠰⠁ ⠼⠉⠅ ⠼⠙⠠⠃",
+		outFull: "⠼⠁⠓⠊⠚ ⠼⠃⠰⠁ ⠼⠉⠅ ⠼⠙⠠⠃",
+		t:       BrailleGrade1Encode(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "punctuation",
+		szDst:   large,
+		atEOF:   true,
+		in:      `x, y; z: "(no)!"`,
+		out:     "⠭⠂ ⠽⠆ ⠵⠒ ⠠⠶⠐⠣⠝⠕⠐⠜⠖⠠⠶",
+		outFull: "⠭⠂ ⠽⠆ ⠵⠒ ⠠⠶⠐⠣⠝⠕⠐⠜⠖⠠⠶",
+		t:       BrailleGrade1Encode(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "other runes",
+		szDst:   large,
+		atEOF:   true,
+		in:      " é\n\t",
+		out:     " é\n\t",
+		outFull: " é\n\t",
+		t:       BrailleGrade1Encode(),
+	}, {
+		desc:    "decode",
+		szDst:   large,
+		atEOF:   true,
+		in:      "⠠⠓⠊⠖ ⠼⠃⠰⠁⠲",
+		out:     "Hi! 2a.",
+		outFull: "Hi! 2a.",
+		t:       BrailleGrade1Decode(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "decode two cells at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "⠁⠠",
+		out:     "a",
+		outFull: "a⠠",
+		err:     transform.ErrShortSrc,
+		t:       BrailleGrade1Decode(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "decode unknown cells",
+		szDst:   large,
+		atEOF:   true,
+		in:      "⠈⠿⠿⠐",
+		out:     "⠈⠿⠿⠐",
+		outFull: "⠈⠿⠿⠐",
+		t:       BrailleGrade1Decode(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestBrailleLetters(t *testing.T) {
+	// Dots 1 through 6 correspond to bits 0 through 5 of the Braille Patterns.
+	dots := map[rune]string{
+		'a': "1", 'b': "12", 'c': "14", 'd': "145", 'e': "15", 'f': "124",
+		'g': "1245", 'h': "125", 'i': "24", 'j': "245", 'k': "13", 'l': "123",
+		'm': "134", 'n': "1345", 'o': "135", 'p': "1234", 'q': "12345",
+		'r': "1235", 's': "234", 't': "2345", 'u': "136", 'v': "1236",
+		'w': "2456", 'x': "1346", 'y': "13456", 'z': "1356",
+	}
+	for r, d := range dots {
+		want := rune(0x2800)
+		for _, c := range d {
+			want |= 1 << uint(c-'1')
+		}
+		if got := BrailleGrade1Encode().String(string(r)); got != string(want) {
+			t.Errorf("%c: got %+q; want %+q", r, got, want)
+		}
+	}
+}
+
+func TestBrailleRoundTrip(t *testing.T) {
+	var ascii []byte
+	for c := byte(' '); c < 0x7f; c++ {
+		ascii = append(ascii, c)
+	}
+	inputs := []string{
+		string(ascii),
+		"1a2b3c 4j5k 0Z",
+		"3.14, 1,000 and 2-3",
+		"\"Hello,\" she said (twice).",
+		"a\nb\tc",
+	}
+	for _, c := range ascii {
+		inputs = append(inputs, string(c), "1"+string(c), string(c)+string(c))
+	}
+	roundTrip(t, BrailleGrade1Encode(), BrailleGrade1Decode(), inputs)
+}
@@ -0,0 +1,107 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode"
+
+// ToCamelCase returns a Transformer that converts snake_case identifiers to
+// camelCase. Each run of underscores between two letters or digits is removed
+// and the rune following it is capitalized. Leading and trailing underscores are
+// kept.
+func ToCamelCase() Transformer {
+	return NewTransformer(&camelCase{})
+}
+
+// ToPascalCase returns a Transformer that converts snake_case identifiers to
+// PascalCase. It is like ToCamelCase, but also capitalizes the first letter of
+// each identifier.
+func ToPascalCase() Transformer {
+	return NewTransformer(&camelCase{pascal: true})
+}
+
+// ToSnakeCase returns a Transformer that converts camelCase and PascalCase
+// identifiers to snake_case. Uppercase letters are lowercased and preceded by an
+// underscore unless they start an identifier. Acronyms are kept together, so
+// "HTTPServer" becomes "http_server".
+func ToSnakeCase() Transformer {
+	return NewTransformer(&snakeCase{})
+}
+
+func isIdent(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type camelCase struct {
+	pascal bool
+	// inIdent is set if the last written rune was a letter or digit.
+	inIdent bool
+}
+
+func (c *camelCase) Reset() { c.inIdent = false }
+
+func (c *camelCase) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if r != '_' {
+		if !c.inIdent && c.pascal {
+			r = unicode.ToTitle(r)
+		}
+		if s.WriteRune(r) {
+			c.inIdent = isIdent(r)
+		}
+		return
+	}
+
+	n := 1
+	r, size := s.ReadRune()
+	for ; r == '_'; n++ {
+		r, size = s.ReadRune()
+	}
+	if c.inIdent && isIdent(r) {
+		s.WriteRune(unicode.ToTitle(r))
+		return
+	}
+	s.UnreadRune()
+	for ; n > 0; n-- {
+		s.WriteRune('_')
+	}
+	// Leave the state unchanged at the end of the input, as Rewrite will be
+	// called again if more input follows.
+	if size > 0 {
+		c.inIdent = false
+	}
+}
+
+type snakeCase struct {
+	// prev is the last written rune.
+	prev rune
+}
+
+func (c *snakeCase) Reset() { c.prev = 0 }
+
+func (c *snakeCase) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if !unicode.IsUpper(r) {
+		if s.WriteRune(r) {
+			c.prev = r
+		}
+		return
+	}
+	split := false
+	switch {
+	case unicode.IsUpper(c.prev):
+		// Split off the last letter of an acronym if it starts a new word.
+		next, _ := s.ReadRune()
+		s.UnreadRune()
+		split = unicode.IsLower(next)
+	case isIdent(c.prev):
+		split = true
+	}
+	if split && !s.WriteRune('_') {
+		return
+	}
+	if s.WriteRune(unicode.ToLower(r)) {
+		c.prev = r
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestCase(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "camel",
+		szDst:   large,
+		atEOF:   true,
+		in:      "hello_world foo_bar_baz",
+		out:     "helloWorld fooBarBaz",
+		outFull: "helloWorld fooBarBaz",
+		t:       ToCamelCase(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("hello"),
+	}, {
+		desc:    "camel consecutive underscores",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a__b___c",
+		out:     "aBC",
+		outFull: "aBC",
+		t:       ToCamelCase(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "camel leading and trailing underscores",
+		szDst:   large,
+		atEOF:   true,
+		in:      "__init__ _x_y_ _",
+		out:     "__init__ _xY_ _",
+		outFull: "__init__ _xY_ _",
+		t:       ToCamelCase(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("__init__ _x"),
+	}, {
+		desc:    "camel Unicode",
+		szDst:   large,
+		atEOF:   true,
+		in:      "straße_über_ǆungla",
+		out:     "straßeÜberǅungla",
+		outFull: "straßeÜberǅungla",
+		t:       ToCamelCase(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("straße"),
+	}, {
+		desc:    "camel underscores at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "ab__",
+		out:     "ab",
+		outFull: "ab__",
+		err:     transform.ErrShortSrc,
+		t:       ToCamelCase(),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   2,
+	}, {
+		desc:    "camel short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "ab_cd",
+		out:     "ab",
+		outFull: "abCd",
+		err:     transform.ErrShortDst,
+		t:       ToCamelCase(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "pascal",
+		szDst:   large,
+		atEOF:   true,
+		in:      "hello_world, __x_y",
+		out:     "HelloWorld, __XY",
+		outFull: "HelloWorld, __XY",
+		t:       ToPascalCase(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "snake",
+		szDst:   large,
+		atEOF:   true,
+		in:      "helloWorld HelloWorld hello_world",
+		out:     "hello_world hello_world hello_world",
+		outFull: "hello_world hello_world hello_world",
+		t:       ToSnakeCase(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("hello"),
+	}, {
+		desc:    "snake acronyms",
+		szDst:   large,
+		atEOF:   true,
+		in:      "HTTPServer userID parseURL2 v2Beta",
+		out:     "http_server user_id parse_url2 v2_beta",
+		outFull: "http_server user_id parse_url2 v2_beta",
+		t:       ToSnakeCase(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "snake Unicode",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x ÉcoleNormaleΣύνολο",
+		out:     "x école_normale_σύνολο",
+		outFull: "x école_normale_σύνολο",
+		t:       ToSnakeCase(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "snake acronym at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "xHT",
+		out:     "x_h",
+		outFull: "x_ht",
+		err:     transform.ErrShortSrc,
+		t:       ToSnakeCase(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestCaseRoundTrip(t *testing.T) {
+	roundTrip(t, ToCamelCase(), ToSnakeCase(), []string{
+		"hello_world",
+		"ab_cd_ef",
+		"_private_field_",
+		"user_id = get_user_id(x)",
+	})
+}
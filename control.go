@@ -0,0 +1,64 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// VisualizeControlChars returns a Transformer that replaces each C0 control
+// character (U+0000–U+001F) with format(r). If format is nil, a control
+// character is replaced by its abbreviated name in angle brackets, such as
+// <NUL> or <LF>.
+func VisualizeControlChars(format func(r rune) string) Transformer {
+	if format == nil {
+		format = controlName
+	}
+	return NewTransformerFromFunc(func(s State) {
+		if r, _ := s.ReadRune(); r < ' ' {
+			s.WriteString(format(r))
+		} else {
+			s.WriteRune(r)
+		}
+	})
+}
+
+// NewControlCharStripper returns a Rewriter that removes all ASCII control
+// characters (U+0000–U+001F and U+007F). If keepNewlines is true, tab
+// (U+0009), LF (U+000A) and CR (U+000D) are kept. Invalid UTF-8 is replaced
+// with utf8.RuneError.
+func NewControlCharStripper(keepNewlines bool) Rewriter {
+	return rewriterFunc(func(s State) {
+		r, _ := s.PeekRune()
+		switch {
+		case r >= ' ' && r != 0x7f:
+		case keepNewlines && (r == '\t' || r == '\n' || r == '\r'):
+		default:
+			s.Skip()
+			return
+		}
+		s.ReadRune()
+		s.WriteRune(r)
+	})
+}
+
+func controlName(r rune) string {
+	return "<" + controlNames[r] + ">"
+}
+
+var controlNames = [' ']string{
+	"NUL", "SOH", "STX", "ETX", "EOT", "ENQ", "ACK", "BEL",
+	"BS", "HT", "LF", "VT", "FF", "CR", "SO", "SI",
+	"DLE", "DC1", "DC2", "DC3", "DC4", "NAK", "SYN", "ETB",
+	"CAN", "EM", "SUB", "ESC", "FS", "GS", "RS", "US",
+}
+
+// ControlPictures returns a Transformer that replaces each C0 control character
+// (U+0000–U+001F) with the corresponding symbol of the Control Pictures block
+// (U+2400–U+241F).
+func ControlPictures() Transformer {
+	return MapRune(func(r rune) rune {
+		if 0 <= r && r < ' ' {
+			return '␀' + r
+		}
+		return r
+	})
+}
@@ -0,0 +1,165 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestVisualizeControlChars(t *testing.T) {
+	var all []byte
+	for r := 0; r < ' '; r++ {
+		all = append(all, byte(r))
+	}
+	const want = "<NUL><SOH><STX><ETX><EOT><ENQ><ACK><BEL>" +
+		"<BS><HT><LF><VT><FF><CR><SO><SI>" +
+		"<DLE><DC1><DC2><DC3><DC4><NAK><SYN><ETB>" +
+		"<CAN><EM><SUB><ESC><FS><GS><RS><US>"
+
+	testCases := []transformTest{{
+		desc:    "all C0 controls",
+		szDst:   large,
+		atEOF:   true,
+		in:      string(all),
+		out:     want,
+		outFull: want,
+		t:       VisualizeControlChars(nil),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "mixed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\tb\r\n",
+		out:     "a<HT>b<CR><LF>",
+		outFull: "a<HT>b<CR><LF>",
+		t:       VisualizeControlChars(nil),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no controls",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Héllo wørld ~\u007f\u0085",
+		out:     "Héllo wørld ~\u007f\u0085",
+		outFull: "Héllo wørld ~\u007f\u0085",
+		t:       VisualizeControlChars(nil),
+	}, {
+		desc:    "short destination",
+		szDst:   6,
+		atEOF:   true,
+		in:      "a\x00\x01",
+		out:     "a<NUL>",
+		outFull: "a<NUL><SOH>",
+		err:     transform.ErrShortDst,
+		t:       VisualizeControlChars(nil),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "custom format",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x00\x1f",
+		out:     "a^@^_",
+		outFull: "a^@^_",
+		t: VisualizeControlChars(func(r rune) string {
+			return "^" + string('@'+r)
+		}),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestVisualizeControlCharsFormat(t *testing.T) {
+	var got []rune
+	tr := VisualizeControlChars(func(r rune) string {
+		got = append(got, r)
+		return fmt.Sprintf("%U", r)
+	})
+	var in []string
+	for r := rune(0); r < ' '; r++ {
+		in = append(in, string(r), "x")
+	}
+	tr.Transform(make([]byte, large), []byte(strings.Join(in, "")), true)
+	if len(got) != ' ' {
+		t.Fatalf("got %d calls; want %d", len(got), ' ')
+	}
+	for i, r := range got {
+		if r != rune(i) {
+			t.Errorf("%d: got %U; want %U", i, r, i)
+		}
+	}
+}
+
+func TestControlCharStripper(t *testing.T) {
+	strip := func(keep bool) Transformer { return NewTransformer(NewControlCharStripper(keep)) }
+	testCases := []transformTest{{
+		desc:    "strip all",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\x00a\tb\r\nc\x1b[1md\x7f",
+		out:     "abc[1md",
+		outFull: "abc[1md",
+		t:       strip(false),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "keep newlines",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\tb\r\nc\x1b[1md\x7f\x00",
+		out:     "a\tb\r\nc[1md",
+		outFull: "a\tb\r\nc[1md",
+		t:       strip(true),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "non-ASCII",
+		szDst:   large,
+		atEOF:   true,
+		in:      "H\u00e9llo \u0080\u0085\u009f\u00a0w\u00f8rld",
+		out:     "H\u00e9llo \u0080\u0085\u009f\u00a0w\u00f8rld",
+		outFull: "H\u00e9llo \u0080\u0085\u009f\u00a0w\u00f8rld",
+		t:       strip(false),
+	}, {
+		desc:    "only controls",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\x01\x02\x03",
+		out:     "",
+		outFull: "",
+		t:       strip(true),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "a\x00\u00e9",
+		out:     "a",
+		outFull: "a\u00e9",
+		err:     transform.ErrShortDst,
+		t:       strip(false),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestControlPictures(t *testing.T) {
+	for r := rune(0); r < ' '; r++ {
+		want := string(r + 0x2400)
+		if got := ControlPictures().String(string(r)); got != want {
+			t.Errorf("%U: got %q; want %q", r, got, want)
+		}
+	}
+	if got, want := ControlPictures().String("a\tb\n"), "a␉b␊"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if got, want := ControlPictures().String("a b\u007f"), "a b\u007f"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
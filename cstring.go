@@ -0,0 +1,130 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode/utf8"
+
+// CStringEscape returns a Rewriter that escapes its input for use in a C
+// string literal. It uses the escapes \a, \b, \f, \n, \r, \t, \v, \\ and \"
+// for the respective characters and three-digit octal escapes, such as \001,
+// for any other ASCII character that is not printable and for invalid UTF-8
+// bytes. Unlike hexadecimal escapes, these are never extended by the
+// characters that follow. Valid non-ASCII runes are written as is.
+func CStringEscape() Rewriter {
+	return rewriterFunc(func(s State) {
+		r, size := s.ReadRune()
+		switch esc := cEscapes[r&0x7f]; {
+		case r == utf8.RuneError && size == 1:
+			writeOctal(s, lastByte(s))
+		case r >= 0x80:
+			s.WriteRune(r)
+		case esc != 0:
+			s.WriteBytes([]byte{'\\', esc})
+		case r < ' ' || r == 0x7f:
+			writeOctal(s, byte(r))
+		default:
+			s.WriteRune(r)
+		}
+	})
+}
+
+func writeOctal(s State, c byte) {
+	s.WriteBytes([]byte{'\\', '0' + c>>6, '0' + c>>3&7, '0' + c&7})
+}
+
+const hexDigits = "0123456789abcdef"
+
+// cEscapes maps ASCII characters to the letter of their C escape sequence.
+var cEscapes = [0x80]byte{
+	'\a': 'a',
+	'\b': 'b',
+	'\f': 'f',
+	'\n': 'n',
+	'\r': 'r',
+	'\t': 't',
+	'\v': 'v',
+	'\\': '\\',
+	'"':  '"',
+}
+
+// CStringUnescape returns a Rewriter that interprets the escape sequences of C
+// string literals: the simple escapes \a, \b, \f, \n, \r, \t, \v, \\, \", \'
+// and \?, octal escapes of up to three digits and hexadecimal escapes of one or
+// two digits. Escapes denoting a value of 0x80 or higher are written as a
+// single byte. An octal escape denoting a value above 255 results in
+// ErrInvalidEscape. Other invalid escape sequences are written as is.
+func CStringUnescape() Rewriter {
+	return rewriterFunc(func(s State) {
+		if r, _ := s.ReadRune(); r != '\\' {
+			s.WriteRune(r)
+			return
+		}
+		r, size := s.ReadRune()
+		switch {
+		case size == 0:
+			// A backslash at the end of the input.
+			s.WriteRune('\\')
+		case r == '\'' || r == '?':
+			s.WriteRune(r)
+		case r < 0x80 && cUnescapes[r] != 0:
+			s.WriteBytes([]byte{cUnescapes[r]})
+		case '0' <= r && r <= '7':
+			c := int(r - '0')
+			for i := 0; i < 2; i++ {
+				r, _ := s.ReadRune()
+				if r < '0' || '7' < r {
+					s.UnreadRune()
+					break
+				}
+				c = c<<3 | int(r-'0')
+			}
+			if c > 0xff {
+				s.SetError(ErrInvalidEscape)
+				return
+			}
+			s.WriteBytes([]byte{byte(c)})
+		case r == 'x':
+			h, _ := s.ReadRune()
+			c, ok := unhex(h)
+			if !ok {
+				s.UnreadRune()
+				s.WriteString(`\x`)
+				return
+			}
+			h, _ = s.ReadRune()
+			if d, ok := unhex(h); ok {
+				c = c<<4 | d
+			} else {
+				s.UnreadRune()
+			}
+			s.WriteBytes([]byte{c})
+		default:
+			s.WriteRune('\\')
+			s.WriteRune(r)
+		}
+	})
+}
+
+// cUnescapes is the inverse of cEscapes.
+var cUnescapes = func() (m [0x80]byte) {
+	for c, esc := range cEscapes {
+		if esc != 0 {
+			m[esc] = byte(c)
+		}
+	}
+	return m
+}()
+
+func unhex(r rune) (c byte, ok bool) {
+	switch {
+	case '0' <= r && r <= '9':
+		return byte(r - '0'), true
+	case 'a' <= r && r <= 'f':
+		return byte(r - 'a' + 10), true
+	case 'A' <= r && r <= 'F':
+		return byte(r - 'A' + 10), true
+	}
+	return 0, false
+}
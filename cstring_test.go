@@ -0,0 +1,125 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestCString(t *testing.T) {
+	escape := NewTransformer(CStringEscape())
+	unescape := NewTransformer(CStringUnescape())
+
+	testCases := []transformTest{{
+		desc:    "escape simple",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\n\r\t\\\"b'\a\b\f\v",
+		out:     `a\n\r\t\\\"b'\a\b\f\v`,
+		outFull: `a\n\r\t\\\"b'\a\b\f\v`,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "escape hex",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x00\x1b\x7f\x01aé",
+		out:     `a\000\033\177\001aé`,
+		outFull: `a\000\033\177\001aé`,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "escape invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xff\xc3b\ufffd",
+		out:     "a\\377\\303b\ufffd",
+		outFull: "a\\377\\303b\ufffd",
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "escape printable ASCII",
+		szDst:   large,
+		atEOF:   true,
+		in:      " !#$%&'()*+,-./09:;<=>?@AZ[]^_`az{|}~",
+		out:     " !#$%&'()*+,-./09:;<=>?@AZ[]^_`az{|}~",
+		outFull: " !#$%&'()*+,-./09:;<=>?@AZ[]^_`az{|}~",
+		t:       escape,
+	}, {
+		desc:    "unescape simple",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a\n\r\t\\\"\'\?\a\b\f\v`,
+		out:     "a\n\r\t\\\"'?\a\b\f\v",
+		outFull: "a\n\r\t\\\"'?\a\b\f\v",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unescape octal",
+		szDst:   large,
+		atEOF:   true,
+		in:      `\0-\101\1012\18`,
+		out:     "\x00-AA2\x018",
+		outFull: "\x00-AA2\x018",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unescape hex",
+		szDst:   large,
+		atEOF:   true,
+		in:      `\x41\x6a\x6A\x7\x412\xffz`,
+		out:     "Ajj\aA2\xffz",
+		outFull: "Ajj\aA2\xffz",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unescape octal overflow",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a\377\400`,
+		out:     "a\xff",
+		outFull: "a\xff",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "unescape invalid",
+		szDst:   large,
+		atEOF:   true,
+		in:      `\q\xg\`,
+		out:     `\q\xg\`,
+		outFull: `\q\xg\`,
+		t:       unescape,
+	}, {
+		desc:    "unescape at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      `a\x4`,
+		out:     "a",
+		outFull: "a\x04",
+		err:     transform.ErrShortSrc,
+		t:       unescape,
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	var all []byte
+	for c := 0; c < 0x80; c++ {
+		all = append(all, byte(c))
+	}
+	roundTrip(t, escape, unescape, []string{
+		string(all),
+		"Héllo, wørld!\n",
+		"\x01a\x00123",
+		"\x80\xff\xc3\xa9\xed\xa0\x80",
+	})
+}
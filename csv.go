@@ -0,0 +1,256 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// CSVToTSV returns a Transformer that converts RFC 4180 comma-separated values
+// to tab-separated values. Commas separating fields are replaced with tabs and
+// the quotes around quoted fields are removed. Tabs, carriage returns, line
+// feeds and backslashes within fields are escaped as \t, \r, \n, and \\.
+func CSVToTSV() Transformer {
+	return NewTransformer(&csvToTSV{fieldStart: true})
+}
+
+// TSVToCSV returns a Transformer that converts tab-separated values, as
+// produced by CSVToTSV, to RFC 4180 comma-separated values. Fields that
+// contain a comma, quote, or line break after unescaping are quoted. Fields
+// longer than 256 bytes are always quoted, so that they need not be buffered
+// to decide.
+func TSVToCSV() Transformer {
+	return NewTransformer(&tsvToCSV{})
+}
+
+// maxCSVLookahead is the number of bytes of a field TSVToCSV buffers to decide
+// whether the field needs to be quoted.
+const maxCSVLookahead = 256
+
+type csvToTSV struct {
+	// fieldStart is set if the next rune is the first rune of a field.
+	fieldStart bool
+	// inQuote is set if the next rune is part of a quoted field.
+	inQuote bool
+}
+
+func (c *csvToTSV) Reset() { *c = csvToTSV{fieldStart: true} }
+
+// tsvEscape returns the escape sequence for r in a TSV field.
+func tsvEscape(r rune) (esc string, ok bool) {
+	switch r {
+	case '\t':
+		return `\t`, true
+	case '\r':
+		return `\r`, true
+	case '\n':
+		return `\n`, true
+	case '\\':
+		return `\\`, true
+	}
+	return "", false
+}
+
+func (c *csvToTSV) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if c.inQuote {
+		if r != '"' {
+			if esc, ok := tsvEscape(r); ok {
+				s.WriteString(esc)
+			} else {
+				s.WriteRune(r)
+			}
+			return
+		}
+		// A doubled quote is a literal quote; anything else ends the field.
+		// The state is left unchanged at the end of the input, in which case
+		// Rewrite may be called again with more input.
+		switch r, size := s.ReadRune(); {
+		case size == 0:
+		case r == '"':
+			s.WriteRune('"')
+		default:
+			s.UnreadRune()
+			c.inQuote = false
+		}
+		return
+	}
+	switch r {
+	case '"':
+		if c.fieldStart {
+			if s.IsSpan() {
+				// Removing the quote ends a span.
+				s.SetError(transform.ErrEndOfSpan)
+				return
+			}
+			c.inQuote = true
+			c.fieldStart = false
+			return
+		}
+	case ',':
+		if s.WriteRune('\t') {
+			c.fieldStart = true
+		}
+		return
+	case '\r', '\n':
+		if s.WriteRune(r) {
+			c.fieldStart = true
+		}
+		return
+	}
+	ok := false
+	if esc, isEsc := tsvEscape(r); isEsc {
+		ok = s.WriteString(esc)
+	} else {
+		ok = s.WriteRune(r)
+	}
+	if ok {
+		c.fieldStart = false
+	}
+}
+
+type tsvToCSV struct {
+	// field buffers the unescaped start of the current field so that it can
+	// be quoted if necessary.
+	field []byte
+	// inQuote is set if the opening quote of the current field has been
+	// written, in which case the remainder of the field is written as it is
+	// read.
+	inQuote bool
+}
+
+func (c *tsvToCSV) Reset() { c.inQuote = false }
+
+// Rewrite converts a single field, including its terminating tab or line
+// break, if any, or the start of a long field. In a long field, each further
+// call converts a single rune.
+func (c *tsvToCSV) Rewrite(s State) {
+	if c.inQuote {
+		c.rewriteQuoted(s)
+		return
+	}
+	c.field = c.field[:0]
+	quote := false
+	for {
+		r, size, term := readTSV(s)
+		switch {
+		case size == 0:
+			c.writeField(s, quote)
+			return
+		case term:
+			if c.writeField(s, quote) {
+				writeCSVTerm(s, r)
+			}
+			return
+		}
+		switch r {
+		case ',', '"', '\r', '\n':
+			quote = true
+		}
+		c.field = utf8.AppendRune(c.field, r)
+		if len(c.field) >= maxCSVLookahead {
+			if c.writeQuoted(s) {
+				c.inQuote = true
+			}
+			return
+		}
+	}
+}
+
+// rewriteQuoted converts the next rune of a long field, closing the quotes at
+// the end of the field.
+func (c *tsvToCSV) rewriteQuoted(s State) {
+	r, size, term := readTSV(s)
+	switch {
+	case size == 0:
+		// Only invalid bytes that are skipped remained.
+		if s.IsAtEOF() && s.WriteRune('"') {
+			c.inQuote = false
+		}
+		return
+	case term:
+		if s.WriteRune('"') && writeCSVTerm(s, r) {
+			c.inQuote = false
+		}
+		return
+	case r == '"':
+		if !s.WriteString(`""`) {
+			return
+		}
+	default:
+		if !s.WriteRune(r) {
+			return
+		}
+	}
+	// Close the quotes at the end of input. If more input may follow,
+	// PeekRune sets ErrShortSrc and we will be called again.
+	if _, size := s.PeekRune(); size == 0 && s.IsAtEOF() && s.WriteRune('"') {
+		c.inQuote = false
+	}
+}
+
+// readTSV reads the next rune of a field, interpreting escape sequences, and
+// reports whether it is an unescaped tab or line break, which terminates the
+// field. A size of 0 indicates the end of the source buffer.
+func readTSV(s State) (r rune, size int, term bool) {
+	r, size = s.ReadRune()
+	switch r {
+	case '\t', '\r', '\n':
+		return r, size, true
+	case '\\':
+		e, n := s.ReadRune()
+		switch e {
+		case 't':
+			r = '\t'
+		case 'r':
+			r = '\r'
+		case 'n':
+			r = '\n'
+		case '\\':
+		default:
+			// Not an escape sequence: keep the backslash.
+			s.UnreadRune()
+			n = 0
+		}
+		size += n
+	}
+	return r, size, false
+}
+
+// writeCSVTerm writes the CSV equivalent of the field terminator r.
+func writeCSVTerm(s State, r rune) bool {
+	if r == '\t' {
+		r = ','
+	}
+	return s.WriteRune(r)
+}
+
+func (c *tsvToCSV) writeField(s State, quote bool) bool {
+	if !quote {
+		return s.WriteBytes(c.field)
+	}
+	return c.writeQuoted(s) && s.WriteRune('"')
+}
+
+// writeQuoted writes the opening quote and the buffered field, doubling any
+// quotes.
+func (c *tsvToCSV) writeQuoted(s State) bool {
+	if !s.WriteRune('"') {
+		return false
+	}
+	start := 0
+	for i, b := range c.field {
+		if b == '"' {
+			// Write the quote twice.
+			if !s.WriteBytes(c.field[start : i+1]) {
+				return false
+			}
+			start = i
+		}
+	}
+	return s.WriteBytes(c.field[start:])
+}
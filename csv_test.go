@@ -0,0 +1,145 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestCSV(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "unquoted fields",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a,b,c\nd,e,f\n",
+		out:     "a\tb\tc\nd\te\tf\n",
+		outFull: "a\tb\tc\nd\te\tf\n",
+		t:       CSVToTSV(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "quoted fields with commas and quotes",
+		szDst:   large,
+		atEOF:   true,
+		in:      `x,"a,b","say ""hi"""`,
+		out:     "x\ta,b\tsay \"hi\"",
+		outFull: "x\ta,b\tsay \"hi\"",
+		t:       CSVToTSV(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "embedded line breaks and tabs",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\"a\r\nb\",\"c\td\\\"\n",
+		out:     "a\\r\\nb\tc\\td\\\\\n",
+		outFull: "a\\r\\nb\tc\\td\\\\\n",
+		t:       CSVToTSV(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "empty fields",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a,,\"\",b\n,\n",
+		out:     "a\t\t\tb\n\t\n",
+		outFull: "a\t\t\tb\n\t\n",
+		t:       CSVToTSV(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "quote inside unquoted field",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a"b,c`,
+		out:     "a\"b\tc",
+		outFull: "a\"b\tc",
+		t:       CSVToTSV(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   3,
+	}, {
+		desc:    "quote at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      `x,"a"`,
+		out:     "x\ta",
+		outFull: "x\ta",
+		err:     transform.ErrShortSrc,
+		t:       CSVToTSV(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "to CSV",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\tb,c\tsay \"hi\"\n\t\tx\\ny\t\\\\\\q",
+		out:     ",\"b,c\",\"say \"\"hi\"\"\"\n,,\"x\ny\",\\\\q",
+		outFull: ",\"b,c\",\"say \"\"hi\"\"\"\n,,\"x\ny\",\\\\q",
+		t:       TSVToCSV(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "to CSV field at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "\tb,c",
+		out:     ",",
+		outFull: ",\"b,c\"",
+		err:     transform.ErrShortSrc,
+		t:       TSVToCSV(),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	roundTrip(t, TSVToCSV(), CSVToTSV(), []string{
+		"",
+		"a\tb\tc\n",
+		"a,b\t\"quoted\"\t\n",
+		"\t\t\n\t",
+		"line\\nbreak\ttab\\tq\\\\\r\n",
+		"héllo\twörld",
+	})
+}
+
+func TestCSVLongField(t *testing.T) {
+	// Long fields are quoted and streamed, even to a small destination.
+	long := strings.Repeat("x", 10000)
+	testCases := []struct{ in, out string }{
+		{long, `"` + long + `"`},
+		{long + "\tb\n", `"` + long + `",b` + "\n"},
+		{"a\t" + long + `"` + "\n" + long, `a,"` + long + `"""` + "\n\"" + long + `"`},
+	}
+	for _, tc := range testCases {
+		b, err := ioutil.ReadAll(transform.NewReader(strings.NewReader(tc.in), TSVToCSV()))
+		if got := string(b); got != tc.out || err != nil {
+			t.Errorf("%.20q: got %.20q, %v; want %.20q, <nil>", tc.in, got, err, tc.out)
+		}
+		if got := CSVToTSV().String(tc.out); got != tc.in {
+			t.Errorf("%.20q: round trip: got %.20q", tc.in, got)
+		}
+	}
+}
+
+func TestCSVSpan(t *testing.T) {
+	testCases := []struct {
+		t       Transformer
+		in, out string
+	}{
+		{CSVToTSV(), `a,"b c"`, "a\tb c"},
+		{CSVToTSV(), "\"a\r\nb\",\"c\td\\\"\n", "a\\r\\nb\tc\\td\\\\\n"},
+		{TSVToCSV(), "a\tb,c", `a,"b,c"`},
+	}
+	for _, tc := range testCases {
+		if got, err := spanTransform(tc.t, tc.in); got != tc.out || err != nil {
+			t.Errorf("%q: got %q, %v; want %q, <nil>", tc.in, got, err, tc.out)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "golang.org/x/text/language"
+
+// CurrencySymbolToCode returns a Transformer that replaces currency symbols
+// with their ISO 4217 code, such as € to EUR and ₹ to INR. The symbols $ and ¥
+// are mapped to USD and JPY. Symbols of the Currency Symbols block
+// (U+20A0–U+20CF) without an ISO 4217 code, like ₰ (German penny), and unknown
+// symbols are left unchanged.
+//
+// Codes are inserted in place of the symbol, so "€5" becomes "EUR5".
+func CurrencySymbolToCode() Transformer {
+	return FlatMap(lookup(currencyCodes))
+}
+
+// CurrencySymbolToCodeIn is like CurrencySymbolToCode, but uses the region of
+// lang, such as CA for en-CA, to disambiguate the dollar and yen signs. If lang
+// has no region, the region inferred by its Region method is used. The symbols
+// are mapped as by CurrencySymbolToCode if no region can be determined or the
+// region is not known to use the symbol.
+func CurrencySymbolToCodeIn(lang language.Tag) Transformer {
+	region := ""
+	if r, conf := lang.Region(); conf != language.No {
+		region = r.String()
+	}
+	return FlatMap(func(r rune) (string, bool) {
+		switch {
+		case r == '$' && dollarCodes[region] != "":
+			return dollarCodes[region], true
+		case r == '¥' && region == "CN":
+			return "CNY", true
+		}
+		code, ok := currencyCodes[r]
+		return code, ok
+	})
+}
+
+var currencyCodes = map[rune]string{
+	'$': "USD",
+	'¢': "USD",
+	'£': "GBP",
+	'¥': "JPY",
+
+	// Currency Symbols block.
+	'₠': "XEU",
+	'₡': "CRC",
+	'₣': "FRF",
+	'₤': "ITL",
+	'₦': "NGN",
+	'₧': "ESP",
+	'₩': "KRW",
+	'₪': "ILS",
+	'₫': "VND",
+	'€': "EUR",
+	'₭': "LAK",
+	'₮': "MNT",
+	'₯': "GRD",
+	'₱': "PHP",
+	'₲': "PYG",
+	'₳': "ARA",
+	'₴': "UAH",
+	'₵': "GHS",
+	'₸': "KZT",
+	'₹': "INR",
+	'₺': "TRY",
+	'₼': "AZN",
+	'₽': "RUB",
+	'₾': "GEL",
+	'₿': "BTC",
+	'⃀': "KGS", // SOM SIGN
+}
+
+// dollarCodes maps regions to the currency denoted by the dollar sign.
+var dollarCodes = map[string]string{
+	"AR": "ARS",
+	"AU": "AUD",
+	"BS": "BSD",
+	"BZ": "BZD",
+	"CA": "CAD",
+	"CL": "CLP",
+	"CO": "COP",
+	"FJ": "FJD",
+	"HK": "HKD",
+	"JM": "JMD",
+	"LR": "LRD",
+	"MX": "MXN",
+	"NA": "NAD",
+	"NZ": "NZD",
+	"SG": "SGD",
+	"TT": "TTD",
+	"TW": "TWD",
+	"US": "USD",
+}
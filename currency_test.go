@@ -0,0 +1,119 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/transform"
+)
+
+func TestCurrencySymbolToCode(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "major symbols",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x €1 £2 ¥3 ¢4 ₹5 ₩6 ₿7 $8",
+		out:     "x EUR1 GBP2 JPY3 USD4 INR5 KRW6 BTC7 USD8",
+		outFull: "x EUR1 GBP2 JPY3 USD4 INR5 KRW6 BTC7 USD8",
+		t:       CurrencySymbolToCode(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "unknown symbols",
+		szDst:   large,
+		atEOF:   true,
+		in:      "₰ ₥ ₨ ﷼ ¤",
+		out:     "₰ ₥ ₨ ﷼ ¤",
+		outFull: "₰ ₥ ₨ ﷼ ¤",
+		t:       CurrencySymbolToCode(),
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "x€",
+		out:     "x",
+		outFull: "xEUR",
+		err:     transform.ErrShortDst,
+		t:       CurrencySymbolToCode(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "dollar in Canada",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x $5 €5 ¥5",
+		out:     "x CAD5 EUR5 JPY5",
+		outFull: "x CAD5 EUR5 JPY5",
+		t:       CurrencySymbolToCodeIn(language.CanadianFrench),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "yen in China",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x ¥5 $5",
+		out:     "x CNY5 USD5",
+		outFull: "x CNY5 USD5",
+		t:       CurrencySymbolToCodeIn(language.MustParse("zh-Hans-CN")),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "unknown region",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x $5",
+		out:     "x USD5",
+		outFull: "x USD5",
+		t:       CurrencySymbolToCodeIn(language.French),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "inferred region",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x ¥5",
+		out:     "x CNY5",
+		outFull: "x CNY5",
+		t:       CurrencySymbolToCodeIn(language.Chinese),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "explicit region",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x $5",
+		out:     "x MXN5",
+		outFull: "x MXN5",
+		t:       CurrencySymbolToCodeIn(language.MustParse("en-MX")),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestCurrencySymbolsBlock(t *testing.T) {
+	want := map[rune]string{
+		0x20A0: "XEU", 0x20A1: "CRC", 0x20A3: "FRF", 0x20A4: "ITL",
+		0x20A6: "NGN", 0x20A7: "ESP", 0x20A9: "KRW", 0x20AA: "ILS",
+		0x20AB: "VND", 0x20AC: "EUR", 0x20AD: "LAK", 0x20AE: "MNT",
+		0x20AF: "GRD", 0x20B1: "PHP", 0x20B2: "PYG", 0x20B3: "ARA",
+		0x20B4: "UAH", 0x20B5: "GHS", 0x20B8: "KZT", 0x20B9: "INR",
+		0x20BA: "TRY", 0x20BC: "AZN", 0x20BD: "RUB", 0x20BE: "GEL",
+		0x20BF: "BTC", 0x20C0: "KGS",
+	}
+	for r := rune(0x20A0); r <= 0x20CF; r++ {
+		code, ok := want[r]
+		if !ok {
+			code = string(r)
+		}
+		if got := CurrencySymbolToCode().String(string(r)); got != code {
+			t.Errorf("%U: got %q; want %q", r, got, code)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// NewDebugRewriter returns a Rewriter that calls inner and writes a line to log
+// for each call to Rewrite, listing the runes read, the runes written and the
+// error set by inner, if any, as in
+//
+//	READ: [U+0041 U+0300] WROTE: [U+00C0] ERR: <nil>
+//
+// Bytes of invalid UTF-8 are listed as 0xHH. If an error is set, the reads and
+// writes listed are discarded. Errors writing to log are ignored.
+func NewDebugRewriter(inner Rewriter, log io.Writer) Rewriter {
+	return &debugRewriter{inner: inner, log: log}
+}
+
+type debugRewriter struct {
+	inner Rewriter
+	log   io.Writer
+}
+
+func (d *debugRewriter) Reset() { d.inner.Reset() }
+
+func (d *debugRewriter) Rewrite(s State) {
+	// s may have been used before within the same call to Rewrite.
+	nRead, nWritten := len(s.Consumed()), s.Written()
+	d.inner.Rewrite(s)
+
+	fmt.Fprintf(d.log, "READ: [%s] WROTE: [%s] ERR: %v\n",
+		formatRunes(s.Consumed()[nRead:]), formatRunes(s.Output()[nWritten:]), s.Err())
+}
+
+// formatRunes returns the code points of the runes in b separated by spaces.
+func formatRunes(b []byte) string {
+	var a []string
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size == 1 {
+			a = append(a, fmt.Sprintf("0x%02X", b[0]))
+		} else {
+			a = append(a, fmt.Sprintf("U+%04X", r))
+		}
+		b = b[size:]
+	}
+	return strings.Join(a, " ")
+}
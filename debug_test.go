@@ -0,0 +1,70 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"unicode"
+)
+
+func TestDebugRewriter(t *testing.T) {
+	inner := NewStatefulRewriterFunc(func(s State) {
+		switch r, _ := s.ReadRune(); r {
+		case 'x':
+			s.SetError(ErrTooLong)
+		case '.':
+			s.WriteString("\u2026")
+		case 'q':
+			// Remove.
+		default:
+			s.WriteRune(unicode.ToUpper(r))
+		}
+	}, func() {})
+
+	testCases := []struct {
+		in   string
+		out  string
+		err  error
+		want string
+	}{{
+		in:  "a\u00e9.q",
+		out: "A\u00c9\u2026",
+		want: "READ: [U+0061] WROTE: [U+0041] ERR: <nil>\n" +
+			"READ: [U+00E9] WROTE: [U+00C9] ERR: <nil>\n" +
+			"READ: [U+002E] WROTE: [U+2026] ERR: <nil>\n" +
+			"READ: [U+0071] WROTE: [] ERR: <nil>\n",
+	}, {
+		in:  "a\xffx",
+		out: "A\ufffd",
+		err: ErrTooLong,
+		want: "READ: [U+0061] WROTE: [U+0041] ERR: <nil>\n" +
+			"READ: [0xFF] WROTE: [U+FFFD] ERR: <nil>\n" +
+			"READ: [U+0078] WROTE: [] ERR: textutil: input too long\n",
+	}}
+	for _, tc := range testCases {
+		var log bytes.Buffer
+		tr := NewTransformer(NewDebugRewriter(inner, &log))
+		if got, err := tr.StringErr(tc.in); got != tc.out || !errors.Is(err, tc.err) {
+			t.Errorf("%q: got %q, %v; want %q, %v", tc.in, got, err, tc.out, tc.err)
+		}
+		if got := log.String(); got != tc.want {
+			t.Errorf("%q: got log\n%s; want\n%s", tc.in, got, tc.want)
+		}
+	}
+
+	// In a Span, the writes that match the input are logged.
+	var log bytes.Buffer
+	tr := NewTransformer(NewDebugRewriter(inner, &log))
+	if n, err := tr.Span([]byte("AB"), true); n != 2 || err != nil {
+		t.Errorf("span: got %d, %v; want 2, <nil>", n, err)
+	}
+	want := "READ: [U+0041] WROTE: [U+0041] ERR: <nil>\n" +
+		"READ: [U+0042] WROTE: [U+0042] ERR: <nil>\n"
+	if got := log.String(); got != want {
+		t.Errorf("span: got log\n%s; want\n%s", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// KanjiDigitsToASCII returns a Transformer that converts the CJK ideographic
+// digits 〇 一 二 三 四 五 六 七 八 九 to the ASCII digits 0 through 9. It does not
+// interpret ideographic numbers: 十 (ten), for instance, is left unchanged.
+func KanjiDigitsToASCII() Transformer {
+	return MapRune(mapTable(kanjiDigits))
+}
+
+var kanjiDigits = map[rune]rune{
+	'〇': '0',
+	'一': '1',
+	'二': '2',
+	'三': '3',
+	'四': '4',
+	'五': '5',
+	'六': '6',
+	'七': '7',
+	'八': '8',
+	'九': '9',
+}
+
+// DigitBlockToASCII returns a Rewriter that converts the ten consecutive
+// digits starting at zero, such as the Devanagari digits starting at U+0966,
+// to the ASCII digits 0 through 9.
+func DigitBlockToASCII(zero rune) Rewriter {
+	return rewriterFunc(func(s State) {
+		if r, _ := s.ReadRune(); zero <= r && r <= zero+9 {
+			s.WriteRune('0' + r - zero)
+		} else {
+			s.WriteRune(r)
+		}
+	})
+}
+
+// FullWidthDigitsToASCII returns a Transformer that converts the fullwidth
+// digits U+FF10 through U+FF19 to the ASCII digits 0 through 9.
+func FullWidthDigitsToASCII() Transformer {
+	return NewTransformer(DigitBlockToASCII('０'))
+}
+
+// ASCIIDigitsToFullWidth returns a Transformer that converts the ASCII digits 0
+// through 9 to the fullwidth digits U+FF10 through U+FF19.
+func ASCIIDigitsToFullWidth() Transformer {
+	return MapRune(func(r rune) rune {
+		if '0' <= r && r <= '9' {
+			return r - '0' + '０'
+		}
+		return r
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestKanjiDigitsToASCII(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "digits",
+		szDst:   large,
+		atEOF:   true,
+		in:      "〇一二三四五六七八九",
+		out:     "0123456789",
+		outFull: "0123456789",
+		t:       KanjiDigitsToASCII(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "mixed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "平成二九年",
+		out:     "平成29年",
+		outFull: "平成29年",
+		t:       KanjiDigitsToASCII(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("平成"),
+	}, {
+		desc:    "no digits",
+		szDst:   large,
+		atEOF:   true,
+		in:      "十百千万 日本語 123",
+		out:     "十百千万 日本語 123",
+		outFull: "十百千万 日本語 123",
+		t:       KanjiDigitsToASCII(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestDigitBlockToASCII(t *testing.T) {
+	testCases := []struct {
+		zero    rune
+		in, out string
+	}{
+		{0x0966, "१२३ ४५६ ७८९ ०", "123 456 789 0"}, // Devanagari
+		{0x0660, "٠١٢٣٤٥٦٧٨٩", "0123456789"},       // Arabic-Indic
+		{0x0f20, "༠༡༢༣༤༥༦༧༨༩ ༪", "0123456789 ༪"},   // Tibetan
+		{0x0966, "٠١٢", "٠١٢"},
+	}
+	for i, tc := range testCases {
+		tr := NewTransformer(DigitBlockToASCII(tc.zero))
+		if got := tr.String(tc.in); got != tc.out {
+			t.Errorf("%d:%U: got %q; want %q", i, tc.zero, got, tc.out)
+		}
+	}
+}
+
+func TestFullWidthDigits(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "to ASCII",
+		szDst:   large,
+		atEOF:   true,
+		in:      "電話：０１２３４５６７８９",
+		out:     "電話：0123456789",
+		outFull: "電話：0123456789",
+		t:       FullWidthDigitsToASCII(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("電話："),
+	}, {
+		desc:    "to fullwidth",
+		szDst:   large,
+		atEOF:   true,
+		in:      "No. 42!",
+		out:     "No. ４２!",
+		outFull: "No. ４２!",
+		t:       ASCIIDigitsToFullWidth(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no digits",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ＡＢＣ abc ١٢",
+		out:     "ＡＢＣ abc ١٢",
+		outFull: "ＡＢＣ abc ١٢",
+		t:       FullWidthDigitsToASCII(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	var inputs []string
+	for r := '0'; r <= '9'; r++ {
+		inputs = append(inputs, string(r), "a"+string(r)+"b")
+		if got, want := ASCIIDigitsToFullWidth().String(string(r)), string(r+0xfee0); got != want {
+			t.Errorf("%U: got %q; want %q", r, got, want)
+		}
+	}
+	roundTrip(t, ASCIIDigitsToFullWidth(), FullWidthDigitsToASCII(), inputs)
+}
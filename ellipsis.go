@@ -0,0 +1,72 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// NormalizeEllipsis returns a Transformer that replaces each run of exactly
+// three full stops with a horizontal ellipsis (U+2026). Shorter and longer runs
+// of full stops are written verbatim.
+func NormalizeEllipsis() Transformer {
+	return NewTransformer(&ellipsis{})
+}
+
+// DenormalizeEllipsis returns a Transformer that replaces each horizontal
+// ellipsis (U+2026) with three full stops.
+func DenormalizeEllipsis() Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		if r, _ := s.ReadRune(); r == '…' {
+			s.WriteString("...")
+		} else {
+			s.WriteRune(r)
+		}
+	})
+}
+
+// ellipsis is a Rewriter that replaces a run of exactly three dots with an
+// ellipsis.
+type ellipsis struct {
+	// inRun is set if the last written full stop was part of a run of four
+	// or more full stops. This avoids having to buffer arbitrarily long runs.
+	inRun bool
+}
+
+func (e *ellipsis) Reset() { *e = ellipsis{} }
+
+func (e *ellipsis) Clone() Rewriter {
+	c := *e
+	return &c
+}
+
+func (e *ellipsis) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	switch {
+	case r != '.':
+		if s.WriteRune(r) {
+			e.inRun = false
+		}
+		return
+	case e.inRun:
+		s.WriteRune('.')
+		return
+	}
+
+	// Read up to three more full stops to determine the length of the run. If
+	// the input ends prematurely, ReadRune will have set ErrShortSrc and we
+	// will be called again with more input.
+	n := 1
+	for ; n < 4; n++ {
+		if r, _ := s.ReadRune(); r != '.' {
+			s.UnreadRune()
+			break
+		}
+	}
+	switch {
+	case n == 3:
+		s.WriteRune('…')
+	case n < 3:
+		s.WriteString("..."[:n])
+	case s.WriteString("...."):
+		e.inRun = true
+	}
+}
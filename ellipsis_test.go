@@ -0,0 +1,108 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestEllipsis(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "three dots",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Wait... what",
+		out:     "Wait… what",
+		outFull: "Wait… what",
+		t:       NormalizeEllipsis(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "three dots at end",
+		szDst:   large,
+		atEOF:   true,
+		in:      "So...",
+		out:     "So…",
+		outFull: "So…",
+		t:       NormalizeEllipsis(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "two dots",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a..b..",
+		out:     "a..b..",
+		outFull: "a..b..",
+		t:       NormalizeEllipsis(),
+	}, {
+		desc:    "four dots",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a....b",
+		out:     "a....b",
+		outFull: "a....b",
+		t:       NormalizeEllipsis(),
+	}, {
+		desc:    "seven dots",
+		szDst:   large,
+		atEOF:   true,
+		in:      ".......",
+		out:     ".......",
+		outFull: ".......",
+		t:       NormalizeEllipsis(),
+	}, {
+		desc:    "dots split across buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a..",
+		out:     "a",
+		outFull: "a..",
+		err:     transform.ErrShortSrc,
+		t:       NormalizeEllipsis(),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "a...",
+		out:     "a",
+		outFull: "a…",
+		err:     transform.ErrShortDst,
+		t:       NormalizeEllipsis(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "denormalize",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a…b……",
+		out:     "a...b......",
+		outFull: "a...b......",
+		t:       DenormalizeEllipsis(),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestEllipsisBoundary(t *testing.T) {
+	// transform.String uses an initial buffer size of 128 bytes.
+	for n := 120; n < 130; n++ {
+		prefix := strings.Repeat("x", n)
+		for _, in := range []string{"...", "....", "..", "......"} {
+			want := prefix + in
+			if in == "..." {
+				want = prefix + "…"
+			}
+			if got := NormalizeEllipsis().String(prefix + in); got != want {
+				t.Errorf("%d:%q: got %q; want %q", n, in, got, want)
+			}
+		}
+	}
+}
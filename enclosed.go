@@ -0,0 +1,29 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "strconv"
+
+// ExpandEnclosedAlphanumerics returns a Transformer that replaces circled
+// numbers ①–⑳, parenthesized letters ⒜–⒵ and circled letters Ⓐ–Ⓩ and ⓐ–ⓩ with
+// the number or letter they enclose. For instance, ⑫ becomes 12 and ⒝ becomes
+// b.
+func ExpandEnclosedAlphanumerics() Transformer {
+	return FlatMap(expandEnclosed)
+}
+
+func expandEnclosed(r rune) (string, bool) {
+	switch {
+	case '①' <= r && r <= '⑳':
+		return strconv.Itoa(int(r-'①') + 1), true
+	case '⒜' <= r && r <= '⒵':
+		return string('a' + r - '⒜'), true
+	case 'Ⓐ' <= r && r <= 'Ⓩ':
+		return string('A' + r - 'Ⓐ'), true
+	case 'ⓐ' <= r && r <= 'ⓩ':
+		return string('a' + r - 'ⓐ'), true
+	}
+	return "", false
+}
@@ -0,0 +1,54 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestExpandEnclosedAlphanumerics(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "numbers",
+		szDst:   large,
+		atEOF:   true,
+		in:      "step ① to ⑨, ⑩ and ⑳",
+		out:     "step 1 to 9, 10 and 20",
+		outFull: "step 1 to 9, 10 and 20",
+		t:       ExpandEnclosedAlphanumerics(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ⒶⓏ ⓐⓩ ⒜⒵",
+		out:     "AZ az az",
+		outFull: "AZ az az",
+		t:       ExpandEnclosedAlphanumerics(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "⑪⑫",
+		out:     "11",
+		outFull: "1112",
+		err:     transform.ErrShortDst,
+		t:       ExpandEnclosedAlphanumerics(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "not enclosed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "A1 ⑴ ⒈ ⓪ ⓫",
+		out:     "A1 ⑴ ⒈ ⓪ ⓫",
+		outFull: "A1 ⑴ ⒈ ⓪ ⓫",
+		t:       ExpandEnclosedAlphanumerics(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "fmt"
+
+// A TextError is returned by the Transform method of Transformers created from
+// a Rewriter for an error set by the Rewriter. Use errors.Is or errors.As to
+// check for specific errors.
+type TextError struct {
+	// Err is the error set by the Rewriter.
+	Err error
+
+	// Offset is the offset in bytes, relative to the input consumed since the
+	// last Reset, of the segment of input for which the error was set.
+	Offset int
+
+	// Context holds up to contextSize bytes of input on either side of the
+	// segment, to the extent they are available in the source buffer.
+	Context []byte
+}
+
+// contextSize is the maximum number of bytes of context on either side of the
+// position of an error.
+const contextSize = 16
+
+// newTextError returns a TextError for err, where p is the position in src of
+// the segment that caused it.
+func newTextError(err error, offset int, src []byte, p int) *TextError {
+	start, end := p-contextSize, p+contextSize
+	if start < 0 {
+		start = 0
+	}
+	if end > len(src) {
+		end = len(src)
+	}
+	return &TextError{
+		Err:     err,
+		Offset:  offset,
+		Context: append([]byte(nil), src[start:end]...),
+	}
+}
+
+func (e *TextError) Error() string {
+	return fmt.Sprintf("%v at offset %d near %q", e.Err, e.Offset, e.Context)
+}
+
+// Unwrap returns e.Err.
+func (e *TextError) Unwrap() error { return e.Err }
@@ -0,0 +1,52 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/text/transform"
+)
+
+func TestTextError(t *testing.T) {
+	tr := NewTransformer(NewTruncator(300, TruncateBytes))
+	in := strings.Repeat("a", 290) + "0123456789XYZ"
+
+	// The offset is relative to the start of the input, even if it is passed
+	// in pieces.
+	_, err := ioutil.ReadAll(tr.NewReader(iotest.OneByteReader(strings.NewReader(in))))
+	var e *TextError
+	if !errors.As(err, &e) {
+		t.Fatalf("got %#v; want *TextError", err)
+	}
+	if !errors.Is(err, ErrTruncated) || e.Err != ErrTruncated {
+		t.Errorf("got %v; want it to wrap %v", err, ErrTruncated)
+	}
+	if e.Offset != 300 {
+		t.Errorf("Offset: got %d; want 300", e.Offset)
+	}
+
+	dst := make([]byte, large)
+	tr.Reset()
+	if _, _, err := tr.Transform(dst, []byte(in), true); !errors.As(err, &e) {
+		t.Fatalf("got %#v; want *TextError", err)
+	}
+	if got, want := string(e.Context), in[284:]; e.Offset != 300 || got != want {
+		t.Errorf("got offset %d, context %q; want 300, %q", e.Offset, got, want)
+	}
+	if got, want := e.Error(), `textutil: input truncated at offset 300 near "aaaaaa0123456789XYZ"`; got != want {
+		t.Errorf("Error: got %q; want %q", got, want)
+	}
+
+	// Errors used by the transform package are not wrapped.
+	tr.Reset()
+	if _, _, err := tr.Transform(dst[:0], []byte("a"), true); err != transform.ErrShortDst {
+		t.Errorf("got %v; want %v", err, transform.ErrShortDst)
+	}
+}
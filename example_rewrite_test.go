@@ -6,6 +6,7 @@ package textutil_test
 
 import (
 	"fmt"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
@@ -36,6 +37,54 @@ func ExampleRewriter() {
 	// Héllø wørl∂!
 }
 
+func ExampleNewStatefulRewriterFunc() {
+	// A Rewriter equivalent to cleanSpaces, with its state held by closures.
+	var notFirst, foundSpace bool
+	rewrite := func(s textutil.State) {
+		switch r, _ := s.ReadRune(); {
+		case unicode.IsSpace(r):
+			foundSpace = true
+		case foundSpace && notFirst && !s.WriteRune(' '):
+			// Don't change the state if writing the space fails.
+		default:
+			foundSpace, notFirst = false, true
+			s.WriteRune(r)
+		}
+	}
+	reset := func() { notFirst, foundSpace = false, false }
+
+	clean := textutil.NewTransformer(textutil.NewStatefulRewriterFunc(rewrite, reset))
+	fmt.Println(clean.String("  Hello   world! \t Hello   world!   "))
+
+	// Output:
+	// Hello world! Hello world!
+}
+
+func ExampleNewConditionalRewriter() {
+	upper := textutil.NewStatefulRewriterFunc(func(s textutil.State) {
+		r, _ := s.ReadRune()
+		s.WriteRune(unicode.ToUpper(r))
+	}, func() {})
+	isVowel := func(r rune) bool { return strings.ContainsRune("aeiou", r) }
+
+	t := textutil.NewTransformer(textutil.NewConditionalRewriter(isVowel, upper))
+	fmt.Println(t.String("the quick brown fox"))
+
+	// Output:
+	// thE qUIck brOwn fOx
+}
+
+func ExampleNewROT13Rewriter() {
+	rot13 := textutil.NewTransformer(textutil.NewROT13Rewriter())
+	s := rot13.String("Hello, world!")
+	fmt.Println(s)
+	fmt.Println(rot13.String(s))
+
+	// Output:
+	// Uryyb, jbeyq!
+	// Hello, world!
+}
+
 // The cleanSpaces Rewriter collapses consecutive whitespace characters into a
 // single space and trims them completely at the beginning and end of the input.
 // It handles only one rune at a time.
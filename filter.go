@@ -0,0 +1,232 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// RemoveFunc returns a Transformer that removes from the input all runes r for
+// which remove(r) is true. Invalid UTF-8 is passed to remove as
+// utf8.RuneError.
+func RemoveFunc(remove func(r rune) bool) Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		if r, _ := s.ReadRune(); !remove(r) {
+			s.WriteRune(r)
+		}
+	})
+}
+
+// NewFilterRewriter returns a Rewriter that copies the runes r of the input for
+// which keep(r) is true and removes all others. Invalid UTF-8 is passed to
+// keep as utf8.RuneError and written as such if kept.
+func NewFilterRewriter(keep func(r rune) bool) Rewriter {
+	return rewriterFunc(func(s State) {
+		if r, _ := s.PeekRune(); keep(r) {
+			s.ReadRune()
+			s.WriteRune(r)
+		} else {
+			s.Skip()
+		}
+	})
+}
+
+// NewReplaceFilterRewriter returns a Rewriter that copies the runes r of the
+// input for which keep(r) is true and replaces all others with replacement.
+// Invalid UTF-8 is passed to keep as utf8.RuneError and written as such if
+// kept.
+func NewReplaceFilterRewriter(keep func(r rune) bool, replacement rune) Rewriter {
+	return rewriterFunc(func(s State) {
+		if r, _ := s.ReadRune(); keep(r) {
+			s.WriteRune(r)
+		} else {
+			s.WriteRune(replacement)
+		}
+	})
+}
+
+// CollapseRuns returns a Transformer that replaces each run of one or more
+// runes r for which f(r) is true with a single repl.
+func CollapseRuns(f func(r rune) bool, repl rune) Transformer {
+	return NewTransformer(&collapseRuns{f: f, repl: repl})
+}
+
+type collapseRuns struct {
+	f     func(rune) bool
+	repl  rune
+	inRun bool
+}
+
+func (c *collapseRuns) Reset() { c.inRun = false }
+
+func (c *collapseRuns) Clone() Rewriter {
+	x := *c
+	return &x
+}
+
+func (c *collapseRuns) Rewrite(s State) {
+	switch r, _ := s.ReadRune(); {
+	case !c.f(r):
+		if s.WriteRune(r) {
+			c.inRun = false
+		}
+	case c.inRun:
+		// Skip the rune.
+	case s.WriteRune(c.repl):
+		c.inRun = true
+	}
+}
+
+// NewCollapseSpacesRewriter returns a Rewriter that replaces each run of one or
+// more white space runes, as defined by unicode.IsSpace, with a single space
+// (U+0020). If trimEdges is true, white space at the beginning and end of the
+// input is removed altogether.
+func NewCollapseSpacesRewriter(trimEdges bool) Rewriter {
+	return &collapseSpaces{trim: trimEdges}
+}
+
+type collapseSpaces struct {
+	trim bool
+
+	// notFirst is set once a rune other than white space has been written.
+	notFirst bool
+
+	// foundSpace is set if the last rune read was white space. If trim is
+	// true, the space that replaces the run is only written once it is
+	// followed by a rune other than white space. Otherwise it was already
+	// written at the start of the run.
+	foundSpace bool
+}
+
+func (c *collapseSpaces) Reset() { c.notFirst, c.foundSpace = false, false }
+
+func (c *collapseSpaces) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	switch {
+	case !unicode.IsSpace(r):
+		if c.trim && c.foundSpace && !s.WriteRune(' ') || !s.WriteRune(r) {
+			return
+		}
+		c.notFirst, c.foundSpace = true, false
+	case c.foundSpace:
+		// Skip the rune.
+	case !c.trim:
+		c.foundSpace = s.WriteRune(' ')
+	case !c.notFirst:
+		// Remove leading white space.
+	default:
+		// Write a single space right away if it is followed by a rune other
+		// than white space. This keeps most text within a span.
+		p, size := s.PeekRune()
+		if size == 0 && !s.IsAtEOF() {
+			return // PeekRune set ErrShortSrc.
+		}
+		if r == ' ' && size > 0 && !unicode.IsSpace(p) {
+			s.WriteRune(' ')
+			return
+		}
+		c.foundSpace = true
+	}
+}
+
+// A RunCategory selects the runes for which NewRunDeduplicator collapses runs.
+type RunCategory int
+
+const (
+	// AllRunes selects all runes.
+	AllRunes RunCategory = iota
+
+	// SpaceRunes selects white space, as defined by unicode.IsSpace.
+	SpaceRunes
+
+	// LetterRunes selects letters, as defined by unicode.IsLetter.
+	LetterRunes
+)
+
+func (c RunCategory) contains(r rune) bool {
+	switch c {
+	case SpaceRunes:
+		return unicode.IsSpace(r)
+	case LetterRunes:
+		return unicode.IsLetter(r)
+	}
+	return true
+}
+
+// NewRunDeduplicator returns a Rewriter that replaces each run of two or more
+// identical runes of the given category with a single instance of that rune.
+// For instance, with AllRunes, "!!!" becomes "!". Unlike CollapseRuns, a run
+// consists of a single repeated rune: a space followed by a tab is left
+// unchanged.
+func NewRunDeduplicator(category RunCategory) Rewriter {
+	return &runDeduplicator{category: category}
+}
+
+type runDeduplicator struct {
+	category RunCategory
+
+	// lastRune is the last rune written, if hasLast is true.
+	lastRune rune
+	hasLast  bool
+}
+
+func (d *runDeduplicator) Reset() { d.lastRune, d.hasLast = 0, false }
+
+func (d *runDeduplicator) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if d.hasLast && r == d.lastRune && d.category.contains(r) {
+		// Skip the rune.
+		return
+	}
+	if s.WriteRune(r) {
+		d.lastRune, d.hasLast = r, true
+	}
+}
+
+// Trim returns a Transformer that removes all leading and trailing runes r for
+// which f(r) is true. Runs of such runes are buffered until a rune is
+// encountered for which f is false, so f should be false for the majority of
+// the input.
+func Trim(f func(r rune) bool) Transformer {
+	return NewTransformer(&trim{f: f})
+}
+
+type trim struct {
+	f       func(rune) bool
+	started bool
+	buf     []byte
+}
+
+func (t *trim) Reset() { t.started = false }
+
+func (t *trim) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	switch {
+	case !t.f(r):
+		if s.WriteRune(r) {
+			t.started = true
+		}
+		return
+	case !t.started:
+		// Remove leading runes.
+		return
+	}
+	// Collect the run and only write it if it is followed by a rune for which
+	// f is false. The end of a non-final buffer results in ErrShortSrc.
+	t.buf = utf8.AppendRune(t.buf[:0], r)
+	for {
+		r, size := s.ReadRune()
+		if size == 0 {
+			return
+		}
+		if !t.f(r) {
+			s.UnreadRune()
+			break
+		}
+		t.buf = utf8.AppendRune(t.buf, r)
+	}
+	s.WriteBytes(t.buf)
+}
@@ -0,0 +1,396 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+func TestRemoveFunc(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "remove spaces",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a b\tc",
+		out:     "abc",
+		outFull: "abc",
+		t:       RemoveFunc(unicode.IsSpace),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "remove at end",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc ",
+		out:     "abc",
+		outFull: "abc",
+		t:       RemoveFunc(unicode.IsSpace),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "remove all",
+		szDst:   large,
+		atEOF:   true,
+		in:      "  \t ",
+		out:     "",
+		outFull: "",
+		t:       RemoveFunc(unicode.IsSpace),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "remove none",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "abc",
+		outFull: "abc",
+		t:       RemoveFunc(unicode.IsSpace),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestFilterRewriter(t *testing.T) {
+	isASCII := func(r rune) bool { return r < utf8.RuneSelf }
+	all := func(r rune) bool { return true }
+	testCases := []transformTest{{
+		desc:    "filter",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, w\u00f8rld!",
+		out:     "Hello, wrld!",
+		outFull: "Hello, wrld!",
+		t:       NewTransformer(NewFilterRewriter(isASCII)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xffb",
+		out:     "ab",
+		outFull: "ab",
+		t:       NewTransformer(NewFilterRewriter(isASCII)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "remove all",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u00e9\u00f8\u2208",
+		out:     "",
+		outFull: "",
+		t:       NewTransformer(NewFilterRewriter(isASCII)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "keep all",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, w\u00f8rld!",
+		out:     "Hello, w\u00f8rld!",
+		outFull: "Hello, w\u00f8rld!",
+		t:       NewTransformer(NewFilterRewriter(all)),
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "a\u00e9bc",
+		out:     "ab",
+		outFull: "abc",
+		err:     transform.ErrShortDst,
+		t:       NewTransformer(NewFilterRewriter(isASCII)),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "replace",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, w\u00f8rld!",
+		out:     "Hello, w?rld!",
+		outFull: "Hello, w?rld!",
+		t:       NewTransformer(NewReplaceFilterRewriter(isASCII, '?')),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "replace with multi-byte rune",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xffb",
+		out:     "a\ufffdb",
+		outFull: "a\ufffdb",
+		t:       NewTransformer(NewReplaceFilterRewriter(isASCII, utf8.RuneError)),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestCollapseRuns(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "collapse",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a  b\t\n c ",
+		out:     "a b c ",
+		outFull: "a b c ",
+		t:       CollapseRuns(unicode.IsSpace, ' '),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "replace",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a, b!",
+		out:     "a-b-",
+		outFull: "a-b-",
+		t:       CollapseRuns(isNotAlnum, '-'),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "single runes",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a b c",
+		out:     "a b c",
+		outFull: "a b c",
+		t:       CollapseRuns(unicode.IsSpace, ' '),
+	}, {
+		desc:    "run across buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a  ",
+		out:     "a ",
+		outFull: "a ",
+		t:       CollapseRuns(unicode.IsSpace, ' '),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestCollapseSpacesRewriter(t *testing.T) {
+	trim := func() Transformer { return NewTransformer(NewCollapseSpacesRewriter(true)) }
+	keep := func() Transformer { return NewTransformer(NewCollapseSpacesRewriter(false)) }
+	testCases := []transformTest{{
+		desc:    "trim",
+		szDst:   large,
+		atEOF:   true,
+		in:      "  Hello   world! \t Hello\u3000world!   ",
+		out:     "Hello world! Hello world!",
+		outFull: "Hello world! Hello world!",
+		t:       trim(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "keep edges",
+		szDst:   large,
+		atEOF:   true,
+		in:      "  Hello   world! \t Hello\u3000world!   ",
+		out:     " Hello world! Hello world! ",
+		outFull: " Hello world! Hello world! ",
+		t:       keep(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "single spaces",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a b c",
+		out:     "a b c",
+		outFull: "a b c",
+		t:       trim(),
+	}, {
+		desc:    "only white space",
+		szDst:   large,
+		atEOF:   true,
+		in:      " \t\n ",
+		out:     "",
+		outFull: "",
+		t:       trim(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "space at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a b ",
+		out:     "a b",
+		outFull: "a b",
+		err:     transform.ErrShortSrc,
+		t:       trim(),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   3,
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "a  bc",
+		out:     "a",
+		outFull: "a bc",
+		err:     transform.ErrShortDst,
+		t:       trim(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	// Runs of white space split across calls to Transform.
+	in := " a  b\t\t\tc \u3000 d "
+	for _, sz := range [][2]int{{1, 2}, {2, 2}, {3, 2}, {5, 8}} {
+		for _, tc := range []struct {
+			t    Transformer
+			want string
+		}{
+			{trim(), "a b c d"},
+			{keep(), " a b c d "},
+		} {
+			got, err := transformChunks(tc.t, in, sz[0], sz[1])
+			if got != tc.want || err != nil {
+				t.Errorf("%v: got %q, %v; want %q, <nil>", sz, got, err, tc.want)
+			}
+		}
+	}
+}
+
+func TestRunDeduplicator(t *testing.T) {
+	dedup := func(c RunCategory) Transformer { return NewTransformer(NewRunDeduplicator(c)) }
+	testCases := []transformTest{{
+		desc:    "all",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello!!!  Wow\u2026\u2026 ok",
+		out:     "Helo! Wow\u2026 ok",
+		outFull: "Helo! Wow\u2026 ok",
+		t:       dedup(AllRunes),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   3,
+	}, {
+		desc:    "spaces",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello!!!  Wow \t\t ok",
+		out:     "Hello!!! Wow \t ok",
+		outFull: "Hello!!! Wow \t ok",
+		t:       dedup(SpaceRunes),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   9,
+	}, {
+		desc:    "letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello!!!  W\u00f6\u00f6w",
+		out:     "Helo!!!  W\u00f6w",
+		outFull: "Helo!!!  W\u00f6w",
+		t:       dedup(LetterRunes),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   3,
+	}, {
+		desc:    "no runs",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a b\t c",
+		out:     "a b\t c",
+		outFull: "a b\t c",
+		t:       dedup(AllRunes),
+	}, {
+		desc:    "NUL",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x00\x00",
+		out:     "a\x00",
+		outFull: "a\x00",
+		t:       dedup(AllRunes),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "abb\u00e9\u00e9",
+		out:     "ab",
+		outFull: "ab\u00e9",
+		err:     transform.ErrShortDst,
+		t:       dedup(AllRunes),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	// Runs split across calls to Transform.
+	tr := dedup(AllRunes)
+	in := "aa!!!!b\u00e9\u00e9\u00e9  c"
+	for _, sz := range [][2]int{{1, 4}, {2, 4}, {3, 8}} {
+		if got, err := transformChunks(tr, in, sz[0], sz[1]); got != "a!b\u00e9 c" || err != nil {
+			t.Errorf("%v: got %q, %v; want %q, <nil>", sz, got, err, "a!b\u00e9 c")
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "trim",
+		szDst:   large,
+		atEOF:   true,
+		in:      "  a  b  ",
+		out:     "a  b",
+		outFull: "a  b",
+		t:       Trim(unicode.IsSpace),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   0,
+	}, {
+		desc:    "trailing",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a  b \t",
+		out:     "a  b",
+		outFull: "a  b",
+		t:       Trim(unicode.IsSpace),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "nothing to trim",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a  b",
+		out:     "a  b",
+		outFull: "a  b",
+		t:       Trim(unicode.IsSpace),
+	}, {
+		desc:    "only runes to trim",
+		szDst:   large,
+		atEOF:   true,
+		in:      "   ",
+		out:     "",
+		outFull: "",
+		t:       Trim(unicode.IsSpace),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "trailing run at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a  ",
+		out:     "a",
+		outFull: "a",
+		err:     transform.ErrShortSrc,
+		t:       Trim(unicode.IsSpace),
+		errSpan: transform.ErrShortSrc,
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "a   b",
+		out:     "a",
+		outFull: "a   b",
+		err:     transform.ErrShortDst,
+		t:       Trim(unicode.IsSpace),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
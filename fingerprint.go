@@ -0,0 +1,146 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"bytes"
+	"errors"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// ErrFingerprintDetected is returned by the Transformer returned by
+// StripInvisibleFingerprint if its input contains an invisible fingerprint.
+var ErrFingerprintDetected = errors.New("textutil: invisible fingerprint detected")
+
+// minFingerprintLen is the minimum length of a run of invisible characters that
+// is considered a fingerprint.
+const minFingerprintLen = 8
+
+// StripInvisibleFingerprint returns a Transformer that removes the zero-width
+// characters U+200B, U+200C, U+200D, and U+2060. Such characters can be used to
+// invisibly embed a unique fingerprint in text, typically by encoding bits as a
+// run of two or more distinct zero-width characters.
+//
+// If the input contains a run of at least eight such characters using at least
+// two distinct characters, Transform returns ErrFingerprintDetected once all
+// input has been processed. The output is complete in that case. Use
+// NewFingerprintStripper to obtain the number of removed characters.
+func StripInvisibleFingerprint() Transformer {
+	_, t := NewFingerprintStripper()
+	return t
+}
+
+// FingerprintStats holds the number of invisible characters removed by the
+// Transformer returned by NewFingerprintStripper.
+type FingerprintStats struct {
+	// Removed is the number of invisible characters removed.
+	Removed int
+}
+
+// NewFingerprintStripper returns a FingerprintStats and a Transformer that
+// behaves as the one returned by StripInvisibleFingerprint and counts the
+// characters it removes. Reset clears the count. Use Synchronized to read the
+// count while the Transformer is in use.
+func NewFingerprintStripper() (*FingerprintStats, Transformer) {
+	f := &fingerprint{stats: &FingerprintStats{}}
+	return f.stats, Transformer{f}
+}
+
+type fingerprint struct {
+	stats    *FingerprintStats
+	detected bool
+
+	// run and seen hold the length and the set of distinct characters of the
+	// current run of invisible characters.
+	run  int
+	seen uint8
+}
+
+func (f *fingerprint) Reset() {
+	*f.stats = FingerprintStats{}
+	*f = fingerprint{stats: f.stats}
+}
+
+// invisibleBit returns the bit in fingerprint.seen for r, or 0 if r is not
+// an invisible character.
+func invisibleBit(r rune) uint8 {
+	switch r {
+	case '\u200b':
+		return 1
+	case '\u200c':
+		return 2
+	case '\u200d':
+		return 4
+	case '\u2060':
+		return 8
+	}
+	return 0
+}
+
+func (f *fingerprint) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		// All invisible characters start with 0xE2.
+		end := len(src)
+		if i := bytes.IndexByte(src[nSrc:], 0xE2); i >= 0 {
+			end = nSrc + i
+		}
+		if end > nSrc {
+			n := copy(dst[nDst:], src[nSrc:end])
+			nDst += n
+			nSrc += n
+			f.run, f.seen = 0, 0
+			if nSrc < end {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			continue
+		}
+		if !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+		bit := invisibleBit(r)
+		if bit == 0 {
+			if len(dst)-nDst < size {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+			nSrc += size
+			f.run, f.seen = 0, 0
+			continue
+		}
+		nSrc += size
+		f.stats.Removed++
+		f.run++
+		f.seen |= bit
+		if f.run >= minFingerprintLen && f.seen&(f.seen-1) != 0 { // at least two bits set
+			f.detected = true
+		}
+	}
+	if atEOF && f.detected {
+		return nDst, nSrc, ErrFingerprintDetected
+	}
+	return nDst, nSrc, nil
+}
+
+func (f *fingerprint) Span(src []byte, atEOF bool) (n int, err error) {
+	for n < len(src) {
+		i := bytes.IndexByte(src[n:], 0xE2)
+		if i < 0 {
+			return len(src), nil
+		}
+		n += i
+		if !atEOF && !utf8.FullRune(src[n:]) {
+			return n, transform.ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(src[n:])
+		if invisibleBit(r) != 0 {
+			return n, transform.ErrEndOfSpan
+		}
+		n += size
+	}
+	return n, nil
+}
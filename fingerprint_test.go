@@ -0,0 +1,117 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestStripInvisibleFingerprint(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "fingerprint",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello\u200b\u200c\u200c\u200b\u200b\u200b\u200c\u200b, world",
+		out:     "Hello, world",
+		outFull: "Hello, world",
+		err:     ErrFingerprintDetected,
+		t:       StripInvisibleFingerprint(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "fingerprint with word joiners",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u2060\u200d\u2060\u2060\u200d\u200d\u2060\u200d\u2060b",
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrFingerprintDetected,
+		t:       StripInvisibleFingerprint(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "fingerprint reported at end of input",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a\u200b\u200c\u200c\u200b\u200b\u200b\u200c\u200bb",
+		out:     "ab",
+		outFull: "ab",
+		t:       StripInvisibleFingerprint(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "scattered invisible characters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u200bb\u200cc\u200dd\u2060e\u200b\u200b\u200b\u200b\u200b\u200b\u200b\u200b",
+		out:     "abcde",
+		outFull: "abcde",
+		t:       StripInvisibleFingerprint(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "plain text",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Thé qüick – brøwn … føx",
+		out:     "Thé qüick – brøwn … føx",
+		outFull: "Thé qüick – brøwn … føx",
+		t:       StripInvisibleFingerprint(),
+	}, {
+		desc:    "incomplete rune",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a\u200bb\xe2\x80",
+		out:     "ab",
+		outFull: "ab\xe2\x80",
+		err:     transform.ErrShortSrc,
+		t:       StripInvisibleFingerprint(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   4,
+		atEOF:   true,
+		in:      "ab\u200bc–",
+		out:     "abc",
+		outFull: "abc–",
+		err:     transform.ErrShortDst,
+		t:       StripInvisibleFingerprint(),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.t.Reset()
+		tt.check(t, i)
+	}
+}
+
+func TestFingerprintStripper(t *testing.T) {
+	var mu sync.Mutex
+	stats, tr := NewFingerprintStripper()
+	tr = Synchronized(tr, &mu)
+	s, _, err := transform.String(tr, "a\u200bb\u200c\u200dc")
+	if s != "abc" || err != nil {
+		t.Errorf("got %q, %v; want %q, <nil>", s, err, "abc")
+	}
+	if n := stats.Removed; n != 3 {
+		t.Errorf("got %d; want 3", n)
+	}
+	tr.Reset()
+	if n := stats.Removed; n != 0 {
+		t.Errorf("after Reset: got %d; want 0", n)
+	}
+}
+
+func TestStripInvisibleFingerprintLarge(t *testing.T) {
+	const n = 1 << 20
+	in := "x" + strings.Repeat("\u200b\u200c", n/2) + "y"
+	stats, tr := NewFingerprintStripper()
+	s, _, err := transform.String(tr, in)
+	if s != "xy" || err != ErrFingerprintDetected {
+		t.Errorf("got %q, %v; want %q, %v", s, err, "xy", ErrFingerprintDetected)
+	}
+	if got := stats.Removed; got != n {
+		t.Errorf("got %d; want %d", got, n)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "strings"
+
+// ValidateRegionalIndicators returns a Transformer that replaces regional
+// indicator symbols (U+1F1E6–U+1F1FF) that are not part of a valid flag
+// sequence with the replacement character U+FFFD. A pair of regional
+// indicators is valid if it denotes a region for which the Unicode Emoji
+// specification defines a flag, such as 🇺🇸 for US.
+//
+// Indicators are paired from left to right. If an indicator cannot be paired
+// with the indicator that follows it, only the first is replaced and pairing
+// resumes at the next, so three consecutive indicators always yield at least
+// one replacement.
+func ValidateRegionalIndicators() Transformer {
+	return ValidateRegionalIndicatorsWith("\uFFFD")
+}
+
+// ValidateRegionalIndicatorsWith is like ValidateRegionalIndicators, but
+// replaces each invalid regional indicator with fallback. An empty fallback
+// removes invalid indicators.
+func ValidateRegionalIndicatorsWith(fallback string) Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		r, _ := s.ReadRune()
+		if !isRegionalIndicator(r) {
+			s.WriteRune(r)
+			return
+		}
+		// If the input ends prematurely, ReadRune will have set ErrShortSrc
+		// and we will be called again with more input.
+		if r2, size := s.ReadRune(); size > 0 && isValidFlag(r, r2) {
+			s.WriteRune(r)
+			s.WriteRune(r2)
+			return
+		}
+		s.UnreadRune()
+		s.WriteString(fallback)
+	})
+}
+
+const riA = '\U0001F1E6' // REGIONAL INDICATOR SYMBOL LETTER A
+
+func isRegionalIndicator(r rune) bool {
+	return riA <= r && r <= riA+25
+}
+
+// isValidFlag reports whether the regional indicators a and b form a flag
+// sequence.
+func isValidFlag(a, b rune) bool {
+	if !isRegionalIndicator(a) || !isRegionalIndicator(b) {
+		return false
+	}
+	return flagRegions[a-riA][b-riA]
+}
+
+// flagRegions[i][j] reports whether the region consisting of the ith and jth
+// letter of the alphabet has a flag.
+var flagRegions [26][26]bool
+
+func init() {
+	for _, code := range strings.Fields(flagRegionCodes) {
+		flagRegions[code[0]-'A'][code[1]-'A'] = true
+	}
+}
+
+// flagRegionCodes lists the regions of the RGI emoji flag sequences: the ISO
+// 3166-1 alpha-2 codes and the exceptionally reserved codes AC, CP, DG, EA,
+// EU, IC, TA, UN and XK.
+const flagRegionCodes = "" +
+	"AC AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ " +
+	"BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ " +
+	"CA CC CD CF CG CH CI CK CL CM CN CO CP CR CU CV CW CX CY CZ " +
+	"DE DG DJ DK DM DO DZ " +
+	"EA EC EE EG EH ER ES ET EU " +
+	"FI FJ FK FM FO FR " +
+	"GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY " +
+	"HK HM HN HR HT HU " +
+	"IC ID IE IL IM IN IO IQ IR IS IT " +
+	"JE JM JO JP " +
+	"KE KG KH KI KM KN KP KR KW KY KZ " +
+	"LA LB LC LI LK LR LS LT LU LV LY " +
+	"MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ " +
+	"NA NC NE NF NG NI NL NO NP NR NU NZ " +
+	"OM " +
+	"PA PE PF PG PH PK PL PM PN PR PS PT PW PY " +
+	"QA " +
+	"RE RO RS RU RW " +
+	"SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ " +
+	"TA TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ " +
+	"UA UG UM UN US UY UZ " +
+	"VA VC VE VG VI VN VU " +
+	"WF WS " +
+	"XK " +
+	"YE YT " +
+	"ZA ZM ZW"
@@ -0,0 +1,128 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+// ri returns the regional indicators for the letters of s.
+func ri(s string) string {
+	return strings.Map(func(r rune) rune { return riA + r - 'A' }, s)
+}
+
+func TestValidateRegionalIndicators(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "valid US",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a" + ri("US") + "b",
+		out:     "a" + ri("US") + "b",
+		outFull: "a" + ri("US") + "b",
+		t:       ValidateRegionalIndicators(),
+	}, {
+		desc:    "valid GB",
+		szDst:   large,
+		atEOF:   true,
+		in:      ri("GB"),
+		out:     ri("GB"),
+		outFull: ri("GB"),
+		t:       ValidateRegionalIndicators(),
+	}, {
+		desc:    "single at EOF",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x" + ri("U"),
+		out:     "x\uFFFD",
+		outFull: "x\uFFFD",
+		t:       ValidateRegionalIndicators(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "three indicators",
+		szDst:   large,
+		atEOF:   true,
+		in:      ri("USA"),
+		out:     ri("US") + "\uFFFD",
+		outFull: ri("US") + "\uFFFD",
+		t:       ValidateRegionalIndicators(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "invalid pair resynchronizes",
+		szDst:   large,
+		atEOF:   true,
+		in:      ri("QUS"),
+		out:     "\uFFFD" + ri("US"),
+		outFull: "\uFFFD" + ri("US"),
+		t:       ValidateRegionalIndicators(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "followed by non-indicator",
+		szDst:   large,
+		atEOF:   true,
+		in:      ri("U") + "S",
+		out:     "\uFFFDS",
+		outFull: "\uFFFDS",
+		t:       ValidateRegionalIndicators(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "more input",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a" + ri("U"),
+		out:     "a",
+		outFull: "a\uFFFD",
+		err:     transform.ErrShortSrc,
+		t:       ValidateRegionalIndicators(),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "custom fallback",
+		szDst:   large,
+		atEOF:   true,
+		in:      ri("ZZ") + "!",
+		out:     "??!",
+		outFull: "??!",
+		t:       ValidateRegionalIndicatorsWith("?"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "remove",
+		szDst:   large,
+		atEOF:   true,
+		in:      ri("EUZZ"),
+		out:     ri("EU"),
+		outFull: ri("EU"),
+		t:       ValidateRegionalIndicatorsWith(""),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestFlagRegions(t *testing.T) {
+	valid := map[string]bool{}
+	for _, code := range strings.Fields(flagRegionCodes) {
+		valid[code] = true
+	}
+	if got, want := len(valid), 258; got != want {
+		t.Errorf("number of flags: got %d; want %d", got, want)
+	}
+	tr := ValidateRegionalIndicators()
+	for a := 'A'; a <= 'Z'; a++ {
+		for b := 'A'; b <= 'Z'; b++ {
+			code := string([]rune{a, b})
+			want := "\uFFFD\uFFFD"
+			if valid[code] {
+				want = ri(code)
+			}
+			if got := tr.String(ri(code)); got != want {
+				t.Errorf("%s: got %+q; want %+q", code, got, want)
+			}
+		}
+	}
+}
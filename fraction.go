@@ -0,0 +1,72 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// A FractionMode defines how NormalizeFractions represents a fraction.
+type FractionMode int
+
+const (
+	// FractionModeASCII represents fractions as numerator/denominator, such
+	// as 1/4.
+	FractionModeASCII FractionMode = iota
+
+	// FractionModeDecimal represents fractions as the shortest decimal number
+	// that parses to the same float64 value, such as 0.25.
+	FractionModeDecimal
+)
+
+// NormalizeFractions returns a Transformer that replaces the vulgar fraction
+// characters, such as ½ and ⅞, with a representation using ASCII characters as
+// determined by mode. In FractionModeDecimal, ⅟ (FRACTION NUMERATOR ONE) is
+// left unchanged. It panics if mode is not a valid FractionMode.
+func NormalizeFractions(mode FractionMode) Transformer {
+	switch mode {
+	case FractionModeASCII:
+		return FlatMap(lookup(asciiFractions))
+	case FractionModeDecimal:
+		return FlatMap(lookup(decimalFractions))
+	}
+	panic(fmt.Sprintf("textutil: invalid FractionMode %d", mode))
+}
+
+var fractions = []struct {
+	r        rune
+	num, den int
+}{
+	{'¼', 1, 4},
+	{'½', 1, 2},
+	{'¾', 3, 4},
+	{'⅐', 1, 7},
+	{'⅑', 1, 9},
+	{'⅒', 1, 10},
+	{'⅓', 1, 3},
+	{'⅔', 2, 3},
+	{'⅕', 1, 5},
+	{'⅖', 2, 5},
+	{'⅗', 3, 5},
+	{'⅘', 4, 5},
+	{'⅙', 1, 6},
+	{'⅚', 5, 6},
+	{'⅛', 1, 8},
+	{'⅜', 3, 8},
+	{'⅝', 5, 8},
+	{'⅞', 7, 8},
+	{'↉', 0, 3},
+}
+
+var asciiFractions, decimalFractions = func() (ascii, decimal map[rune]string) {
+	ascii = map[rune]string{'⅟': "1/"}
+	decimal = map[rune]string{}
+	for _, f := range fractions {
+		ascii[f.r] = fmt.Sprintf("%d/%d", f.num, f.den)
+		decimal[f.r] = strconv.FormatFloat(float64(f.num)/float64(f.den), 'f', -1, 64)
+	}
+	return ascii, decimal
+}()
@@ -0,0 +1,76 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestNormalizeFractions(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "ASCII",
+		szDst:   large,
+		atEOF:   true,
+		in:      "add ½ cup and ¾ spoon, ⅞ ⅟",
+		out:     "add 1/2 cup and 3/4 spoon, 7/8 1/",
+		outFull: "add 1/2 cup and 3/4 spoon, 7/8 1/",
+		t:       NormalizeFractions(FractionModeASCII),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "decimal",
+		szDst:   large,
+		atEOF:   true,
+		in:      "add ½ cup and ¾ spoon, ⅛ ⅟",
+		out:     "add 0.5 cup and 0.75 spoon, 0.125 ⅟",
+		outFull: "add 0.5 cup and 0.75 spoon, 0.125 ⅟",
+		t:       NormalizeFractions(FractionModeDecimal),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no fractions",
+		szDst:   large,
+		atEOF:   true,
+		in:      "1/2 0.5 ⁄ ⅰ",
+		out:     "1/2 0.5 ⁄ ⅰ",
+		outFull: "1/2 0.5 ⁄ ⅰ",
+		t:       NormalizeFractions(FractionModeDecimal),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestFractionTables(t *testing.T) {
+	for _, f := range fractions {
+		in := string(f.r)
+
+		ascii := NormalizeFractions(FractionModeASCII).String(in)
+		if want := strings.Replace(norm.NFKC.String(in), "⁄", "/", 1); ascii != want {
+			t.Errorf("%U: got %q; want %q", f.r, ascii, want)
+		}
+
+		dec := NormalizeFractions(FractionModeDecimal).String(in)
+		x, err := strconv.ParseFloat(dec, 64)
+		if err != nil {
+			t.Errorf("%U: %v", f.r, err)
+		}
+		if want := float64(f.num) / float64(f.den); x != want {
+			t.Errorf("%U: got %v; want %v", f.r, x, want)
+		}
+	}
+}
+
+func TestFractionModeInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("did not panic")
+		}
+	}()
+	NormalizeFractions(FractionMode(-1))
+}
@@ -0,0 +1,169 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// fuzzTransformers lists the Transformers exercised by the fuzz tests. If
+// inverse is not nil, it must restore any valid UTF-8 input converted by t.
+// Transformers that may write more than fuzzMaxWrite bytes at once, such as
+// Interpolate and TSVToCSV, are not included.
+var fuzzTransformers = []struct {
+	name       string
+	t, inverse func() Transformer
+}{
+	{"ellipsis", NormalizeEllipsis, nil},
+	{"collapse", func() Transformer { return CollapseRuns(unicode.IsSpace, ' ') }, nil},
+	{"spaces", func() Transformer { return NewTransformer(NewCollapseSpacesRewriter(true)) }, nil},
+	{"lines", func() Transformer { return NewTransformer(NewLineEndingNormalizer()) }, nil},
+	{"ansi", func() Transformer { return NewTransformer(NewANSIStripper()) }, nil},
+	{"bom", func() Transformer { return NewTransformer(NewBOMStripper()) }, nil},
+	{"typographic", func() Transformer { return NewTransformer(NewTypographicEnhancer()) }, nil},
+	{"trie", func() Transformer {
+		return NewTransformer(NewTrieReplacer(map[string]string{"ab": "x", "abc": "yy", "b": ""}))
+	}, nil},
+	{"chain", func() Transformer {
+		return NewTransformer(ChainRewriters(rewriterOf(DenormalizeEllipsis()), &ellipsis{}))
+	}, nil},
+	{"window", func() Transformer {
+		return NewWindowTransformer(1, ' ', func(w []rune, c int) rune {
+			if w[c-1] == w[c+1] {
+				return '='
+			}
+			return w[c]
+		})
+	}, nil},
+	{"morse", func() Transformer { return NewTransformer(MorseDecode('.', '-', " ", " / ")) }, nil},
+	{"rle", func() Transformer { return RunLengthEncode(3) }, nil},
+	{"rle1", func() Transformer { return RunLengthEncode(1) }, RunLengthDecode},
+	{"unrle", RunLengthDecode, nil},
+	{"json",
+		func() Transformer { return NewTransformer(NewJSONStringEscaper()) },
+		func() Transformer { return NewTransformer(NewJSONStringUnescaper()) }},
+	{"go",
+		func() Transformer { return NewTransformer(NewGoStringEscaper()) },
+		func() Transformer { return NewTransformer(NewGoStringUnescaper()) }},
+	{"url",
+		func() Transformer { return NewTransformer(NewURLPercentEncoder("")) },
+		func() Transformer { return NewTransformer(NewURLPercentDecoder()) }},
+	{"html",
+		func() Transformer { return NewTransformer(NewHTMLEscaper()) },
+		func() Transformer { return NewTransformer(NewHTMLUnescaper()) }},
+}
+
+// fuzzMaxWrite is the maximum number of bytes written by a single call to
+// Rewrite for any of the fuzzTransformers, such as the 12 bytes of a
+// percent-encoded rune, and thus the smallest destination buffer with which
+// they are guaranteed to make progress.
+const fuzzMaxWrite = 16
+
+// addFuzzSeeds seeds f with the inputs of the test cases of TestRewriteMain,
+// using args to compute the remaining arguments for the i-th test case.
+func addFuzzSeeds(f *testing.F, args func(i int, tt transformTest) []interface{}) {
+	for i, tt := range rewriteMainTests(f) {
+		f.Add(append([]interface{}{tt.in}, args(i, tt)...)...)
+	}
+}
+
+func FuzzTransformerRoundTrip(f *testing.F) {
+	addFuzzSeeds(f, func(i int, tt transformTest) []interface{} {
+		return []interface{}{tt.szDst, tt.atEOF, uint8(i)}
+	})
+	f.Fuzz(func(t *testing.T, in string, szDst int, atEOF bool, which uint8) {
+		ft := fuzzTransformers[int(which)%len(fuzzTransformers)]
+		tr := ft.t()
+		want, err := tr.StringErr(in)
+		if err != nil || len(want) > large {
+			return
+		}
+
+		// The output of a single call to Transform is a prefix of the output
+		// for the complete input.
+		if szDst < 0 {
+			szDst = -szDst
+		}
+		dst := make([]byte, szDst%256)
+		tr.Reset()
+		nDst, nSrc, err := tr.Transform(dst, []byte(in), atEOF)
+		if nSrc > len(in) || !strings.HasPrefix(want, string(dst[:nDst])) {
+			t.Fatalf("%s:%q: got %q, %d, %v; want prefix of %q", ft.name, in, dst[:nDst], nSrc, err, want)
+		}
+
+		// Check the call, its continuation, and Span as the unit tests do.
+		tr.Reset()
+		nSpan, errSpan := tr.Span([]byte(in), atEOF)
+		if nSpan == 0 {
+			nSpan = -1
+		}
+		tt := transformTest{
+			desc:    ft.name,
+			szDst:   len(dst),
+			atEOF:   atEOF,
+			in:      in,
+			out:     string(dst[:nDst]),
+			outFull: want,
+			err:     err,
+			errSpan: errSpan,
+			nSpan:   nSpan,
+			t:       tr,
+		}
+		tr.Reset()
+		tt.check(t, int(which))
+
+		if got, err := transformChunks(tr, in, 1+szDst%5, fuzzMaxWrite); got != want || err != nil {
+			t.Errorf("%s:%q: chunks: got %q, %v; want %q, <nil>", ft.name, in, got, err, want)
+		}
+
+		if ft.inverse != nil && utf8.ValidString(in) {
+			if got, err := ft.inverse().StringErr(want); got != in || err != nil {
+				t.Errorf("%s:%q: round trip: got %q, %v via %q", ft.name, in, got, err, want)
+			}
+		}
+	})
+}
+
+func FuzzSpanConsistency(f *testing.F) {
+	addFuzzSeeds(f, func(i int, tt transformTest) []interface{} {
+		return []interface{}{tt.atEOF, uint8(i)}
+	})
+	f.Fuzz(func(t *testing.T, in string, atEOF bool, which uint8) {
+		ft := fuzzTransformers[int(which)%len(fuzzTransformers)]
+		tr := ft.t()
+		src := []byte(in)
+		n, err := tr.Span(src, atEOF)
+		switch {
+		case n < 0 || n > len(src):
+			t.Fatalf("%s:%q: got span %d; want at most %d", ft.name, in, n, len(src))
+		case err == nil && n != len(src):
+			t.Errorf("%s:%q: got span %d, <nil>; want %d", ft.name, in, n, len(src))
+		case err != nil && err != transform.ErrEndOfSpan && err != transform.ErrShortSrc:
+			return
+		}
+
+		// The spanned input must be left unchanged by Transform.
+		tr.Reset()
+		dst := make([]byte, large)
+		nDst, _, err := tr.Transform(dst, src, true)
+		if err != nil {
+			return
+		}
+		if !bytes.HasPrefix(dst[:nDst], src[:n]) {
+			t.Errorf("%s:%q: output %q differs from span %q", ft.name, in, dst[:nDst], src[:n])
+		}
+
+		// Transform must continue where Span left off.
+		if got, err := spanTransform(tr, in); got != string(dst[:nDst]) || err != nil {
+			t.Errorf("%s:%q: span then transform: got %q, %v; want %q, <nil>", ft.name, in, got, err, dst[:nDst])
+		}
+	})
+}
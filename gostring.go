@@ -0,0 +1,163 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode/utf8"
+
+// NewGoStringEscaper returns a Rewriter that escapes its input for use in an
+// interpreted Go string literal, without adding the enclosing quotation marks.
+// The output matches that of strconv.QuoteToASCII: it uses the escapes \a, \b,
+// \f, \n, \r, \t, \v, \\ and \" for the respective characters, \xHH for
+// other ASCII control characters and invalid UTF-8 bytes, and \uHHHH or
+// \UHHHHHHHH for all non-ASCII runes.
+func NewGoStringEscaper() Rewriter {
+	return rewriterFunc(func(s State) {
+		r, size := s.ReadRune()
+		switch esc := goEscapes[r&0x7f]; {
+		case r >= utf8.RuneSelf:
+			escapeNonASCII(s, r, size)
+		case esc != 0:
+			s.WriteBytes([]byte{'\\', esc})
+		case r < ' ' || r == 0x7f:
+			s.WriteBytes([]byte{'\\', 'x', hexDigits[r>>4], hexDigits[r&0xf]})
+		default:
+			s.WriteRune(r)
+		}
+	})
+}
+
+func escapeNonASCII(s State, r rune, size int) {
+	var buf [10]byte
+	switch {
+	case r == utf8.RuneError && size == 1:
+		// Escape the invalid byte itself.
+		c := lastByte(s)
+		s.WriteBytes([]byte{'\\', 'x', hexDigits[c>>4], hexDigits[c&0xf]})
+		return
+	case r < 0x10000:
+		buf[1] = 'u'
+		size = 6
+	default:
+		buf[1] = 'U'
+		size = 10
+	}
+	buf[0] = '\\'
+	for i := size - 1; i > 1; i-- {
+		buf[i] = hexDigits[r&0xf]
+		r >>= 4
+	}
+	s.WriteBytes(buf[:size])
+}
+
+// lastByte returns the last byte read from s.
+func lastByte(s State) byte {
+	b := s.Consumed()
+	return b[len(b)-1]
+}
+
+// goEscapes maps ASCII characters to the letter of their Go escape sequence
+// within an interpreted string literal.
+var goEscapes = [0x80]byte{
+	'\a': 'a',
+	'\b': 'b',
+	'\f': 'f',
+	'\n': 'n',
+	'\r': 'r',
+	'\t': 't',
+	'\v': 'v',
+	'\\': '\\',
+	'"':  '"',
+}
+
+// NewGoStringUnescaper returns a Rewriter that interprets the escape sequences
+// of an interpreted Go string literal, the inverse of NewGoStringEscaper:
+// the escapes \a, \b, \f, \n, \r, \t, \v, \\, \" and \', octal escapes of
+// exactly three digits, \xHH, \uHHHH and \UHHHHHHHH. Octal and hexadecimal
+// escapes are written as a single byte. A backslash that does not start a
+// valid escape sequence, or an escape denoting an invalid rune or a byte value
+// above 255, results in ErrInvalidEscape. All other input is written as is.
+func NewGoStringUnescaper() Rewriter {
+	return rewriterFunc(unescapeGo)
+}
+
+func unescapeGo(s State) {
+	if r, _ := s.ReadRune(); r != '\\' {
+		s.WriteRune(r)
+		return
+	}
+	// If ReadRune reaches the end of a buffer, it sets ErrShortSrc, which
+	// takes precedence over ErrInvalidEscape.
+	r, _ := s.ReadRune()
+	switch {
+	case r == '\'':
+		s.WriteRune(r)
+	case r < utf8.RuneSelf && goUnescapes[r] != 0:
+		s.WriteBytes([]byte{goUnescapes[r]})
+	case '0' <= r && r <= '7':
+		c := r - '0'
+		for i := 0; i < 2; i++ {
+			r, _ := s.ReadRune()
+			if r < '0' || '7' < r {
+				s.SetError(ErrInvalidEscape)
+				return
+			}
+			c = c<<3 | (r - '0')
+		}
+		if c > 0xff {
+			s.SetError(ErrInvalidEscape)
+			return
+		}
+		s.WriteBytes([]byte{byte(c)})
+	case r == 'x':
+		if c, ok := readHexRune(s, 2); ok {
+			s.WriteBytes([]byte{byte(c)})
+		}
+	case r == 'u':
+		if c, ok := readHexRune(s, 4); ok {
+			validRune(s, c)
+		}
+	case r == 'U':
+		if c, ok := readHexRune(s, 8); ok {
+			validRune(s, c)
+		}
+	default:
+		s.SetError(ErrInvalidEscape)
+	}
+}
+
+// goUnescapes is the inverse of goEscapes.
+var goUnescapes = func() (m [0x80]byte) {
+	for c, esc := range goEscapes {
+		if esc != 0 {
+			m[esc] = byte(c)
+		}
+	}
+	return m
+}()
+
+// readHexRune reads n hexadecimal digits. It sets ErrInvalidEscape if they are
+// not valid.
+func readHexRune(s State, n int) (r rune, ok bool) {
+	for i := 0; i < n; i++ {
+		h, _ := s.ReadRune()
+		d, ok := unhex(h)
+		if !ok {
+			s.SetError(ErrInvalidEscape)
+			return 0, false
+		}
+		r = r<<4 | rune(d)
+	}
+	return r, true
+}
+
+// validRune writes r if it is a valid rune and sets ErrInvalidEscape
+// otherwise.
+func validRune(s State, r rune) {
+	if !utf8.ValidRune(r) {
+		s.SetError(ErrInvalidEscape)
+		return
+	}
+	s.WriteRune(r)
+}
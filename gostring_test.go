@@ -0,0 +1,222 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strconv"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+func TestGoStringEscaper(t *testing.T) {
+	escape := NewTransformer(NewGoStringEscaper())
+
+	testCases := []transformTest{{
+		desc:    "escape simple",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\"b\\c\a\b\f\n\r\t\v'",
+		out:     `a\"b\\c\a\b\f\n\r\t\v'`,
+		outFull: `a\"b\\c\a\b\f\n\r\t\v'`,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "escape other control characters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x00\x01\x1b\x1f\x7f",
+		out:     `a\x00\x01\x1b\x1f\x7f`,
+		outFull: `a\x00\x01\x1b\x1f\x7f`,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "escape non-ASCII",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u00e9\u2028\ufffd\U0001f600",
+		out:     `a\u00e9\u2028\ufffd\U0001f600`,
+		outFull: `a\u00e9\u2028\ufffd\U0001f600`,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xed\xa0\x80\xffb",
+		out:     `a\xed\xa0\x80\xffb`,
+		outFull: `a\xed\xa0\x80\xffb`,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unescaped",
+		szDst:   large,
+		atEOF:   true,
+		in:      " !#$%&'()*+,-./09:;<=>?@AZ[]^_`az{|}~",
+		out:     " !#$%&'()*+,-./09:;<=>?@AZ[]^_`az{|}~",
+		outFull: " !#$%&'()*+,-./09:;<=>?@AZ[]^_`az{|}~",
+		t:       escape,
+	}, {
+		desc:    "short destination",
+		szDst:   6,
+		atEOF:   true,
+		in:      "ab\u00e9",
+		out:     "ab",
+		outFull: `ab\u00e9`,
+		err:     transform.ErrShortDst,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestGoStringUnescaper(t *testing.T) {
+	unescape := NewTransformer(NewGoStringUnescaper())
+
+	testCases := []transformTest{{
+		desc:    "unescape simple",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a\"b\\c\a\b\f\n\r\t\v\'`,
+		out:     "a\"b\\c\a\b\f\n\r\t\v'",
+		outFull: "a\"b\\c\a\b\f\n\r\t\v'",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unescape numeric",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a\000\101\377\x41\xff\u00e9\U0001F600`,
+		out:     "a\x00A\xffA\xff\u00e9\U0001f600",
+		outFull: "a\x00A\xffA\xff\u00e9\U0001f600",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unescaped",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\"b'\u00e9",
+		out:     "a\"b'\u00e9",
+		outFull: "a\"b'\u00e9",
+		t:       unescape,
+	}, {
+		desc:    "invalid escape",
+		szDst:   large,
+		atEOF:   true,
+		in:      `ab\q`,
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: ErrInvalidEscape,
+		nSpan:   2,
+	}, {
+		desc:    "octal out of range",
+		szDst:   large,
+		atEOF:   true,
+		in:      `ab\400`,
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: ErrInvalidEscape,
+		nSpan:   2,
+	}, {
+		desc:    "short octal",
+		szDst:   large,
+		atEOF:   true,
+		in:      `ab\12x`,
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: ErrInvalidEscape,
+		nSpan:   2,
+	}, {
+		desc:    "surrogate",
+		szDst:   large,
+		atEOF:   true,
+		in:      `ab\ud800`,
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: ErrInvalidEscape,
+		nSpan:   2,
+	}, {
+		desc:    "out of range",
+		szDst:   large,
+		atEOF:   true,
+		in:      `ab\U00110000`,
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: ErrInvalidEscape,
+		nSpan:   2,
+	}, {
+		desc:    "truncated at EOF",
+		szDst:   large,
+		atEOF:   true,
+		in:      `ab\u00e`,
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: ErrInvalidEscape,
+		nSpan:   2,
+	}, {
+		desc:    "escape split across buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      `ab\u00`,
+		out:     "ab",
+		outFull: "ab",
+		err:     transform.ErrShortSrc,
+		t:       unescape,
+		errSpan: transform.ErrShortSrc,
+		nSpan:   2,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestGoStringRoundTrip(t *testing.T) {
+	escape := NewTransformer(NewGoStringEscaper())
+	unescape := NewTransformer(NewGoStringUnescaper())
+
+	check := func(in string) {
+		want := strconv.QuoteToASCII(in)
+		got := escape.String(in)
+		if want = want[1 : len(want)-1]; got != want {
+			t.Errorf("escape(%+q): got %q; want %q", in, got, want)
+		}
+		if s, err := strconv.Unquote(`"` + got + `"`); err != nil || s != in {
+			t.Errorf("strconv.Unquote(%q): got %+q, %v; want %+q, <nil>", got, s, err, in)
+		}
+		if s, err := unescape.StringErr(got); err != nil || s != in {
+			t.Errorf("unescape(%q): got %+q, %v; want %+q, <nil>", got, s, err, in)
+		}
+	}
+	for r := rune(0); r <= utf8.MaxRune; r++ {
+		if utf8.ValidRune(r) {
+			check(string(r))
+		}
+	}
+	for c := 0x80; c < 0x100; c++ {
+		check(string([]byte{'a', byte(c), 'b'}))
+	}
+
+	// The unescaper must accept all escapes produced by strconv.Quote.
+	in := "a\"\\\x00\x7f\xff\u00e9\u2028\U0001f600'"
+	q := strconv.Quote(in)
+	if got, err := unescape.StringErr(q[1 : len(q)-1]); err != nil || got != in {
+		t.Errorf("unescape(%s): got %+q, %v; want %+q, <nil>", q, got, err, in)
+	}
+}
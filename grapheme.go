@@ -0,0 +1,244 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode"
+
+// TruncateAtGrapheme returns a Transformer that truncates its input after
+// maxRunes grapheme clusters, appending ellipsis if any input was removed.
+// Clusters are determined using the extended grapheme cluster rules of UAX #29,
+// so that a base letter is never separated from its combining marks, nor an
+// emoji from its modifiers. Prepend characters and the Grapheme_Extend
+// property of spacing marks are not taken into account.
+func TruncateAtGrapheme(maxRunes int, ellipsis string) Transformer {
+	return NewTransformer(&truncateGrapheme{max: maxRunes, ellipsis: ellipsis})
+}
+
+type truncateGrapheme struct {
+	max      int
+	ellipsis string
+
+	n    int // number of clusters written
+	prev graphemeState
+	done bool
+}
+
+func (t *truncateGrapheme) Reset() {
+	t.n, t.prev, t.done = 0, graphemeState{}, false
+}
+
+func (t *truncateGrapheme) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if t.done {
+		return
+	}
+	next, isBreak := t.prev.next(r)
+	if isBreak && t.n == t.max {
+		if s.WriteString(t.ellipsis) {
+			t.done = true
+		}
+		return
+	}
+	if s.WriteRune(r) {
+		t.prev = next
+		if isBreak {
+			t.n++
+		}
+	}
+}
+
+// A graphemeClass is a value of the Grapheme_Cluster_Break property as defined
+// in UAX #29, with the addition of gcExtPict for Extended_Pictographic runes.
+type graphemeClass uint8
+
+const (
+	gcNone graphemeClass = iota // start of text
+	gcOther
+	gcCR
+	gcLF
+	gcControl
+	gcExtend
+	gcZWJ
+	gcRegionalIndicator
+	gcSpacingMark
+	gcL
+	gcV
+	gcT
+	gcLV
+	gcLVT
+	gcExtPict
+)
+
+func graphemeClassOf(r rune) graphemeClass {
+	switch {
+	case r < 0x7f && r >= 0x20:
+		return gcOther
+	case r == '\r':
+		return gcCR
+	case r == '\n':
+		return gcLF
+	case r == 0x200D:
+		return gcZWJ
+	case 0x1F1E6 <= r && r <= 0x1F1FF:
+		return gcRegionalIndicator
+	case unicode.In(r, unicode.Mn, unicode.Me) || unicode.Is(graphemeExtend, r):
+		return gcExtend
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gcControl
+	case unicode.Is(unicode.Mc, r):
+		return gcSpacingMark
+	case 0x1100 <= r && r <= 0x115F || 0xA960 <= r && r <= 0xA97C:
+		return gcL
+	case 0x1160 <= r && r <= 0x11A7 || 0xD7B0 <= r && r <= 0xD7C6:
+		return gcV
+	case 0x11A8 <= r && r <= 0x11FF || 0xD7CB <= r && r <= 0xD7FB:
+		return gcT
+	case hangulBase <= r && r < hangulEnd:
+		if isHangulLV(r) {
+			return gcLV
+		}
+		return gcLVT
+	case unicode.Is(extendedPictographic, r):
+		return gcExtPict
+	}
+	return gcOther
+}
+
+// graphemeState holds the context needed to determine whether there is a
+// grapheme cluster boundary before the next rune.
+type graphemeState struct {
+	class graphemeClass
+	// pict is set if the class is gcExtPict or gcExtend or gcZWJ following an
+	// Extended_Pictographic rune (rule GB11).
+	pict bool
+	// oddRI is set if the cluster ends in an odd number of regional
+	// indicators (rules GB12 and GB13).
+	oddRI bool
+}
+
+// next returns the state after r and reports whether there is a grapheme
+// cluster boundary before r.
+func (p graphemeState) next(r rune) (s graphemeState, isBreak bool) {
+	c := graphemeClassOf(r)
+	s.class = c
+	switch c {
+	case gcExtPict:
+		s.pict = true
+	case gcExtend, gcZWJ:
+		s.pict = p.pict && (p.class == gcExtPict || p.class == gcExtend)
+	case gcRegionalIndicator:
+		s.oddRI = !p.oddRI
+	}
+	switch {
+	case p.class == gcNone:
+		return s, true
+	case p.class == gcCR && c == gcLF: // GB3
+		return s, false
+	case p.class == gcCR, p.class == gcLF, p.class == gcControl: // GB4
+		return s, true
+	case c == gcCR, c == gcLF, c == gcControl: // GB5
+		return s, true
+	case p.class == gcL && (c == gcL || c == gcV || c == gcLV || c == gcLVT): // GB6
+		return s, false
+	case (p.class == gcLV || p.class == gcV) && (c == gcV || c == gcT): // GB7
+		return s, false
+	case (p.class == gcLVT || p.class == gcT) && c == gcT: // GB8
+		return s, false
+	case c == gcExtend, c == gcZWJ, c == gcSpacingMark: // GB9, GB9a
+		return s, false
+	case p.class == gcZWJ && p.pict && c == gcExtPict: // GB11
+		return s, false
+	case p.class == gcRegionalIndicator && c == gcRegionalIndicator: // GB12, GB13
+		return s, !p.oddRI
+	}
+	return s, true
+}
+
+// graphemeExtend holds the runes with Grapheme_Cluster_Break=Extend that are
+// not nonspacing or enclosing marks.
+var graphemeExtend = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x200c, Hi: 0x200c, Stride: 1}, // ZERO WIDTH NON-JOINER
+		{Lo: 0xff9e, Hi: 0xff9f, Stride: 1}, // halfwidth sound marks
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f3fb, Hi: 0x1f3ff, Stride: 1}, // emoji modifiers
+		{Lo: 0xe0020, Hi: 0xe007f, Stride: 1}, // tags
+	},
+}
+
+// extendedPictographic approximates the Extended_Pictographic property of
+// Unicode 15.
+var extendedPictographic = &unicode.RangeTable{
+	LatinOffset: 1,
+	R16: []unicode.Range16{
+		{Lo: 0x00a9, Hi: 0x00ae, Stride: 5},
+		{Lo: 0x203c, Hi: 0x2049, Stride: 13},
+		{Lo: 0x2122, Hi: 0x2139, Stride: 23},
+		{Lo: 0x2194, Hi: 0x2199, Stride: 1},
+		{Lo: 0x21a9, Hi: 0x21aa, Stride: 1},
+		{Lo: 0x231a, Hi: 0x231b, Stride: 1},
+		{Lo: 0x2328, Hi: 0x2388, Stride: 96},
+		{Lo: 0x23cf, Hi: 0x23cf, Stride: 1},
+		{Lo: 0x23e9, Hi: 0x23f3, Stride: 1},
+		{Lo: 0x23f8, Hi: 0x23fa, Stride: 1},
+		{Lo: 0x24c2, Hi: 0x24c2, Stride: 1},
+		{Lo: 0x25aa, Hi: 0x25ab, Stride: 1},
+		{Lo: 0x25b6, Hi: 0x25c0, Stride: 10},
+		{Lo: 0x25fb, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2600, Hi: 0x2605, Stride: 1},
+		{Lo: 0x2607, Hi: 0x2612, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2685, Stride: 1},
+		{Lo: 0x2690, Hi: 0x2705, Stride: 1},
+		{Lo: 0x2708, Hi: 0x2712, Stride: 1},
+		{Lo: 0x2714, Hi: 0x2716, Stride: 2},
+		{Lo: 0x271d, Hi: 0x2721, Stride: 4},
+		{Lo: 0x2728, Hi: 0x2733, Stride: 11},
+		{Lo: 0x2734, Hi: 0x2744, Stride: 16},
+		{Lo: 0x2747, Hi: 0x2747, Stride: 1},
+		{Lo: 0x274c, Hi: 0x274e, Stride: 2},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2763, Hi: 0x2767, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27a1, Hi: 0x27b0, Stride: 15},
+		{Lo: 0x27bf, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2b05, Hi: 0x2b07, Stride: 1},
+		{Lo: 0x2b1b, Hi: 0x2b1c, Stride: 1},
+		{Lo: 0x2b50, Hi: 0x2b55, Stride: 5},
+		{Lo: 0x3030, Hi: 0x303d, Stride: 13},
+		{Lo: 0x3297, Hi: 0x3299, Stride: 2},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1f0ff, Stride: 1},
+		{Lo: 0x1f10d, Hi: 0x1f10f, Stride: 1},
+		{Lo: 0x1f12f, Hi: 0x1f12f, Stride: 1},
+		{Lo: 0x1f16c, Hi: 0x1f171, Stride: 1},
+		{Lo: 0x1f17e, Hi: 0x1f17f, Stride: 1},
+		{Lo: 0x1f18e, Hi: 0x1f18e, Stride: 1},
+		{Lo: 0x1f191, Hi: 0x1f19a, Stride: 1},
+		{Lo: 0x1f1ad, Hi: 0x1f1e5, Stride: 1},
+		{Lo: 0x1f201, Hi: 0x1f20f, Stride: 1},
+		{Lo: 0x1f21a, Hi: 0x1f22f, Stride: 21},
+		{Lo: 0x1f232, Hi: 0x1f23a, Stride: 1},
+		{Lo: 0x1f23c, Hi: 0x1f23f, Stride: 1},
+		{Lo: 0x1f249, Hi: 0x1f3fa, Stride: 1},
+		{Lo: 0x1f400, Hi: 0x1f53d, Stride: 1},
+		{Lo: 0x1f546, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f680, Hi: 0x1f6ff, Stride: 1},
+		{Lo: 0x1f774, Hi: 0x1f77f, Stride: 1},
+		{Lo: 0x1f7d5, Hi: 0x1f7ff, Stride: 1},
+		{Lo: 0x1f80c, Hi: 0x1f80f, Stride: 1},
+		{Lo: 0x1f848, Hi: 0x1f84f, Stride: 1},
+		{Lo: 0x1f85a, Hi: 0x1f85f, Stride: 1},
+		{Lo: 0x1f888, Hi: 0x1f88f, Stride: 1},
+		{Lo: 0x1f8ae, Hi: 0x1f8ff, Stride: 1},
+		{Lo: 0x1f90c, Hi: 0x1f93a, Stride: 1},
+		{Lo: 0x1f93c, Hi: 0x1f945, Stride: 1},
+		{Lo: 0x1f947, Hi: 0x1faff, Stride: 1},
+		{Lo: 0x1fc00, Hi: 0x1fffd, Stride: 1},
+	},
+}
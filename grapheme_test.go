@@ -0,0 +1,157 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestTruncateAtGrapheme(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "truncate",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, world",
+		out:     "Hello…",
+		outFull: "Hello…",
+		t:       TruncateAtGrapheme(5, "…"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   5,
+	}, {
+		desc:    "combining marks",
+		szDst:   large,
+		atEOF:   true,
+		in:      "e\u0301e\u0301\u0323e\u0301",
+		out:     "e\u0301e\u0301\u0323...",
+		outFull: "e\u0301e\u0301\u0323...",
+		t:       TruncateAtGrapheme(2, "..."),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("e\u0301e\u0301\u0323"),
+	}, {
+		desc:    "emoji modifier",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\U0001F44B\U0001F3FD\U0001F44B\U0001F3FF",
+		out:     "\U0001F44B\U0001F3FD~",
+		outFull: "\U0001F44B\U0001F3FD~",
+		t:       TruncateAtGrapheme(1, "~"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("\U0001F44B\U0001F3FD"),
+	}, {
+		desc:    "ZWJ sequence",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\U0001F469\u200d\U0001F469\u200d\U0001F467x\U0001F469",
+		out:     "\U0001F469\u200d\U0001F469\u200d\U0001F467x~",
+		outFull: "\U0001F469\u200d\U0001F469\u200d\U0001F467x~",
+		t:       TruncateAtGrapheme(2, "~"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("\U0001F469\u200d\U0001F469\u200d\U0001F467x"),
+	}, {
+		desc:    "regional indicators",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\U0001F1F3\U0001F1F1\U0001F1E7\U0001F1EA\U0001F1E9",
+		out:     "\U0001F1F3\U0001F1F1\U0001F1E7\U0001F1EA~",
+		outFull: "\U0001F1F3\U0001F1F1\U0001F1E7\U0001F1EA~",
+		t:       TruncateAtGrapheme(2, "~"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("\U0001F1F3\U0001F1F1\U0001F1E7\U0001F1EA"),
+	}, {
+		desc:    "Hangul",
+		szDst:   large,
+		atEOF:   true,
+		in:      "각각각",
+		out:     "각각~",
+		outFull: "각각~",
+		t:       TruncateAtGrapheme(2, "~"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("각각"),
+	}, {
+		desc:    "line breaks",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\r\n\u0301b",
+		out:     "a\r\n\u0301~",
+		outFull: "a\r\n\u0301~",
+		t:       TruncateAtGrapheme(3, "~"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("a\r\n\u0301"),
+	}, {
+		desc:    "shorter input",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "abc",
+		outFull: "abc",
+		t:       TruncateAtGrapheme(5, "…"),
+	}, {
+		desc:    "exact limit",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abce\u0301",
+		out:     "abce\u0301",
+		outFull: "abce\u0301",
+		t:       TruncateAtGrapheme(4, "…"),
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "abcd",
+		out:     "ab",
+		outFull: "ab…",
+		err:     transform.ErrShortDst,
+		t:       TruncateAtGrapheme(2, "…"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestGraphemeBreaks(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"ab", []string{"a", "b"}},
+		{"e\u0301\u0308x", []string{"e\u0301\u0308", "x"}},
+		{"\r\n\n\r", []string{"\r\n", "\n", "\r"}},
+		{"\u0301a", []string{"\u0301", "a"}},
+		{"क\u093f", []string{"क\u093f"}},
+		{"a\u200db", []string{"a\u200d", "b"}},
+		{"❤\ufe0f\u200d\U0001F525", []string{"❤\ufe0f\u200d\U0001F525"}},
+		{"\U0001F1FA\U0001F1F8\U0001F1FA", []string{"\U0001F1FA\U0001F1F8", "\U0001F1FA"}},
+		{"\U0001F3F4\U000E0067\U000E0062\U000E007F", []string{"\U0001F3F4\U000E0067\U000E0062\U000E007F"}},
+		{"\uac00\uac00", []string{"\uac00", "\uac00"}},
+		{"\u1100\u1161\u11a8\uac00\u11a8", []string{"\u1100\u1161\u11a8", "\uac00\u11a8"}},
+		{"\uac01\u1161", []string{"\uac01", "\u1161"}},
+	}
+	for _, tc := range testCases {
+		var got []string
+		var p graphemeState
+		for _, r := range tc.in {
+			var isBreak bool
+			if p, isBreak = p.next(r); isBreak {
+				got = append(got, "")
+			}
+			got[len(got)-1] += string(r)
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%+q: got %+q; want %+q", tc.in, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%+q: got %+q; want %+q", tc.in, got, tc.want)
+				break
+			}
+		}
+	}
+}
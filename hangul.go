@@ -0,0 +1,86 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// Constants for the algorithmic Hangul decomposition and composition as
+// defined in Section 3.12 of the Unicode Standard.
+const (
+	hangulBase = 0xAC00 // SBase
+	jamoLBase  = 0x1100
+	jamoVBase  = 0x1161
+	jamoTBase  = 0x11A7
+
+	jamoLCount = 19
+	jamoVCount = 21
+	jamoTCount = 28
+	jamoNCount = jamoVCount * jamoTCount
+	hangulEnd  = hangulBase + jamoLCount*jamoNCount
+)
+
+// HangulDecompose returns a Transformer that decomposes precomposed Hangul
+// syllables into their conjoining jamo. Other runes are left unchanged.
+//
+// Unlike NFD, it only applies the Hangul decomposition algorithm.
+func HangulDecompose() Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		r, _ := s.ReadRune()
+		if r < hangulBase || hangulEnd <= r {
+			s.WriteRune(r)
+			return
+		}
+		i := r - hangulBase
+		if !s.WriteRune(jamoLBase + i/jamoNCount) {
+			return
+		}
+		if !s.WriteRune(jamoVBase + i%jamoNCount/jamoTCount) {
+			return
+		}
+		if t := i % jamoTCount; t != 0 {
+			s.WriteRune(jamoTBase + t)
+		}
+	})
+}
+
+// HangulCompose returns a Transformer that composes sequences of conjoining
+// jamo into precomposed Hangul syllables. It also composes an LV syllable
+// followed by a trailing consonant. Other runes are left unchanged.
+//
+// Unlike NFC, it only applies the Hangul composition algorithm.
+func HangulCompose() Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		r, _ := s.ReadRune()
+		switch {
+		case isJamoL(r):
+			v, _ := s.ReadRune()
+			if !isJamoV(v) {
+				s.UnreadRune()
+				s.WriteRune(r)
+				return
+			}
+			r = hangulBase + ((r-jamoLBase)*jamoVCount+v-jamoVBase)*jamoTCount
+		case isHangulLV(r):
+		default:
+			s.WriteRune(r)
+			return
+		}
+		// r is an LV syllable.
+		if t, _ := s.ReadRune(); isJamoT(t) {
+			r += t - jamoTBase
+		} else {
+			s.UnreadRune()
+		}
+		s.WriteRune(r)
+	})
+}
+
+func isJamoL(r rune) bool { return jamoLBase <= r && r < jamoLBase+jamoLCount }
+func isJamoV(r rune) bool { return jamoVBase <= r && r < jamoVBase+jamoVCount }
+
+// isJamoT reports whether r is a trailing consonant. jamoTBase itself is not.
+func isJamoT(r rune) bool { return jamoTBase < r && r < jamoTBase+jamoTCount }
+
+func isHangulLV(r rune) bool {
+	return hangulBase <= r && r < hangulEnd && (r-hangulBase)%jamoTCount == 0
+}
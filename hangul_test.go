@@ -0,0 +1,120 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestHangul(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "decompose LV",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\uac00",
+		out:     "a\u1100\u1161",
+		outFull: "a\u1100\u1161",
+		t:       HangulDecompose(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "decompose LVT",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\ud4db",
+		out:     "a\u1111\u1171\u11b6",
+		outFull: "a\u1111\u1171\u11b6",
+		t:       HangulDecompose(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "decompose short destination",
+		szDst:   7,
+		atEOF:   true,
+		in:      "a\ud4db",
+		out:     "a",
+		outFull: "a\u1111\u1171\u11b6",
+		err:     transform.ErrShortDst,
+		t:       HangulDecompose(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "decompose non-Hangul",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc \u1100\u1161 \u3131",
+		out:     "abc \u1100\u1161 \u3131",
+		outFull: "abc \u1100\u1161 \u3131",
+		t:       HangulDecompose(),
+	}, {
+		desc:    "compose LV and LVT",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u1100\u1161 \u1111\u1171\u11b6",
+		out:     "a\uac00 \ud4db",
+		outFull: "a\uac00 \ud4db",
+		t:       HangulCompose(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "compose LV syllable and T",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\uac00\u11a8",
+		out:     "a\uac01",
+		outFull: "a\uac01",
+		t:       HangulCompose(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "compose LVT syllable and T",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\uac01\u11a8",
+		out:     "\uac01\u11a8",
+		outFull: "\uac01\u11a8",
+		t:       HangulCompose(),
+	}, {
+		desc:    "compose lone jamo",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u1100\u1100a\u1161\u11a8",
+		out:     "\u1100\u1100a\u1161\u11a8",
+		outFull: "\u1100\u1100a\u1161\u11a8",
+		t:       HangulCompose(),
+	}, {
+		desc:    "compose at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a\u1100\u1161",
+		out:     "a",
+		outFull: "a\uac00",
+		err:     transform.ErrShortSrc,
+		t:       HangulCompose(),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestHangulAllSyllables(t *testing.T) {
+	var inputs []string
+	for r := rune(hangulBase); r < hangulEnd; r++ {
+		s := string(r)
+		inputs = append(inputs, s)
+		if got, want := HangulDecompose().String(s), norm.NFD.String(s); got != want {
+			t.Errorf("%U: got %+q; want %+q", r, got, want)
+		}
+	}
+	if n := len(inputs); n != 11172 {
+		t.Errorf("got %d syllables; want 11172", n)
+	}
+	roundTrip(t, HangulDecompose(), HangulCompose(), inputs)
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// NewHTMLEscaper returns a Rewriter that escapes the characters <, >, &, ' and
+// " as HTML entities, like html.EscapeString.
+func NewHTMLEscaper() Rewriter {
+	return rewriterFunc(func(s State) {
+		switch r, _ := s.ReadRune(); r {
+		case '&':
+			s.WriteString("&amp;")
+		case '<':
+			s.WriteString("&lt;")
+		case '>':
+			s.WriteString("&gt;")
+		case '"':
+			s.WriteString("&#34;")
+		case '\'':
+			s.WriteString("&#39;")
+		default:
+			s.WriteRune(r)
+		}
+	})
+}
+
+// NewHTMLUnescaper returns a Rewriter that replaces the entities &amp;, &lt;,
+// &gt;, &quot; and &apos; and all numeric character references, such as &#39;
+// and &#x27;, with the characters they denote. Numeric references to code
+// points that are not valid runes are replaced with utf8.RuneError. Other
+// entities, and entities lacking the terminating semicolon, are written as is.
+func NewHTMLUnescaper() Rewriter {
+	return rewriterFunc(unescapeHTML)
+}
+
+// maxEntityLen is the maximum length of an entity, excluding the ampersand and
+// semicolon, that is recognized by NewHTMLUnescaper.
+const maxEntityLen = 16
+
+var htmlEntities = map[string]rune{
+	"amp":  '&',
+	"lt":   '<',
+	"gt":   '>',
+	"quot": '"',
+	"apos": '\'',
+}
+
+func unescapeHTML(s State) {
+	if r, _ := s.ReadRune(); r != '&' {
+		s.WriteRune(r)
+		return
+	}
+	var buf [maxEntityLen]byte
+	n := 0
+	for {
+		r, size := s.ReadRune()
+		if size == 0 {
+			if !s.IsAtEOF() {
+				return // ReadRune set ErrShortSrc.
+			}
+			break
+		}
+		if r == ';' {
+			if c, ok := htmlEntity(buf[:n]); ok {
+				s.WriteRune(c)
+				return
+			}
+			s.UnreadRune()
+			break
+		}
+		if n == len(buf) || !isAlnum(r) && r != '#' {
+			s.UnreadRune()
+			break
+		}
+		buf[n] = byte(r)
+		n++
+	}
+	// Not a recognized entity.
+	s.WriteRune('&')
+	s.WriteBytes(buf[:n])
+}
+
+// htmlEntity returns the rune denoted by the given entity name.
+func htmlEntity(name []byte) (r rune, ok bool) {
+	if len(name) < 2 || name[0] != '#' {
+		r, ok = htmlEntities[string(name)]
+		return r, ok
+	}
+	digits, base := name[1:], 10
+	if digits[0] == 'x' || digits[0] == 'X' {
+		digits, base = digits[1:], 16
+	}
+	v, err := strconv.ParseUint(string(digits), base, 32)
+	if err != nil && err.(*strconv.NumError).Err != strconv.ErrRange {
+		return 0, false
+	}
+	if v == 0 || v > utf8.MaxRune || !utf8.ValidRune(rune(v)) {
+		return utf8.RuneError, true
+	}
+	return rune(v), true
+}
+
+func isAlnum(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+}
@@ -0,0 +1,141 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"html"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestHTMLEscaper(t *testing.T) {
+	escape := NewTransformer(NewHTMLEscaper())
+	testCases := []transformTest{{
+		desc:    "escape",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a<b c="d">'e' & f</b>`,
+		out:     "a&lt;b c=&#34;d&#34;&gt;&#39;e&#39; &amp; f&lt;/b&gt;",
+		outFull: "a&lt;b c=&#34;d&#34;&gt;&#39;e&#39; &amp; f&lt;/b&gt;",
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "nothing to escape",
+		szDst:   large,
+		atEOF:   true,
+		in:      "H\u00e9llo, w\u00f8rld! ;#/",
+		out:     "H\u00e9llo, w\u00f8rld! ;#/",
+		outFull: "H\u00e9llo, w\u00f8rld! ;#/",
+		t:       escape,
+	}, {
+		desc:    "short destination",
+		szDst:   5,
+		atEOF:   true,
+		in:      "ab<c",
+		out:     "ab",
+		outFull: "ab&lt;c",
+		err:     transform.ErrShortDst,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	in := `<a href="x?y=1&z='2'">\u00e9</a>`
+	if got, want := escape.String(in), html.EscapeString(in); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestHTMLUnescaper(t *testing.T) {
+	unescape := NewTransformer(NewHTMLUnescaper())
+	testCases := []transformTest{{
+		desc:    "named",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a&lt;b&gt;&amp;&quot;&apos;",
+		out:     "a<b>&\"'",
+		outFull: "a<b>&\"'",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "numeric",
+		szDst:   large,
+		atEOF:   true,
+		in:      "&#39;&#x27;&#X2208;&#233;&#x1F600;&#0065;",
+		out:     "''\u2208\u00e9\U0001f600A",
+		outFull: "''\u2208\u00e9\U0001f600A",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "invalid code points",
+		szDst:   large,
+		atEOF:   true,
+		in:      "&#0;&#xD800;&#x110000;&#99999999999;",
+		out:     "\ufffd\ufffd\ufffd\ufffd",
+		outFull: "\ufffd\ufffd\ufffd\ufffd",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "not entities",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a & b &c &nbsp; &#; &#x; &#12a; &&amp; &amp &#x41",
+		out:     "a & b &c &nbsp; &#; &#x; &#12a; && &amp &#x41",
+		outFull: "a & b &c &nbsp; &#; &#x; &#12a; && &amp &#x41",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("a & b &c &nbsp; &#; &#x; &#12a; &"),
+	}, {
+		desc:    "too long",
+		szDst:   large,
+		atEOF:   true,
+		in:      "&#00000000000000065;&abcdefghijklmnopqrstuvwxyz;",
+		out:     "&#00000000000000065;&abcdefghijklmnopqrstuvwxyz;",
+		outFull: "&#00000000000000065;&abcdefghijklmnopqrstuvwxyz;",
+		t:       unescape,
+	}, {
+		desc:    "entity at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a&amp",
+		out:     "a",
+		outFull: "a&amp",
+		err:     transform.ErrShortSrc,
+		t:       unescape,
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "ab&#x2208;",
+		out:     "ab",
+		outFull: "ab\u2208",
+		err:     transform.ErrShortDst,
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	for _, sz := range [][2]int{{1, 4}, {2, 4}, {5, 8}} {
+		got, err := transformChunks(unescape, "a&amp;b&#x1F600;&lt", sz[0], sz[1])
+		if want := "a&b\U0001f600&lt"; got != want || err != nil {
+			t.Errorf("%v: got %+q, %v; want %+q, <nil>", sz, got, err, want)
+		}
+	}
+
+	roundTrip(t, NewTransformer(NewHTMLEscaper()), unescape, []string{
+		"",
+		"abc",
+		`a<b c="d">'e' & f</b>`,
+		"&amp;&#39;\u00e9",
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"sort"
+	"unicode"
+)
+
+// StripDefaultIgnorable returns a Transformer that removes all code points
+// with the Unicode property Default_Ignorable_Code_Point. These include the
+// soft hyphen, zero-width characters, bidirectional formatting characters,
+// variation selectors and tag characters.
+func StripDefaultIgnorable() Transformer {
+	return RemoveFunc(isDefaultIgnorable)
+}
+
+// NewInvisibleStripper returns a Rewriter that removes invisible characters
+// that commonly cause display and security issues: the soft hyphen, the zero
+// width non-joiner and joiner, the word joiner and the bidirectional control
+// characters. The additional runes are removed as well.
+//
+// Unlike StripDefaultIgnorable, it does not remove, for instance, variation
+// selectors, which affect the display of the preceding character.
+func NewInvisibleStripper(additional ...rune) Rewriter {
+	strip := make([]rune, 0, len(invisible)+len(additional))
+	strip = append(append(strip, invisible...), additional...)
+	sort.Slice(strip, func(i, j int) bool { return strip[i] < strip[j] })
+	return NewFilterRewriter(func(r rune) bool {
+		i := sort.Search(len(strip), func(i int) bool { return strip[i] >= r })
+		return i == len(strip) || strip[i] != r
+	})
+}
+
+// invisible lists the runes removed by NewInvisibleStripper in increasing
+// order.
+var invisible = []rune{
+	'\u00ad', // SOFT HYPHEN
+	'\u200c', // ZERO WIDTH NON-JOINER
+	'\u200d', // ZERO WIDTH JOINER
+	'\u200e', // LEFT-TO-RIGHT MARK
+	'\u200f', // RIGHT-TO-LEFT MARK
+	'\u202a', // LEFT-TO-RIGHT EMBEDDING
+	'\u202b', // RIGHT-TO-LEFT EMBEDDING
+	'\u202c', // POP DIRECTIONAL FORMATTING
+	'\u202d', // LEFT-TO-RIGHT OVERRIDE
+	'\u202e', // RIGHT-TO-LEFT OVERRIDE
+	'\u2060', // WORD JOINER
+	'\u2066', // LEFT-TO-RIGHT ISOLATE
+	'\u2067', // RIGHT-TO-LEFT ISOLATE
+	'\u2068', // FIRST STRONG ISOLATE
+	'\u2069', // POP DIRECTIONAL ISOLATE
+}
+
+func isDefaultIgnorable(r rune) bool {
+	return r >= 0xAD && unicode.Is(defaultIgnorable, r)
+}
+
+// defaultIgnorable is derived from DerivedCoreProperties.txt of Unicode 15.0.0.
+var defaultIgnorable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00ad, Hi: 0x00ad, Stride: 1}, // SOFT HYPHEN
+		{Lo: 0x034f, Hi: 0x034f, Stride: 1}, // COMBINING GRAPHEME JOINER
+		{Lo: 0x061c, Hi: 0x061c, Stride: 1}, // ARABIC LETTER MARK
+		{Lo: 0x115f, Hi: 0x1160, Stride: 1}, // HANGUL CHOSEONG FILLER..HANGUL JUNGSEONG FILLER
+		{Lo: 0x17b4, Hi: 0x17b5, Stride: 1}, // KHMER VOWEL INHERENT AQ..KHMER VOWEL INHERENT AA
+		{Lo: 0x180b, Hi: 0x180f, Stride: 1}, // MONGOLIAN FREE VARIATION SELECTORs and VOWEL SEPARATOR
+		{Lo: 0x200b, Hi: 0x200f, Stride: 1}, // ZERO WIDTH SPACE..RIGHT-TO-LEFT MARK
+		{Lo: 0x202a, Hi: 0x202e, Stride: 1}, // LEFT-TO-RIGHT EMBEDDING..RIGHT-TO-LEFT OVERRIDE
+		{Lo: 0x2060, Hi: 0x206f, Stride: 1}, // WORD JOINER..NOMINAL DIGIT SHAPES
+		{Lo: 0x3164, Hi: 0x3164, Stride: 1}, // HANGUL FILLER
+		{Lo: 0xfe00, Hi: 0xfe0f, Stride: 1}, // VARIATION SELECTOR-1..VARIATION SELECTOR-16
+		{Lo: 0xfeff, Hi: 0xfeff, Stride: 1}, // ZERO WIDTH NO-BREAK SPACE
+		{Lo: 0xffa0, Hi: 0xffa0, Stride: 1}, // HALFWIDTH HANGUL FILLER
+		{Lo: 0xfff0, Hi: 0xfff8, Stride: 1}, // <reserved>
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1bca0, Hi: 0x1bca3, Stride: 1}, // SHORTHAND FORMAT LETTER OVERLAP..SHORTHAND FORMAT UP STEP
+		{Lo: 0x1d173, Hi: 0x1d17a, Stride: 1}, // MUSICAL SYMBOL BEGIN BEAM..MUSICAL SYMBOL END PHRASE
+		{Lo: 0xe0000, Hi: 0xe0fff, Stride: 1}, // Tags, VARIATION SELECTOR-17..VARIATION SELECTOR-256 and <reserved>
+	},
+	LatinOffset: 1,
+}
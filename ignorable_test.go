@@ -0,0 +1,124 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+	"unicode"
+
+	"golang.org/x/text/transform"
+)
+
+func TestStripDefaultIgnorable(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "assorted",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\ufeffb\u00adc\u200bd\U000e0020e\U000e0041f",
+		out:     "abcdef",
+		outFull: "abcdef",
+		t:       StripDefaultIgnorable(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "variation selectors",
+		szDst:   large,
+		atEOF:   true,
+		in:      "❤\ufe0f ⛄\ufe0e 辻\U000e0100",
+		out:     "❤ ⛄ 辻",
+		outFull: "❤ ⛄ 辻",
+		t:       StripDefaultIgnorable(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("❤"),
+	}, {
+		desc:    "visible text",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Héllo, wørld! 日本語 \u0301-",
+		out:     "Héllo, wørld! 日本語 \u0301-",
+		outFull: "Héllo, wørld! 日本語 \u0301-",
+		t:       StripDefaultIgnorable(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	for r := rune(0xfe00); r <= 0xfe0f; r++ {
+		if got := StripDefaultIgnorable().String("a" + string(r)); got != "a" {
+			t.Errorf("%U: got %+q; want %q", r, got, "a")
+		}
+	}
+}
+
+func TestInvisibleStripper(t *testing.T) {
+	strip := NewTransformer(NewInvisibleStripper())
+	testCases := []transformTest{{
+		desc:    "assorted",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u00adb\u200cc\u200dd\u2060e\u200e\u200ff",
+		out:     "abcdef",
+		outFull: "abcdef",
+		t:       strip,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "bidi controls",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u202aa\u202b\u202c\u202d\u202eb\u2066\u2067\u2068\u2069c",
+		out:     "abc",
+		outFull: "abc",
+		t:       strip,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "other default ignorables",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u2764\ufe0f \u200b\u2061\ufeff\u2065",
+		out:     "\u2764\ufe0f \u200b\u2061\ufeff\u2065",
+		outFull: "\u2764\ufe0f \u200b\u2061\ufeff\u2065",
+		t:       strip,
+	}, {
+		desc:    "additional",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u200bb\ufeffc\u00add!",
+		out:     "abcd",
+		outFull: "abcd",
+		t:       NewTransformer(NewInvisibleStripper('\ufeff', '!', '\u200b', '!')),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	for _, r := range invisible {
+		if !isDefaultIgnorable(r) {
+			t.Errorf("%U is not a default ignorable code point", r)
+		}
+	}
+}
+
+func TestDefaultIgnorableTable(t *testing.T) {
+	// Other_Default_Ignorable_Code_Point and the format characters, except for
+	// the prepended concatenation marks, are subsets of the table.
+	for _, table := range []*unicode.RangeTable{
+		unicode.Other_Default_Ignorable_Code_Point,
+		unicode.Variation_Selector,
+	} {
+		for r := rune(0); r <= unicode.MaxRune; r++ {
+			if unicode.Is(table, r) && !isDefaultIgnorable(r) {
+				t.Errorf("%U: not in table", r)
+			}
+		}
+	}
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if isDefaultIgnorable(r) && unicode.IsGraphic(r) && !unicode.Is(unicode.Mn, r) &&
+			r != 0x3164 && r != 0xffa0 && r != 0x115f && r != 0x1160 {
+			t.Errorf("%U: is visible", r)
+		}
+	}
+}
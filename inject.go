@@ -0,0 +1,47 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode"
+
+// InjectCombining returns a Transformer that appends combining after each base
+// letter, that is, each letter that is not a nonspacing mark. It is mostly
+// useful for generating test input for code that handles combining characters.
+func InjectCombining(combining rune) Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		r, _ := s.ReadRune()
+		if s.WriteRune(r) && unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r) {
+			s.WriteRune(combining)
+		}
+	})
+}
+
+// InjectBetween returns a Transformer that inserts inject between each pair of
+// consecutive runes prev and next for which pred(prev, next) is true. This can
+// be used, for instance, to insert spaces between Han and Latin characters.
+func InjectBetween(pred func(prev, next rune) bool, inject string) Transformer {
+	return NewTransformer(&injectBetween{pred: pred, inject: inject})
+}
+
+type injectBetween struct {
+	pred   func(prev, next rune) bool
+	inject string
+
+	// prev is the last rune written, if hasPrev is set.
+	prev    rune
+	hasPrev bool
+}
+
+func (b *injectBetween) Reset() { b.hasPrev = false }
+
+func (b *injectBetween) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if b.hasPrev && b.pred(b.prev, r) && !s.WriteString(b.inject) {
+		return
+	}
+	if s.WriteRune(r) {
+		b.prev, b.hasPrev = r, true
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"fmt"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestInjectCombining(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "1ab",
+		out:     "1a\u0301b\u0301",
+		outFull: "1a\u0301b\u0301",
+		t:       InjectCombining('\u0301'),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "non-letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "1 + 2 = 3!",
+		out:     "1 + 2 = 3!",
+		outFull: "1 + 2 = 3!",
+		t:       InjectCombining('\u0301'),
+	}, {
+		desc:    "non-Latin and existing marks",
+		szDst:   large,
+		atEOF:   true,
+		in:      "(д\u0308, ω)",
+		out:     "(д\u0300\u0308, ω\u0300)",
+		outFull: "(д\u0300\u0308, ω\u0300)",
+		t:       InjectCombining('\u0300'),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "short destination",
+		szDst:   4,
+		atEOF:   true,
+		in:      "1ab",
+		out:     "1a\u0301",
+		outFull: "1a\u0301b\u0301",
+		err:     transform.ErrShortDst,
+		t:       InjectCombining('\u0301'),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestInjectCombiningNormalization(t *testing.T) {
+	const in = "The quick brown fox, 1 2 3."
+	out := InjectCombining('\u0301').String(in)
+	if !utf8.ValidString(out) {
+		t.Fatalf("invalid UTF-8: %q", out)
+	}
+	composed := norm.NFC.String(out)
+	if composed == out {
+		t.Errorf("NFC did not compose any of %q", out)
+	}
+	if got := norm.NFD.String(composed); got != out {
+		t.Errorf("NFD: got %q; want %q", got, out)
+	}
+	stripped := transform.RemoveFunc(func(r rune) bool { return r == '\u0301' })
+	if got, _, _ := transform.String(stripped, out); got != in {
+		t.Errorf("stripped: got %q; want %q", got, in)
+	}
+}
+
+func isHanLatin(prev, next rune) bool {
+	han := func(r rune) bool { return unicode.Is(unicode.Han, r) }
+	latin := func(r rune) bool { return unicode.Is(unicode.Latin, r) }
+	return han(prev) && latin(next) || latin(prev) && han(next)
+}
+
+func TestInjectBetween(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "camel case",
+		szDst:   large,
+		atEOF:   true,
+		in:      "CamelCaseWord",
+		out:     "Camel Case Word",
+		outFull: "Camel Case Word",
+		t:       InjectBetween(func(p, n rune) bool { return unicode.IsLower(p) && unicode.IsUpper(n) }, " "),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("Camel"),
+	}, {
+		desc:    "no injection at start",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "a-b-c",
+		outFull: "a-b-c",
+		t:       InjectBetween(func(p, n rune) bool { return true }, "-"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "multi-byte inject",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Go言語abc漢",
+		out:     "Go\u200b言語\u200babc\u200b漢",
+		outFull: "Go\u200b言語\u200babc\u200b漢",
+		t:       InjectBetween(isHanLatin, "\u200b"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("Go"),
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "aB",
+		out:     "a",
+		outFull: "a\u2009B",
+		err:     transform.ErrShortDst,
+		t:       InjectBetween(func(p, n rune) bool { return unicode.IsUpper(n) }, "\u2009"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "no matches",
+		szDst:   large,
+		atEOF:   true,
+		in:      "hello world",
+		out:     "hello world",
+		outFull: "hello world",
+		t:       InjectBetween(isHanLatin, "\u200b"),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestInjectBetweenPairs(t *testing.T) {
+	var got []string
+	tr := InjectBetween(func(p, n rune) bool {
+		got = append(got, string([]rune{p, n}))
+		return false
+	}, "")
+	dst := make([]byte, large)
+	tr.Transform(dst, []byte("abé世"), true)
+	want := []string{"ab", "bé", "é世"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	// Reset clears the previous rune.
+	got = got[:0]
+	tr.Reset()
+	tr.Transform(dst, []byte("c"), true)
+	if len(got) != 0 {
+		t.Errorf("after Reset: got %q; want none", got)
+	}
+}
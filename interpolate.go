@@ -0,0 +1,125 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"bytes"
+	"errors"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// maxVariableName is the maximum length in bytes of the text following open
+// in a placeholder, which limits the size of the buffered input.
+const maxVariableName = 256
+
+// ErrUnknownVariable is returned by the Transformer returned by Interpolate for
+// a placeholder naming a variable that is not defined.
+var ErrUnknownVariable = errors.New("textutil: unknown variable")
+
+// Interpolate returns a Transformer that replaces each placeholder consisting
+// of open, a variable name, and close, such as "${name}" for open "${" and
+// close "}", with the value of the variable in vars. It returns
+// ErrUnknownVariable for a placeholder naming a variable that is not in vars.
+// A placeholder that is not closed by the end of input is written verbatim.
+// Values are not themselves interpolated. It panics if open or close is empty.
+//
+// Names may contain any text, including open, and are buffered until close is
+// found. Text following open is written verbatim as well if close is not found
+// within 256 bytes.
+func Interpolate(vars map[string]string, open, close string) Transformer {
+	if open == "" || close == "" {
+		panic("textutil: empty placeholder delimiter")
+	}
+	return NewTransformer(&interpolator{vars: vars, open: []byte(open), close: []byte(close)})
+}
+
+type interpolator struct {
+	vars        map[string]string
+	open, close []byte
+
+	// buf holds the input read so far that may be a prefix of open, or, if
+	// inName is set, the input following open.
+	buf    []byte
+	inName bool
+}
+
+func (t *interpolator) Reset() {
+	t.buf = t.buf[:0]
+	t.inName = false
+}
+
+func (t *interpolator) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	var b [utf8.UTFMax]byte
+	rb := b[:utf8.EncodeRune(b[:], r)]
+	if !t.inName && len(t.buf) == 0 && !bytes.HasPrefix(t.open, rb) {
+		s.WriteBytes(rb)
+		return
+	}
+	// Appending leaves t.buf unchanged if the results are discarded.
+	buf := append(t.buf, rb...)
+
+	if t.inName {
+		if bytes.HasSuffix(buf, t.close) {
+			v, ok := t.vars[string(buf[:len(buf)-len(t.close)])]
+			if !ok {
+				s.SetError(ErrUnknownVariable)
+				return
+			}
+			if s.WriteString(v) {
+				t.Reset()
+			}
+			return
+		}
+		if t.atEOF(s) {
+			s.WriteBytes(t.open)
+			s.WriteBytes(buf)
+			return
+		}
+		if len(buf) > maxVariableName {
+			if s.WriteBytes(t.open) && s.WriteBytes(buf) {
+				t.Reset()
+			}
+			return
+		}
+		t.buf = buf
+		return
+	}
+
+	// Write the input that can no longer be part of open. Only a few runes can
+	// be unread, so the remainder of the input is kept in buf.
+	i := 0
+	for !bytes.HasPrefix(t.open, buf[i:]) {
+		_, size := utf8.DecodeRune(buf[i:])
+		i += size
+	}
+	inName := bytes.Equal(buf[i:], t.open)
+	if i < len(buf) && t.atEOF(s) {
+		s.WriteBytes(buf)
+		return
+	}
+	if i < len(buf) && s.IsSpan() {
+		// Holding back input ends a span.
+		s.SetError(transform.ErrEndOfSpan)
+		return
+	}
+	if !s.WriteBytes(buf[:i]) {
+		return
+	}
+	if t.buf, t.inName = buf[i:], inName; inName {
+		t.buf = t.buf[:0]
+	}
+}
+
+// atEOF reports whether s has no more input. If more input may follow,
+// ReadRune sets ErrShortSrc, in which case the results of the current call to
+// Rewrite will be discarded.
+func (t *interpolator) atEOF(s State) bool {
+	_, size := s.ReadRune()
+	s.UnreadRune()
+	return size == 0
+}
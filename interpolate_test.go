@@ -0,0 +1,179 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestInterpolate(t *testing.T) {
+	vars := map[string]string{
+		"name":  "Gopher",
+		"greet": "Hello",
+		"empty": "",
+		"nest":  "${name}",
+	}
+	testCases := []transformTest{{
+		desc:    "single variable",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hi ${name}!",
+		out:     "Hi Gopher!",
+		outFull: "Hi Gopher!",
+		t:       Interpolate(vars, "${", "}"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "multiple variables",
+		szDst:   large,
+		atEOF:   true,
+		in:      "${greet}, ${name}${empty}${name}",
+		out:     "Hello, GopherGopher",
+		outFull: "Hello, GopherGopher",
+		t:       Interpolate(vars, "${", "}"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "values are not interpolated",
+		szDst:   large,
+		atEOF:   true,
+		in:      "<${nest}>",
+		out:     "<${name}>",
+		outFull: "<${name}>",
+		t:       Interpolate(vars, "${", "}"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "partial delimiters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "{name} $5 $${name} $",
+		out:     "{name} $5 $Gopher $",
+		outFull: "{name} $5 $Gopher $",
+		t:       Interpolate(vars, "${", "}"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   7,
+	}, {
+		desc:    "unclosed placeholder",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a ${name",
+		out:     "a ${name",
+		outFull: "a ${name",
+		t:       Interpolate(vars, "${", "}"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "open at end",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a ${",
+		out:     "a ${",
+		outFull: "a ${",
+		t:       Interpolate(vars, "${", "}"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "multi-rune delimiters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "«{name}» «name» {«{greet}»}",
+		out:     "Gopher «name» {Hello}",
+		outFull: "Gopher «name» {Hello}",
+		t:       Interpolate(vars, "«{", "}»"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "close at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a ${name}",
+		out:     "a ",
+		outFull: "a ${name}",
+		err:     transform.ErrShortSrc,
+		t:       Interpolate(vars, "${", "}}"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "unknown variable",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a ${name} ${nome} b",
+		out:     "a Gopher ",
+		outFull: "a Gopher ",
+		err:     ErrUnknownVariable,
+		t:       Interpolate(vars, "${", "}"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "empty vars",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a ${}",
+		out:     "a ",
+		outFull: "a ",
+		err:     ErrUnknownVariable,
+		t:       Interpolate(nil, "${", "}"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "short destination",
+		szDst:   5,
+		atEOF:   true,
+		in:      "a ${name}",
+		out:     "a ",
+		outFull: "a Gopher",
+		err:     transform.ErrShortDst,
+		t:       Interpolate(vars, "${", "}"),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.t.Reset()
+		tt.check(t, i)
+	}
+}
+
+func TestInterpolateBoundary(t *testing.T) {
+	// Placeholders spanning the internal buffers of transform.String.
+	vars := map[string]string{"x": "X"}
+	for n := 120; n < 130; n++ {
+		prefix := strings.Repeat("-", n)
+		if got, want := Interpolate(vars, "${", "}").String(prefix+"${x}${x}"), prefix+"XX"; got != want {
+			t.Errorf("%d: got %q; want %q", n, got, want)
+		}
+		if got, want := Interpolate(vars, "{{", "}}").String(prefix+"{{x}}}"), prefix+"X}"; got != want {
+			t.Errorf("%d: got %q; want %q", n, got, want)
+		}
+	}
+}
+
+func TestInterpolateSpan(t *testing.T) {
+	vars := map[string]string{"name": "Bob", "a": "AAA"}
+	for _, in := range []string{"{{name}} is {{a}}", "x {{a}}", "{x}", "{{"} {
+		tr := Interpolate(vars, "{{", "}}")
+		want := tr.String(in)
+		if got, err := spanTransform(tr, in); got != want || err != nil {
+			t.Errorf("%q: got %q, %v; want %q, <nil>", in, got, err, want)
+		}
+	}
+}
+
+func TestInterpolateLongName(t *testing.T) {
+	// Text following open that is not closed within the maximum name length
+	// is written verbatim, even to a small destination.
+	long := "a ${" + strings.Repeat("x", 10000)
+	testCases := []struct{ in, out string }{
+		{long, long},
+		{long + "} ${name}", long + "} Gopher"},
+	}
+	for _, tc := range testCases {
+		tr := Interpolate(map[string]string{"name": "Gopher"}, "${", "}")
+		b, err := ioutil.ReadAll(transform.NewReader(strings.NewReader(tc.in), tr))
+		if got := string(b); got != tc.out || err != nil {
+			t.Errorf("%.10q: got %.10q, %v; want %.10q, <nil>", tc.in, got, err, tc.out)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// StripMIRCColors returns a Transformer that removes mIRC color and formatting
+// codes as used in IRC messages. A color code consists of the byte 0x03,
+// optionally followed by a foreground color of one or two digits, which in
+// turn is optionally followed by a comma and a background color of one or two
+// digits. The formatting codes are 0x02 (bold), 0x0F (reset), 0x11
+// (monospace), 0x15 and 0x1F (underline), 0x16 (reverse), 0x1D (italic) and
+// 0x1E (strikethrough).
+func StripMIRCColors() Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		switch r, _ := s.ReadRune(); r {
+		case 0x02, 0x0f, 0x11, 0x15, 0x16, 0x1d, 0x1e, 0x1f:
+		case 0x03:
+			stripColorCode(s)
+		default:
+			s.WriteRune(r)
+		}
+	})
+}
+
+// stripColorCode consumes the optional color arguments following a color code.
+func stripColorCode(s State) {
+	if r, _ := s.ReadRune(); !isDigit(r) {
+		s.UnreadRune()
+		return
+	}
+	r, _ := s.ReadRune()
+	if isDigit(r) {
+		r, _ = s.ReadRune()
+	}
+	if r != ',' {
+		s.UnreadRune()
+		return
+	}
+	if r, _ := s.ReadRune(); !isDigit(r) {
+		// The comma is not part of the color code.
+		s.UnreadRune()
+		s.WriteRune(',')
+		return
+	}
+	if r, _ := s.ReadRune(); !isDigit(r) {
+		s.UnreadRune()
+	}
+}
+
+func isDigit(r rune) bool { return '0' <= r && r <= '9' }
@@ -0,0 +1,83 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestStripMIRCColors(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "color without arguments",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x03b\x03",
+		out:     "ab",
+		outFull: "ab",
+		t:       StripMIRCColors(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "foreground",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\x034red\x0304red\x03123",
+		out:     "redred3",
+		outFull: "redred3",
+		t:       StripMIRCColors(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "foreground and background",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\x034,12a\x0304,1b\x031,023c",
+		out:     "ab3c",
+		outFull: "ab3c",
+		t:       StripMIRCColors(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "comma without background",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\x034,a\x03,b\x034,",
+		out:     ",a,b,",
+		outFull: ",a,b,",
+		t:       StripMIRCColors(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "formatting",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\x02bold\x02 \x1ditalic\x0f \x15u\x1fu\x16r\x1es\x11m",
+		out:     "bold italic uursm",
+		outFull: "bold italic uursm",
+		t:       StripMIRCColors(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "color at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a\x034,1",
+		out:     "a",
+		outFull: "a",
+		err:     transform.ErrShortSrc,
+		t:       StripMIRCColors(),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "plain text",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, wørld! 4,5",
+		out:     "Hello, wørld! 4,5",
+		outFull: "Hello, wørld! 4,5",
+		t:       StripMIRCColors(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidEscape is reported by Rewriters that interpret escape sequences,
+// such as NewJSONStringUnescaper, for an invalid escape sequence.
+var ErrInvalidEscape = errors.New("textutil: invalid escape sequence")
+
+// NewJSONStringEscaper returns a Rewriter that escapes its input for use in a
+// JSON string as defined by RFC 8259, without adding the enclosing quotation
+// marks. It uses the escapes \", \\, \b, \f, \n, \r and \t for the respective
+// characters and \u00XX for any other control character below U+0020. All
+// other runes, including the solidus, are written as is. Invalid UTF-8 is
+// replaced with utf8.RuneError, as JSON text must be valid UTF-8.
+func NewJSONStringEscaper() Rewriter {
+	return rewriterFunc(func(s State) {
+		r, _ := s.ReadRune()
+		switch esc := jsonEscapes[r&0x7f]; {
+		case r >= 0x80:
+			s.WriteRune(r)
+		case esc != 0:
+			s.WriteBytes([]byte{'\\', esc})
+		case r < ' ':
+			s.WriteBytes([]byte{'\\', 'u', '0', '0', hexDigits[r>>4], hexDigits[r&0xf]})
+		default:
+			s.WriteRune(r)
+		}
+	})
+}
+
+// jsonEscapes maps ASCII characters to the letter of their JSON escape
+// sequence.
+var jsonEscapes = [0x80]byte{
+	'\b': 'b',
+	'\f': 'f',
+	'\n': 'n',
+	'\r': 'r',
+	'\t': 't',
+	'\\': '\\',
+	'"':  '"',
+}
+
+// jsonUnescapes is the inverse of jsonEscapes, extended with the escape for the
+// solidus.
+var jsonUnescapes = func() (m [0x80]byte) {
+	for c, esc := range jsonEscapes {
+		if esc != 0 {
+			m[esc] = byte(c)
+		}
+	}
+	m['/'] = '/'
+	return m
+}()
+
+// NewJSONStringUnescaper returns a Rewriter that interprets the escape
+// sequences of a JSON string as defined by RFC 8259, the inverse of
+// NewJSONStringEscaper. A \uXXXX escape denoting a high surrogate is combined
+// with an immediately following escape denoting a low surrogate. Unpaired
+// surrogates are written as utf8.RuneError. A backslash that does not start a
+// valid escape sequence results in ErrInvalidEscape. All other input is
+// written as is.
+func NewJSONStringUnescaper() Rewriter {
+	return rewriterFunc(unescapeJSON)
+}
+
+func unescapeJSON(s State) {
+	if r, _ := s.ReadRune(); r != '\\' {
+		s.WriteRune(r)
+		return
+	}
+	// If ReadRune reaches the end of a buffer, it sets ErrShortSrc, which
+	// takes precedence over ErrInvalidEscape.
+	r, _ := s.ReadRune()
+	if r != 'u' {
+		if r < 0x80 && jsonUnescapes[r] != 0 {
+			s.WriteBytes([]byte{jsonUnescapes[r]})
+		} else {
+			s.SetError(ErrInvalidEscape)
+		}
+		return
+	}
+	r, ok := readJSONHex(s)
+	if !ok {
+		s.SetError(ErrInvalidEscape)
+		return
+	}
+	for 0xd800 <= r && r <= 0xdbff {
+		// Look for an escape denoting the low surrogate.
+		if p, size := s.PeekRune(); p != '\\' {
+			if size == 0 && !s.IsAtEOF() {
+				return // PeekRune set ErrShortSrc.
+			}
+			break
+		}
+		s.ReadRune()
+		if p, size := s.PeekRune(); p != 'u' {
+			if size == 0 && !s.IsAtEOF() {
+				return
+			}
+			s.UnreadRune()
+			break
+		}
+		s.ReadRune()
+		low, ok := readJSONHex(s)
+		if !ok {
+			s.SetError(ErrInvalidEscape)
+			return
+		}
+		if x := utf16.DecodeRune(r, low); x != utf8.RuneError {
+			r = x
+			break
+		}
+		// The high surrogate is unpaired, but low may start a new pair.
+		s.WriteRune(utf8.RuneError)
+		r = low
+	}
+	// WriteRune writes surrogates as utf8.RuneError.
+	s.WriteRune(r)
+}
+
+// readJSONHex reads the four hexadecimal digits of a \uXXXX escape.
+func readJSONHex(s State) (r rune, ok bool) {
+	for i := 0; i < 4; i++ {
+		h, _ := s.ReadRune()
+		d, ok := unhex(h)
+		if !ok {
+			return 0, false
+		}
+		r = r<<4 | rune(d)
+	}
+	return r, true
+}
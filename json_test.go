@@ -0,0 +1,200 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestJSONStringEscaper(t *testing.T) {
+	escape := NewTransformer(NewJSONStringEscaper())
+
+	testCases := []transformTest{{
+		desc:    "escape simple",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\"b\\c\b\f\n\r\t/",
+		out:     `a\"b\\c\b\f\n\r\t/`,
+		outFull: `a\"b\\c\b\f\n\r\t/`,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "escape other control characters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x00\x01\x0b\x1b\x1f\x7f",
+		out:     `a\u0000\u0001\u000b\u001b\u001f` + "\x7f",
+		outFull: `a\u0000\u0001\u000b\u001b\u001f` + "\x7f",
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unescaped",
+		szDst:   large,
+		atEOF:   true,
+		in:      " !#$%&'()*+,-./09:;<=>?@AZ[]^_`az{|}~\u00e9\u2028\U0001f600",
+		out:     " !#$%&'()*+,-./09:;<=>?@AZ[]^_`az{|}~\u00e9\u2028\U0001f600",
+		outFull: " !#$%&'()*+,-./09:;<=>?@AZ[]^_`az{|}~\u00e9\u2028\U0001f600",
+		t:       escape,
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xed\xa0\x80b",
+		out:     "a\ufffd\ufffd\ufffdb",
+		outFull: "a\ufffd\ufffd\ufffdb",
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "short destination",
+		szDst:   6,
+		atEOF:   true,
+		in:      "ab\x01",
+		out:     "ab",
+		outFull: `ab\u0001`,
+		err:     transform.ErrShortDst,
+		t:       escape,
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	// The output must be decoded to the input by a JSON decoder.
+	var all []rune
+	for r := rune(0); r < 0x80; r++ {
+		all = append(all, r)
+	}
+	all = append(all, '\u00e9', '\u2028', '\u2029', '\ufeff', '\U0001f600')
+	in := string(all)
+	var got string
+	if err := json.Unmarshal([]byte(`"`+escape.String(in)+`"`), &got); err != nil || got != in {
+		t.Errorf("decoding: got %+q, %v; want %+q, <nil>", got, err, in)
+	}
+}
+
+func TestJSONStringUnescaper(t *testing.T) {
+	unescape := NewTransformer(NewJSONStringUnescaper())
+
+	testCases := []transformTest{{
+		desc:    "unescape simple",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a\"b\\c\/\b\f\n\r\t`,
+		out:     "a\"b\\c/\b\f\n\r\t",
+		outFull: "a\"b\\c/\b\f\n\r\t",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "unescape hex",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a\u0000\u001B\u00e9\u2208\uFEFF`,
+		out:     "a\x00\x1b\u00e9\u2208\ufeff",
+		outFull: "a\x00\x1b\u00e9\u2208\ufeff",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "surrogate pair",
+		szDst:   large,
+		atEOF:   true,
+		in:      `\ud83d\ude00\uD834\uDD1E`,
+		out:     "\U0001f600\U0001d11e",
+		outFull: "\U0001f600\U0001d11e",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unpaired surrogates",
+		szDst:   large,
+		atEOF:   true,
+		in:      `\ud83d\ud83d\ude00\ude00-\ud83dx\ud83d\n\ud83d\u0041\ud83d`,
+		out:     "\ufffd\U0001f600\ufffd-\ufffdx\ufffd\n\ufffdA\ufffd",
+		outFull: "\ufffd\U0001f600\ufffd-\ufffdx\ufffd\n\ufffdA\ufffd",
+		t:       unescape,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unescaped input",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\"b/\u00e9\t",
+		out:     "a\"b/\u00e9\t",
+		outFull: "a\"b/\u00e9\t",
+		t:       unescape,
+	}, {
+		desc:    "invalid escape",
+		szDst:   large,
+		atEOF:   true,
+		in:      `ab\qc`,
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: ErrInvalidEscape,
+	}, {
+		desc:    "invalid hex escape",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a\u12G4`,
+		out:     "a",
+		outFull: "a",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: ErrInvalidEscape,
+	}, {
+		desc:    "truncated escape at end of input",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a\u12`,
+		out:     "a",
+		outFull: "a",
+		err:     ErrInvalidEscape,
+		t:       unescape,
+		errSpan: ErrInvalidEscape,
+	}, {
+		desc:    "escape at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      `a\u12`,
+		out:     "a",
+		outFull: "a",
+		err:     transform.ErrShortSrc,
+		t:       unescape,
+		errSpan: transform.ErrShortSrc,
+	}, {
+		desc:    "high surrogate at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      `a\ud83d`,
+		out:     "a",
+		outFull: "a\ufffd",
+		err:     transform.ErrShortSrc,
+		t:       unescape,
+		errSpan: transform.ErrShortSrc,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	// Surrogate pairs split across calls to Transform.
+	for _, sz := range [][2]int{{1, 4}, {3, 4}, {7, 8}, {11, 4}} {
+		got, err := transformChunks(unescape, `a\ud83d\ude00\ud83d-`, sz[0], sz[1])
+		if want := "a\U0001f600\ufffd-"; got != want || err != nil {
+			t.Errorf("%v: got %+q, %v; want %+q, <nil>", sz, got, err, want)
+		}
+	}
+
+	roundTrip(t, NewTransformer(NewJSONStringEscaper()), unescape, []string{
+		"",
+		"abc",
+		"a\"b\\c/\b\f\n\r\t\x00\x1f\x7f",
+		"H\u00e9llo, w\u00f8rld! \U0001f600\u2028\u2029\ufeff",
+		`A\"`,
+	})
+}
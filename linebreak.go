@@ -0,0 +1,45 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// NormalizeLineBreaks returns a Transformer that converts each line break to
+// target. Recognized line breaks are LF, CR, CRLF, NEL (U+0085), FF (U+000C),
+// LS (U+2028) and PS (U+2029). A CRLF pair is converted to a single target.
+func NormalizeLineBreaks(target rune) Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		switch r, _ := s.ReadRune(); r {
+		case '\r':
+			// Treat CRLF as a single line break.
+			skipLF(s)
+			s.WriteRune(target)
+		case '\n', '\f', '\u0085', '\u2028', '\u2029':
+			s.WriteRune(target)
+		default:
+			s.WriteRune(r)
+		}
+	})
+}
+
+// NewLineEndingNormalizer returns a Rewriter that converts each CRLF pair and
+// each lone CR to LF. Other line breaks are left unchanged.
+func NewLineEndingNormalizer() Rewriter {
+	return rewriterFunc(func(s State) {
+		r, _ := s.ReadRune()
+		if r != '\r' {
+			s.WriteRune(r)
+			return
+		}
+		skipLF(s)
+		s.WriteRune('\n')
+	})
+}
+
+// skipLF reads the LF following a CR, if any. A CR at the end of a non-final
+// buffer results in ErrShortSrc, so that the LF is seen on the next call.
+func skipLF(s State) {
+	if r, _ := s.ReadRune(); r != '\n' {
+		s.UnreadRune()
+	}
+}
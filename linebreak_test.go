@@ -0,0 +1,167 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestNormalizeLineBreaks(t *testing.T) {
+	const all = "a\nb\rc\r\nd\u0085e\ff\u2028g\u2029h"
+
+	testCases := []transformTest{{
+		desc:    "all forms to LF",
+		szDst:   large,
+		atEOF:   true,
+		in:      all,
+		out:     "a\nb\nc\nd\ne\nf\ng\nh",
+		outFull: "a\nb\nc\nd\ne\nf\ng\nh",
+		t:       NormalizeLineBreaks('\n'),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "all forms to LS",
+		szDst:   large,
+		atEOF:   true,
+		in:      all,
+		out:     "a\u2028b\u2028c\u2028d\u2028e\u2028f\u2028g\u2028h",
+		outFull: "a\u2028b\u2028c\u2028d\u2028e\u2028f\u2028g\u2028h",
+		t:       NormalizeLineBreaks('\u2028'),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "CRLF to CR",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\r\nb\rc\n",
+		out:     "a\rb\rc\r",
+		outFull: "a\rb\rc\r",
+		t:       NormalizeLineBreaks('\r'),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "CRLF to space",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\r\n\r\nb",
+		out:     "a  b",
+		outFull: "a  b",
+		t:       NormalizeLineBreaks(' '),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "LF only",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\nb\n\nc\n",
+		out:     "a\nb\n\nc\n",
+		outFull: "a\nb\n\nc\n",
+		t:       NormalizeLineBreaks('\n'),
+	}, {
+		desc:    "CR at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a\r",
+		out:     "a",
+		outFull: "a\n",
+		err:     transform.ErrShortSrc,
+		t:       NormalizeLineBreaks('\n'),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "CR at end of input",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\r",
+		out:     "a\n",
+		outFull: "a\n",
+		t:       NormalizeLineBreaks('\n'),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestLineEndingNormalizer(t *testing.T) {
+	lf := func() Transformer { return NewTransformer(NewLineEndingNormalizer()) }
+	testCases := []transformTest{{
+		desc:    "CRLF and CR",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\nb\r\nc\rd\r\r\ne",
+		out:     "a\nb\nc\nd\n\ne",
+		outFull: "a\nb\nc\nd\n\ne",
+		t:       lf(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "other line breaks",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u0085b\fc\u2028d",
+		out:     "a\u0085b\fc\u2028d",
+		outFull: "a\u0085b\fc\u2028d",
+		t:       lf(),
+	}, {
+		desc:    "LF only",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\nb\n\nc\n",
+		out:     "a\nb\n\nc\n",
+		outFull: "a\nb\n\nc\n",
+		t:       lf(),
+	}, {
+		desc:    "CRLF split across buffers",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a\r",
+		out:     "a",
+		outFull: "a\n",
+		err:     transform.ErrShortSrc,
+		t:       lf(),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "CR at end of input",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\r",
+		out:     "a\n",
+		outFull: "a\n",
+		t:       lf(),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	for _, sz := range [][2]int{{1, 1}, {2, 2}, {3, 1}} {
+		got, err := transformChunks(lf(), "a\r\nb\r\r\n\r", sz[0], sz[1])
+		if want := "a\nb\n\n\n"; got != want || err != nil {
+			t.Errorf("%v: got %q, %v; want %q, <nil>", sz, got, err, want)
+		}
+	}
+}
+
+func TestNormalizeLineBreaksBoundary(t *testing.T) {
+	// transform.String uses an initial buffer size of 128 bytes.
+	for n := 120; n < 130; n++ {
+		in := strings.Repeat("x", n) + "\r\n\r\n"
+		want := strings.Repeat("x", n) + "\n\n"
+		if got := NormalizeLineBreaks('\n').String(in); got != want {
+			t.Errorf("%d: got %q; want %q", n, got, want)
+		}
+	}
+}
+
+func BenchmarkNormalizeLineBreaksSpan(b *testing.B) {
+	src := []byte(strings.Repeat("The quick brown fox.\n", 100))
+	t := NormalizeLineBreaks('\n')
+	for i := 0; i < b.N; i++ {
+		if n, err := t.Span(src, true); n != len(src) || err != nil {
+			b.Fatalf("got %d, %v; want %d, nil", n, err, len(src))
+		}
+	}
+}
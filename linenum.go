@@ -0,0 +1,41 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// InsertLineNumbers returns a Transformer that prefixes each line with
+// format(n), where n is the line number starting at 1. For example, using
+//
+//	func(n int) string { return fmt.Sprintf("%4d: ", n) }
+//
+// produces output similar to that of many editors. Lines are terminated by
+// '\n', so a CRLF sequence ends a single line. No prefix is written after the
+// final line break of the input. The format function may be called more than
+// once for the same line.
+func InsertLineNumbers(format func(lineNum int) string) Transformer {
+	return NewTransformer(&lineNumbers{format: format, lineNum: 1, atLineStart: true})
+}
+
+type lineNumbers struct {
+	format      func(lineNum int) string
+	lineNum     int
+	atLineStart bool
+}
+
+func (l *lineNumbers) Reset() {
+	l.lineNum, l.atLineStart = 1, true
+}
+
+func (l *lineNumbers) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if l.atLineStart && !s.WriteString(l.format(l.lineNum)) {
+		return
+	}
+	if s.WriteRune(r) {
+		l.atLineStart = r == '\n'
+		if l.atLineStart {
+			l.lineNum++
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestInsertLineNumbers(t *testing.T) {
+	padded := func(n int) string { return fmt.Sprintf("%4d: ", n) }
+	plain := func(n int) string { return strconv.Itoa(n) + " " }
+	testCases := []transformTest{{
+		desc:    "lines",
+		szDst:   large,
+		atEOF:   true,
+		in:      "one\ntwo\nthree\n",
+		out:     "   1: one\n   2: two\n   3: three\n",
+		outFull: "   1: one\n   2: two\n   3: three\n",
+		t:       InsertLineNumbers(padded),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "CRLF and empty lines",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\r\n\r\n\nb\rc",
+		out:     "1 a\r\n2 \r\n3 \n4 b\rc",
+		outFull: "1 a\r\n2 \r\n3 \n4 b\rc",
+		t:       InsertLineNumbers(plain),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no trailing newline",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x\ny",
+		out:     "1 x\n2 y",
+		outFull: "1 x\n2 y",
+		t:       InsertLineNumbers(plain),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "custom format",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x\ny",
+		out:     "[1] x\n[2] y",
+		outFull: "[1] x\n[2] y",
+		t:       InsertLineNumbers(func(n int) string { return fmt.Sprintf("[%d] ", n) }),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   6,
+		atEOF:   true,
+		in:      "ab\ncd",
+		out:     "1 ab\n",
+		outFull: "1 ab\n2 cd",
+		err:     transform.ErrShortDst,
+		t:       InsertLineNumbers(plain),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "empty input",
+		szDst:   large,
+		atEOF:   true,
+		in:      "",
+		out:     "",
+		outFull: "",
+		t:       InsertLineNumbers(plain),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestInsertLineNumbersReset(t *testing.T) {
+	tr := InsertLineNumbers(func(n int) string { return strconv.Itoa(n) + ":" })
+	for i := 0; i < 2; i++ {
+		if got, want := tr.String("a\nb\n"), "1:a\n2:b\n"; got != want {
+			t.Errorf("%d: got %q; want %q", i, got, want)
+		}
+	}
+}
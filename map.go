@@ -0,0 +1,101 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// MapRune returns a Transformer that replaces each rune r in the input with
+// mapping(r). Invalid UTF-8 is passed to mapping as utf8.RuneError.
+func MapRune(mapping func(rune) rune) Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		r, _ := s.ReadRune()
+		s.WriteRune(mapping(r))
+	})
+}
+
+// NewMapRewriter returns a Rewriter that replaces each rune in the input that
+// is a key in table with its value. All other runes are left unchanged.
+// Invalid UTF-8 is looked up as utf8.RuneError and is written as such if it is
+// not in table. The table must not be modified while the Rewriter is in use.
+func NewMapRewriter(table map[rune]rune) Rewriter {
+	return mapRewriter(table)
+}
+
+type mapRewriter map[rune]rune
+
+func (m mapRewriter) Reset()          {}
+func (m mapRewriter) Clone() Rewriter { return m }
+
+func (m mapRewriter) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if x, ok := m[r]; ok {
+		r = x
+	}
+	s.WriteRune(r)
+}
+
+// NewStringMapRewriter returns a Rewriter that replaces each rune in the input
+// that is a key in table with its value, which may be of any length. An empty
+// value removes the rune. All other runes are left unchanged. Invalid UTF-8 is
+// looked up as utf8.RuneError. The table must not be modified while the
+// Rewriter is in use.
+func NewStringMapRewriter(table map[rune]string) Rewriter {
+	return stringMapRewriter(table)
+}
+
+type stringMapRewriter map[rune]string
+
+func (m stringMapRewriter) Reset()          {}
+func (m stringMapRewriter) Clone() Rewriter { return m }
+
+func (m stringMapRewriter) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if str, ok := m[r]; ok {
+		s.WriteString(str)
+	} else {
+		s.WriteRune(r)
+	}
+}
+
+// mapTable returns a mapping function for use with MapRune that maps the runes
+// in table and leaves all other runes unchanged.
+func mapTable(table map[rune]rune) func(rune) rune {
+	return func(r rune) rune {
+		if x, ok := table[r]; ok {
+			return x
+		}
+		return r
+	}
+}
+
+// invert returns the inverse of the given one-to-one mapping.
+func invert(table map[rune]rune) map[rune]rune {
+	m := make(map[rune]rune, len(table))
+	for k, v := range table {
+		m[v] = k
+	}
+	return m
+}
+
+// FlatMap returns a Transformer that replaces each rune r in the input for
+// which mapping returns true with the returned string. All other runes are left
+// unchanged. Invalid UTF-8 is passed to mapping as utf8.RuneError.
+func FlatMap(mapping func(r rune) (s string, ok bool)) Transformer {
+	return NewTransformerFromFunc(func(s State) {
+		r, _ := s.ReadRune()
+		if str, ok := mapping(r); ok {
+			s.WriteString(str)
+		} else {
+			s.WriteRune(r)
+		}
+	})
+}
+
+// lookup returns a mapping function for use with FlatMap that maps the runes
+// in table.
+func lookup(table map[rune]string) func(rune) (string, bool) {
+	return func(r rune) (string, bool) {
+		s, ok := table[r]
+		return s, ok
+	}
+}
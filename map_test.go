@@ -0,0 +1,234 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+func TestMapRune(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "upper",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, wørld!",
+		out:     "HELLO, WØRLD!",
+		outFull: "HELLO, WØRLD!",
+		t:       MapRune(unicode.ToUpper),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "identity",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, wørld!",
+		out:     "Hello, wørld!",
+		outFull: "Hello, wørld!",
+		t:       MapRune(func(r rune) rune { return r }),
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "aøb",
+		out:     "A",
+		outFull: "AØB",
+		err:     transform.ErrShortDst,
+		t:       MapRune(unicode.ToUpper),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestMapRewriter(t *testing.T) {
+	table := map[rune]rune{
+		'a': '\u00e4',
+		'o': '\u00f6',
+		'x': 'x',
+		'?': utf8.RuneError,
+	}
+	testCases := []transformTest{{
+		desc:    "mapped",
+		szDst:   large,
+		atEOF:   true,
+		in:      "xyzzy fox",
+		out:     "xyzzy f\u00f6x",
+		outFull: "xyzzy f\u00f6x",
+		t:       NewTransformer(NewMapRewriter(table)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "mapped to itself",
+		szDst:   large,
+		atEOF:   true,
+		in:      "xxx",
+		out:     "xxx",
+		outFull: "xxx",
+		t:       NewTransformer(NewMapRewriter(table)),
+	}, {
+		desc:    "mapped to RuneError",
+		szDst:   large,
+		atEOF:   true,
+		in:      "why?",
+		out:     "why\ufffd",
+		outFull: "why\ufffd",
+		t:       NewTransformer(NewMapRewriter(table)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "b\xffc",
+		out:     "b\ufffdc",
+		outFull: "b\ufffdc",
+		t:       NewTransformer(NewMapRewriter(table)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "bab",
+		out:     "b",
+		outFull: "b\u00e4b",
+		err:     transform.ErrShortDst,
+		t:       NewTransformer(NewMapRewriter(table)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "empty table",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "abc",
+		outFull: "abc",
+		t:       NewTransformer(NewMapRewriter(nil)),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestFlatMap(t *testing.T) {
+	double := FlatMap(func(r rune) (string, bool) {
+		if r == 'ø' || r == 'b' {
+			return string([]rune{r, r}), true
+		}
+		return "", r == '-'
+	})
+	testCases := []transformTest{{
+		desc:    "expand and remove",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abø-c",
+		out:     "abbøøc",
+		outFull: "abbøøc",
+		t:       double,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "expand at end",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ab",
+		out:     "abb",
+		outFull: "abb",
+		t:       double,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "short destination",
+		szDst:   4,
+		atEOF:   true,
+		in:      "abø",
+		out:     "abb",
+		outFull: "abbøø",
+		err:     transform.ErrShortDst,
+		t:       double,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "unmapped",
+		szDst:   large,
+		atEOF:   true,
+		in:      "acd",
+		out:     "acd",
+		outFull: "acd",
+		t:       double,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestStringMapRewriter(t *testing.T) {
+	entities := NewTransformer(NewStringMapRewriter(map[rune]string{
+		'<':      "&lt;",
+		'>':      "&gt;",
+		'&':      "&amp;",
+		'\u00a0': "&nbsp;",
+		'\u00df': "\u1e9e\u1e9e", // multi-byte value
+		'\u00ad': "",             // soft hyphen is removed
+	}))
+	testCases := []transformTest{{
+		desc:    "expand",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a<b>&c",
+		out:     "a&lt;b&gt;&amp;c",
+		outFull: "a&lt;b&gt;&amp;c",
+		t:       entities,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "multi-byte value",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Stra\u00dfe\u00a0",
+		out:     "Stra\u1e9e\u1e9ee&nbsp;",
+		outFull: "Stra\u1e9e\u1e9ee&nbsp;",
+		t:       entities,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "remove",
+		szDst:   large,
+		atEOF:   true,
+		in:      "hy\u00adphen\u00ad",
+		out:     "hyphen",
+		outFull: "hyphen",
+		t:       entities,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "remove all",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u00ad\u00ad",
+		out:     "",
+		outFull: "",
+		t:       entities,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   6,
+		atEOF:   true,
+		in:      "a<b>",
+		out:     "a&lt;b",
+		outFull: "a&lt;b&gt;",
+		err:     transform.ErrShortDst,
+		t:       entities,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unmapped",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, w\u00f8rld!",
+		out:     "Hello, w\u00f8rld!",
+		outFull: "Hello, w\u00f8rld!",
+		t:       entities,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
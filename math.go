@@ -0,0 +1,25 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// NormalizeMathOperators returns a Transformer that replaces common
+// mathematical operators with their ASCII equivalent as used in programming
+// languages such as Go, for instance − (MINUS SIGN) with - and ≤ with <=.
+func NormalizeMathOperators() Transformer {
+	return FlatMap(lookup(mathOperators))
+}
+
+var mathOperators = map[rune]string{
+	'−': "-",  // MINUS SIGN
+	'×': "*",  // MULTIPLICATION SIGN
+	'÷': "/",  // DIVISION SIGN
+	'∕': "/",  // DIVISION SLASH
+	'∗': "*",  // ASTERISK OPERATOR
+	'≠': "!=", // NOT EQUAL TO
+	'≤': "<=", // LESS-THAN OR EQUAL TO
+	'≥': ">=", // GREATER-THAN OR EQUAL TO
+	'≪': "<<", // MUCH LESS-THAN
+	'≫': ">>", // MUCH GREATER-THAN
+}
@@ -0,0 +1,74 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"go/scanner"
+	"go/token"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestNormalizeMathOperators(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "operators",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a − b × c ÷ d ∕ e ∗ f",
+		out:     "a - b * c / d / e * f",
+		outFull: "a - b * c / d / e * f",
+		t:       NormalizeMathOperators(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "multi-character operators",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x≠y, x≤y, x≥y, x≪y, x≫y",
+		out:     "x!=y, x<=y, x>=y, x<<y, x>>y",
+		outFull: "x!=y, x<=y, x>=y, x<<y, x>>y",
+		t:       NormalizeMathOperators(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "x≠y",
+		out:     "x",
+		outFull: "x!=y",
+		err:     transform.ErrShortDst,
+		t:       NormalizeMathOperators(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "other characters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a - b = c ± ∞ ∑ ≈",
+		out:     "a - b = c ± ∞ ∑ ≈",
+		outFull: "a - b = c ± ∞ ∑ ≈",
+		t:       NormalizeMathOperators(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestMathOperatorsAreGoOperators(t *testing.T) {
+	for r, op := range mathOperators {
+		var s scanner.Scanner
+		src := []byte(op)
+		s.Init(token.NewFileSet().AddFile("", -1, len(src)), src, nil, 0)
+		_, tok, _ := s.Scan()
+		if !tok.IsOperator() || tok.String() != op {
+			t.Errorf("%U: %q is not a Go operator", r, op)
+		}
+		if _, tok, _ = s.Scan(); tok != token.SEMICOLON && tok != token.EOF {
+			t.Errorf("%U: %q is not a single Go operator", r, op)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// ErrTooLong is returned by the Transformer returned by RequireMaxLength for
+// input that exceeds the maximum length.
+var ErrTooLong = errors.New("textutil: input too long")
+
+// RequireMaxLength returns a Transformer that copies up to maxRunes runes of
+// its input verbatim and returns ErrTooLong at the first rune past the limit.
+// Unlike TruncateAtGrapheme, it allows callers to reject oversized input
+// instead of silently shortening it. Each byte of invalid UTF-8 counts as a
+// single rune. Reset resets the count.
+func RequireMaxLength(maxRunes int) Transformer {
+	return Transformer{&maxLength{max: maxRunes}}
+}
+
+type maxLength struct {
+	max int
+
+	// n is the number of runes copied since the last Reset.
+	n int
+}
+
+func (m *maxLength) Reset() { m.n = 0 }
+
+func (m *maxLength) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	nSrc, count, err := m.span(src, atEOF, len(dst))
+	m.n += count
+	return copy(dst, src[:nSrc]), nSrc, err
+}
+
+// Span reports the input up to the rune past the limit as unchanged, without
+// counting it.
+func (m *maxLength) Span(src []byte, atEOF bool) (n int, err error) {
+	n, _, err = m.span(src, atEOF, len(src))
+	return n, err
+}
+
+// span returns the number of bytes and runes of the input that do not exceed
+// the limit and fit in a destination of size szDst.
+func (m *maxLength) span(src []byte, atEOF bool, szDst int) (n, count int, err error) {
+	for n < len(src) {
+		if m.n+count >= m.max {
+			return n, count, ErrTooLong
+		}
+		size := 1
+		if src[n] >= utf8.RuneSelf {
+			if !atEOF && !utf8.FullRune(src[n:]) {
+				return n, count, transform.ErrShortSrc
+			}
+			_, size = utf8.DecodeRune(src[n:])
+		}
+		if n+size > szDst {
+			return n, count, transform.ErrShortDst
+		}
+		n += size
+		count++
+	}
+	return n, count, nil
+}
+
+// ErrTruncated is reported by the Rewriter returned by NewTruncator when the
+// input exceeds the limit. The output written before it is the truncated
+// input.
+var ErrTruncated = errors.New("textutil: input truncated")
+
+// A TruncateUnit is the unit in which NewTruncator measures the limit.
+type TruncateUnit int
+
+const (
+	// TruncateBytes measures the length of the output in bytes.
+	TruncateBytes TruncateUnit = iota
+
+	// TruncateRunes measures the length of the output in runes.
+	TruncateRunes
+)
+
+// NewTruncator returns a Rewriter that writes its input until the output
+// would exceed max units, at which point it reports ErrTruncated. A rune that
+// does not fit within the limit is discarded in full, so the output is never
+// cut in the middle of a multi-byte rune. Invalid UTF-8 is written as U+FFFD
+// and counted as such. Reset resets the count.
+func NewTruncator(max int, unit TruncateUnit) Rewriter {
+	return &truncator{max: max, unit: unit}
+}
+
+type truncator struct {
+	max  int
+	unit TruncateUnit
+
+	// n is the length of the output written since the last Reset.
+	n int
+}
+
+func (t *truncator) Reset() { t.n = 0 }
+
+func (t *truncator) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	n := 1
+	if t.unit == TruncateBytes {
+		n = utf8.RuneLen(r)
+	}
+	if t.n+n > t.max {
+		s.SetError(ErrTruncated)
+		return
+	}
+	if s.WriteRune(r) {
+		t.n += n
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestRequireMaxLength(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "within limit",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "abc",
+		outFull: "abc",
+		t:       RequireMaxLength(5),
+	}, {
+		desc:    "at limit",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abcde",
+		out:     "abcde",
+		outFull: "abcde",
+		t:       RequireMaxLength(5),
+	}, {
+		desc:    "one past limit",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abcdef",
+		out:     "abcde",
+		outFull: "abcde",
+		err:     ErrTooLong,
+		t:       RequireMaxLength(5),
+		errSpan: ErrTooLong,
+	}, {
+		desc:    "multi-byte runes",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Thé qüick",
+		out:     "Thé q",
+		outFull: "Thé q",
+		err:     ErrTooLong,
+		t:       RequireMaxLength(5),
+		errSpan: ErrTooLong,
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\x80\xffab",
+		out:     "\x80\xffa",
+		outFull: "\x80\xffa",
+		err:     ErrTooLong,
+		t:       RequireMaxLength(3),
+		errSpan: ErrTooLong,
+	}, {
+		desc:    "zero",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a",
+		out:     "",
+		outFull: "",
+		err:     ErrTooLong,
+		t:       RequireMaxLength(0),
+		errSpan: ErrTooLong,
+	}, {
+		desc:    "incomplete rune",
+		szDst:   large,
+		atEOF:   false,
+		in:      "ab\xc3",
+		out:     "ab",
+		outFull: "ab\xc3",
+		err:     transform.ErrShortSrc,
+		t:       RequireMaxLength(5),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   2,
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "aéb",
+		out:     "aé",
+		outFull: "aéb",
+		err:     transform.ErrShortDst,
+		t:       RequireMaxLength(3),
+	}, {
+		desc:    "short destination within rune",
+		szDst:   2,
+		atEOF:   true,
+		in:      "aéb",
+		out:     "a",
+		outFull: "aéb",
+		err:     transform.ErrShortDst,
+		t:       RequireMaxLength(3),
+	}}
+	for i, tt := range testCases {
+		tt.t.Reset()
+		tt.check(t, i)
+	}
+}
+
+func TestRequireMaxLengthReset(t *testing.T) {
+	tr := RequireMaxLength(200)
+	in := strings.Repeat("é", 200)
+	for i := 0; i < 2; i++ {
+		// The count spans the internal buffers of transform.String.
+		if out, _, err := transform.String(tr, in); out != in || err != nil {
+			t.Errorf("%d: got %d bytes, %v; want %d bytes, <nil>", i, len(out), err, len(in))
+		}
+		if out, _, err := transform.String(tr, in+"x"); out != in || err != ErrTooLong {
+			t.Errorf("%d: got %d bytes, %v; want %d bytes, %v", i, len(out), err, len(in), ErrTooLong)
+		}
+	}
+}
+
+func TestTruncator(t *testing.T) {
+	bytes := func(max int) Transformer { return NewTransformer(NewTruncator(max, TruncateBytes)) }
+	runes := func(max int) Transformer { return NewTransformer(NewTruncator(max, TruncateRunes)) }
+	testCases := []transformTest{{
+		desc:    "within limit",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "abc",
+		outFull: "abc",
+		t:       bytes(5),
+	}, {
+		desc:    "at limit",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abcde",
+		out:     "abcde",
+		outFull: "abcde",
+		t:       bytes(5),
+	}, {
+		desc:    "one past limit",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abcdef",
+		out:     "abcde",
+		outFull: "abcde",
+		err:     ErrTruncated,
+		t:       bytes(5),
+		errSpan: ErrTruncated,
+	}, {
+		desc:    "bytes at multi-byte boundary",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Th\u00e9 q",
+		out:     "Th\u00e9",
+		outFull: "Th\u00e9",
+		err:     ErrTruncated,
+		t:       bytes(4),
+		errSpan: ErrTruncated,
+	}, {
+		desc:    "bytes within multi-byte rune",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Th\u00e9 q",
+		out:     "Th",
+		outFull: "Th",
+		err:     ErrTruncated,
+		t:       bytes(3),
+		errSpan: ErrTruncated,
+	}, {
+		desc:    "runes",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Th\u00e9 q\u00fcick",
+		out:     "Th\u00e9 q\u00fc",
+		outFull: "Th\u00e9 q\u00fc",
+		err:     ErrTruncated,
+		t:       runes(6),
+		errSpan: ErrTruncated,
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xffb",
+		out:     "a\ufffd",
+		outFull: "a\ufffd",
+		err:     ErrTruncated,
+		t:       bytes(4),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "zero",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "",
+		outFull: "",
+		err:     ErrTruncated,
+		t:       runes(0),
+		errSpan: ErrTruncated,
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "a\u00e9\u00e9",
+		out:     "a\u00e9",
+		outFull: "a\u00e9\u00e9",
+		err:     transform.ErrShortDst,
+		t:       runes(3),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestTruncatorReset(t *testing.T) {
+	tr := NewTransformer(NewTruncator(3, TruncateRunes))
+	for i := 0; i < 2; i++ {
+		if got, err := tr.StringErr("abcd"); got != "abc" || !errors.Is(err, ErrTruncated) {
+			t.Errorf("%d: got %q, %v; want %q, %v", i, got, err, "abc", ErrTruncated)
+		}
+	}
+}
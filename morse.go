@@ -0,0 +1,283 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// MorseEncode returns a Transformer that encodes letters, digits and the
+// punctuation defined in ITU-R M.1677-1 as International Morse code, using dot
+// and dash for the short and long signals. Encoded characters are separated by
+// letterSep and runs of white space are replaced by a single wordSep. Leading
+// white space and characters that cannot be encoded are dropped.
+//
+// Common choices are '.', '-', " " and " / ".
+func MorseEncode(dot, dash rune, letterSep, wordSep string) Transformer {
+	return NewTransformer(&morseEncoder{
+		dot:       dot,
+		dash:      dash,
+		letterSep: letterSep,
+		wordSep:   wordSep,
+	})
+}
+
+type morseEncoder struct {
+	dot, dash          rune
+	letterSep, wordSep string
+
+	started bool // A character was encoded.
+	inWord  bool // The last rune was a character.
+	buf     []byte
+}
+
+func (m *morseEncoder) Reset() {
+	m.started = false
+	m.inWord = false
+}
+
+func (m *morseEncoder) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if unicode.IsSpace(r) {
+		m.inWord = false
+		return
+	}
+	code, ok := morseCodes[unicode.ToUpper(r)]
+	if !ok {
+		return
+	}
+	m.buf = m.buf[:0]
+	switch {
+	case !m.started:
+	case m.inWord:
+		m.buf = append(m.buf, m.letterSep...)
+	default:
+		m.buf = append(m.buf, m.wordSep...)
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] == '.' {
+			m.buf = utf8.AppendRune(m.buf, m.dot)
+		} else {
+			m.buf = utf8.AppendRune(m.buf, m.dash)
+		}
+	}
+	if s.WriteBytes(m.buf) {
+		m.started = true
+		m.inWord = true
+	}
+}
+
+// MorseDecode returns a Rewriter that decodes the output of MorseEncode with
+// the same arguments. The characters are decoded as uppercase letters. A
+// sequence of signals that does not denote a character is decoded as
+// utf8.RuneError. Each wordSep is decoded as a space and a letterSep between
+// two sequences of signals is dropped. Other text is written as is.
+//
+// The separators should not contain dot or dash. Input that may be a separator
+// is buffered, so they should be short.
+func MorseDecode(dot, dash rune, letterSep, wordSep string) Rewriter {
+	return &morseDecoder{
+		dot:       dot,
+		dash:      dash,
+		letterSep: letterSep,
+		wordSep:   wordSep,
+	}
+}
+
+type morseDecoder struct {
+	dot, dash          rune
+	letterSep, wordSep string
+
+	// afterSignals is set if the last input was a sequence of signals.
+	afterSignals bool
+	// invalid is set if the signals that follow are the remainder of a
+	// sequence already decoded as utf8.RuneError.
+	invalid bool
+
+	buf []byte
+}
+
+func (m *morseDecoder) Reset() {
+	m.afterSignals = false
+	m.invalid = false
+}
+
+func (m *morseDecoder) isSignal(r rune) bool { return r == m.dot || r == m.dash }
+
+func (m *morseDecoder) Rewrite(s State) {
+	if r, _ := s.PeekRune(); m.isSignal(r) {
+		m.rewriteSignals(s)
+		return
+	}
+	rs := NewRewindableState(s)
+	if m.wordSep != "" {
+		t := rs.Mark()
+		if matchString(rs, m.wordSep) {
+			if rs.WriteRune(' ') {
+				m.afterSignals, m.invalid = false, false
+			}
+			return
+		}
+		rs.Rollback(t)
+		if rs.Err() != nil {
+			// Out of input before knowing whether this is a separator.
+			return
+		}
+	}
+	if m.afterSignals && m.letterSep != "" {
+		t := rs.Mark()
+		if matchString(rs, m.letterSep) {
+			// If more input may follow, PeekRune sets ErrShortSrc at the end
+			// of the source and we will be called again.
+			if r, size := rs.PeekRune(); m.isSignal(r) || size == 0 && rs.IsAtEOF() {
+				if rs.IsSpan() {
+					// Dropping the separator ends a span.
+					rs.SetError(transform.ErrEndOfSpan)
+					return
+				}
+				m.afterSignals, m.invalid = false, false
+				return
+			}
+		}
+		rs.Rollback(t)
+		if rs.Err() != nil {
+			// Out of input before knowing whether this is a separator.
+			return
+		}
+	}
+	r, _ := rs.ReadRune()
+	if rs.WriteRune(r) {
+		m.afterSignals, m.invalid = false, false
+	}
+}
+
+// rewriteSignals decodes a sequence of signals. Sequences longer than any code
+// are decoded as utf8.RuneError as soon as this is known, after which the
+// remaining signals are dropped one by one.
+func (m *morseDecoder) rewriteSignals(s State) {
+	if m.invalid {
+		if s.IsSpan() {
+			// Dropping the signal ends a span.
+			s.SetError(transform.ErrEndOfSpan)
+			return
+		}
+		s.Skip()
+		return
+	}
+	m.buf = m.buf[:0]
+	for len(m.buf) <= maxMorseCode {
+		r, size := s.ReadRune()
+		if size == 0 || !m.isSignal(r) {
+			// At the end of input, ReadRune has set ErrShortSrc if more input
+			// may follow.
+			s.UnreadRune()
+			break
+		}
+		if r == m.dot {
+			m.buf = append(m.buf, '.')
+		} else {
+			m.buf = append(m.buf, '-')
+		}
+	}
+
+	if len(m.buf) > maxMorseCode {
+		if s.WriteRune(utf8.RuneError) {
+			m.afterSignals, m.invalid = true, true
+		}
+		return
+	}
+	if r, ok := morseLetters[string(m.buf)]; ok {
+		s.WriteRune(r)
+	} else if !s.WriteRune(utf8.RuneError) {
+		return
+	}
+	m.afterSignals = true
+}
+
+// matchString reads the runes of str from s and reports whether they match.
+func matchString(s State, str string) bool {
+	for _, want := range str {
+		if r, size := s.ReadRune(); size == 0 || r != want {
+			return false
+		}
+	}
+	return true
+}
+
+// morseCodes holds the codes of ITU-R M.1677-1.
+var morseCodes = map[rune]string{
+	'A': ".-",
+	'B': "-...",
+	'C': "-.-.",
+	'D': "-..",
+	'E': ".",
+	'F': "..-.",
+	'G': "--.",
+	'H': "....",
+	'I': "..",
+	'J': ".---",
+	'K': "-.-",
+	'L': ".-..",
+	'M': "--",
+	'N': "-.",
+	'O': "---",
+	'P': ".--.",
+	'Q': "--.-",
+	'R': ".-.",
+	'S': "...",
+	'T': "-",
+	'U': "..-",
+	'V': "...-",
+	'W': ".--",
+	'X': "-..-",
+	'Y': "-.--",
+	'Z': "--..",
+
+	'1': ".----",
+	'2': "..---",
+	'3': "...--",
+	'4': "....-",
+	'5': ".....",
+	'6': "-....",
+	'7': "--...",
+	'8': "---..",
+	'9': "----.",
+	'0': "-----",
+
+	'.':  ".-.-.-",
+	',':  "--..--",
+	':':  "---...",
+	'?':  "..--..",
+	'\'': ".----.",
+	'-':  "-....-",
+	'/':  "-..-.",
+	'(':  "-.--.",
+	')':  "-.--.-",
+	'"':  ".-..-.",
+	'=':  "-...-",
+	'+':  ".-.-.",
+	'@':  ".--.-.",
+}
+
+// maxMorseCode is the number of signals of the longest code in morseCodes.
+var maxMorseCode = func() (n int) {
+	for _, code := range morseCodes {
+		if len(code) > n {
+			n = len(code)
+		}
+	}
+	return n
+}()
+
+var morseLetters = func() map[string]rune {
+	m := make(map[string]rune, len(morseCodes))
+	for r, code := range morseCodes {
+		m[code] = r
+	}
+	return m
+}()
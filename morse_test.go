@@ -0,0 +1,153 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestMorse(t *testing.T) {
+	encode := MorseEncode('.', '-', " ", " / ")
+	decode := NewTransformer(MorseDecode('.', '-', " ", " / "))
+
+	testCases := []transformTest{{
+		desc:    "encode SOS",
+		szDst:   large,
+		atEOF:   true,
+		in:      "SOS",
+		out:     "... --- ...",
+		outFull: "... --- ...",
+		t:       encode,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "encode words",
+		szDst:   large,
+		atEOF:   true,
+		in:      "  Hello,  world\n1 ",
+		out:     ".... . .-.. .-.. --- --..-- / .-- --- .-. .-.. -.. / .----",
+		outFull: ".... . .-.. .-.. --- --..-- / .-- --- .-. .-.. -.. / .----",
+		t:       encode,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "encode unsupported",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a!é b",
+		out:     ".- / -...",
+		outFull: ".- / -...",
+		t:       encode,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "encode custom signals",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ok go",
+		out:     "▬▬▬|▬•▬_▬▬•|▬▬▬",
+		outFull: "▬▬▬|▬•▬_▬▬•|▬▬▬",
+		t:       MorseEncode('•', '▬', "|", "_"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "encode short destination",
+		szDst:   6,
+		atEOF:   true,
+		in:      "SOS",
+		out:     "...",
+		outFull: "... --- ...",
+		err:     transform.ErrShortDst,
+		t:       encode,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "decode",
+		szDst:   large,
+		atEOF:   true,
+		in:      ".... .. / - .... . .-. .",
+		out:     "HI THERE",
+		outFull: "HI THERE",
+		t:       decode,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "decode invalid",
+		szDst:   large,
+		atEOF:   true,
+		in:      "........ x .-",
+		out:     "� x A",
+		outFull: "� x A",
+		t:       decode,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "decode at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "... ---",
+		out:     "S",
+		outFull: "SO",
+		err:     transform.ErrShortSrc,
+		t:       decode,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "decode long sequence at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      ".......",
+		out:     "\uFFFD",
+		outFull: "\uFFFD",
+		t:       decode,
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	for r, code := range morseCodes {
+		if got := encode.String(string(r)); got != code {
+			t.Errorf("%q: got %q; want %q", r, got, code)
+		}
+		if got := decode.String(code); got != string(r) {
+			t.Errorf("%q: got %q; want %q", code, got, string(r))
+		}
+	}
+	roundTrip(t, encode, decode, []string{
+		"SOS",
+		"THE QUICK BROWN FOX JUMPS OVER THE LAZY DOG",
+		"0123456789",
+		"A1 B2 C3",
+	})
+}
+
+func TestMorseLong(t *testing.T) {
+	// Long input between separators is not buffered.
+	decode := NewTransformer(MorseDecode('.', '-', " ", " / "))
+	testCases := []struct{ in, out string }{
+		{".- " + strings.Repeat("x", 10000) + " .-", "A " + strings.Repeat("x", 10000) + " A"},
+		{strings.Repeat(".", 10000) + " .-", "\uFFFDA"},
+		{strings.Repeat(".- / ", 3000), strings.Repeat("A ", 3000)},
+		{strings.Repeat("... ", 3000), strings.Repeat("S", 3000)},
+	}
+	for _, tc := range testCases {
+		b, err := ioutil.ReadAll(transform.NewReader(strings.NewReader(tc.in), decode))
+		if got := string(b); got != tc.out || err != nil {
+			t.Errorf("%.20q: got %.20q, %v; want %.20q, <nil>", tc.in, got, err, tc.out)
+		}
+	}
+}
+
+func TestMorseSpan(t *testing.T) {
+	decode := NewTransformer(MorseDecode('.', '-', " ", " / "))
+	testCases := []struct{ in, out string }{
+		{"a  b", "a  b"},
+		{"a .- .-", "a AA"},
+		{"a .- / .-", "a A A"},
+		{"a ........ .. x", "a \uFFFDI x"},
+	}
+	for _, tc := range testCases {
+		if got, err := spanTransform(decode, tc.in); got != tc.out || err != nil {
+			t.Errorf("%q: got %q, %v; want %q, <nil>", tc.in, got, err, tc.out)
+		}
+	}
+}
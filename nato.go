@@ -0,0 +1,54 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// NATOPhonetic returns a Transformer that spells out each ASCII letter, of
+// either case, as its code word of the NATO phonetic alphabet. Consecutive code
+// words are separated by a space. All other runes are left unchanged.
+// For example, "SOS 1" becomes "Sierra Oscar Sierra 1".
+func NATOPhonetic() Transformer {
+	return NewTransformer(&natoPhonetic{})
+}
+
+type natoPhonetic struct {
+	// afterLetter is set if the last rune written was a code word.
+	afterLetter bool
+}
+
+func (n *natoPhonetic) Reset() { n.afterLetter = false }
+
+func (n *natoPhonetic) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	word, ok := natoWord(r)
+	if !ok {
+		if s.WriteRune(r) {
+			n.afterLetter = false
+		}
+		return
+	}
+	if n.afterLetter && !s.WriteRune(' ') {
+		return
+	}
+	if s.WriteString(word) {
+		n.afterLetter = true
+	}
+}
+
+func natoWord(r rune) (word string, ok bool) {
+	switch {
+	case 'a' <= r && r <= 'z':
+		return natoAlphabet[r-'a'], true
+	case 'A' <= r && r <= 'Z':
+		return natoAlphabet[r-'A'], true
+	}
+	return "", false
+}
+
+var natoAlphabet = [26]string{
+	"Alpha", "Bravo", "Charlie", "Delta", "Echo", "Foxtrot", "Golf",
+	"Hotel", "India", "Juliett", "Kilo", "Lima", "Mike", "November",
+	"Oscar", "Papa", "Quebec", "Romeo", "Sierra", "Tango", "Uniform",
+	"Victor", "Whiskey", "X-ray", "Yankee", "Zulu",
+}
@@ -0,0 +1,72 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestNATOPhonetic(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "consecutive letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "(ABc)",
+		out:     "(Alpha Bravo Charlie)",
+		outFull: "(Alpha Bravo Charlie)",
+		t:       NATOPhonetic(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "non-letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "1. SOS, 2x!",
+		out:     "1. Sierra Oscar Sierra, 2X-ray!",
+		outFull: "1. Sierra Oscar Sierra, 2X-ray!",
+		t:       NATOPhonetic(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("1. "),
+	}, {
+		desc:    "short destination",
+		szDst:   8,
+		atEOF:   true,
+		in:      "-ab",
+		out:     "-Alpha",
+		outFull: "-Alpha Bravo",
+		err:     transform.ErrShortDst,
+		t:       NATOPhonetic(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "no letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "123 é ø",
+		out:     "123 é ø",
+		outFull: "123 é ø",
+		t:       NATOPhonetic(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	for r := 'a'; r <= 'z'; r++ {
+		want := natoAlphabet[r-'a']
+		if got := NATOPhonetic().String(string(r)); got != want {
+			t.Errorf("%q: got %q; want %q", r, got, want)
+		}
+		upper := strings.ToUpper(string(r))
+		if got := NATOPhonetic().String(upper); got != want {
+			t.Errorf("%q: got %q; want %q", upper, got, want)
+		}
+		if !strings.HasPrefix(want, upper) {
+			t.Errorf("%q: code word %q does not start with letter", r, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode"
+
+// StripNoncharacters returns a Transformer that removes the 66 Unicode
+// noncharacters: U+FDD0–U+FDEF and the last two code points of each plane,
+// such as U+FFFE and U+10FFFF. Noncharacters are permanently reserved for
+// internal use and should not appear in interchanged text.
+func StripNoncharacters() Transformer {
+	return RemoveFunc(isNoncharacter)
+}
+
+func isNoncharacter(r rune) bool {
+	return r >= 0xFDD0 && unicode.Is(noncharacters, r)
+}
+
+// noncharacters holds the code points with the Unicode property
+// Noncharacter_Code_Point.
+var noncharacters = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0xfdd0, Hi: 0xfdef, Stride: 1},
+		{Lo: 0xfffe, Hi: 0xffff, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1fffe, Hi: 0x1ffff, Stride: 1},
+		{Lo: 0x2fffe, Hi: 0x2ffff, Stride: 1},
+		{Lo: 0x3fffe, Hi: 0x3ffff, Stride: 1},
+		{Lo: 0x4fffe, Hi: 0x4ffff, Stride: 1},
+		{Lo: 0x5fffe, Hi: 0x5ffff, Stride: 1},
+		{Lo: 0x6fffe, Hi: 0x6ffff, Stride: 1},
+		{Lo: 0x7fffe, Hi: 0x7ffff, Stride: 1},
+		{Lo: 0x8fffe, Hi: 0x8ffff, Stride: 1},
+		{Lo: 0x9fffe, Hi: 0x9ffff, Stride: 1},
+		{Lo: 0xafffe, Hi: 0xaffff, Stride: 1},
+		{Lo: 0xbfffe, Hi: 0xbffff, Stride: 1},
+		{Lo: 0xcfffe, Hi: 0xcffff, Stride: 1},
+		{Lo: 0xdfffe, Hi: 0xdffff, Stride: 1},
+		{Lo: 0xefffe, Hi: 0xeffff, Stride: 1},
+		{Lo: 0xffffe, Hi: 0xfffff, Stride: 1},
+		{Lo: 0x10fffe, Hi: 0x10ffff, Stride: 1},
+	},
+}
@@ -0,0 +1,55 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestStripNoncharacters(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "noncharacters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\ufffeb\uffffc\ufdefd\U0010ffff",
+		out:     "abcd",
+		outFull: "abcd",
+		t:       StripNoncharacters(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "nearby characters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\ufdcf\ufdf0\ufffd\U0001fffd\U00010000\U0010fffd",
+		out:     "\ufdcf\ufdf0\ufffd\U0001fffd\U00010000\U0010fffd",
+		outFull: "\ufdcf\ufdf0\ufffd\U0001fffd\U00010000\U0010fffd",
+		t:       StripNoncharacters(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestNoncharacters(t *testing.T) {
+	n := 0
+	for r := rune(0); r <= 0x10ffff; r++ {
+		want := 0xfdd0 <= r && r <= 0xfdef || r&0xfffe == 0xfffe
+		if got := isNoncharacter(r); got != want {
+			t.Errorf("%U: got %v; want %v", r, got, want)
+		}
+		if want {
+			if got := StripNoncharacters().String("x" + string(r)); got != "x" {
+				t.Errorf("%U: got %+q; want %q", r, got, "x")
+			}
+			n++
+		}
+	}
+	if n != 66 {
+		t.Errorf("got %d noncharacters; want 66", n)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NFC returns a Transformer that converts input to Unicode Normalization Form C.
+func NFC() Transformer { return Transformer{norm.NFC} }
+
+// NFD returns a Transformer that converts input to Unicode Normalization Form D.
+func NFD() Transformer { return Transformer{norm.NFD} }
+
+// StripDiacritics returns a Transformer that removes all nonspacing marks, such
+// as accents, from the input. The input is decomposed first, so that precomposed
+// characters like é are converted to their base character as well. The result
+// is in Normalization Form C.
+func StripDiacritics() Transformer {
+	return ChainTransformers(NFD(), NewTransformer(NewDiacriticsStripper()), NFC())
+}
+
+// NewDiacriticsStripper returns a Rewriter that removes all nonspacing marks
+// (general category Mn), such as accents, from the input. Precomposed
+// characters like é are left unchanged, so the input should normally be
+// decomposed with NFD first. StripDiacritics does so.
+func NewDiacriticsStripper() Rewriter {
+	return NewFilterRewriter(func(r rune) bool { return !isMn(r) })
+}
+
+func isMn(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
+
+// NormalizeCJKCompatibility returns a Transformer that replaces CJK
+// Compatibility Ideographs (U+F900–U+FAFF and U+2F800–U+2FA1F) with their
+// canonical equivalent unified ideograph, such as U+F900 with U+8C48 (豈).
+// This is a subset of the mappings applied by all Unicode normalization forms.
+// Compatibility ideographs that are unified ideographs themselves, such as
+// U+FA0E, are left unchanged.
+func NormalizeCJKCompatibility() Transformer {
+	return MapRune(cjkCompatibility)
+}
+
+func cjkCompatibility(r rune) rune {
+	if !(0xF900 <= r && r <= 0xFAFF || 0x2F800 <= r && r <= 0x2FA1F) {
+		return r
+	}
+	// The mappings are the singleton canonical decompositions of the Unicode
+	// Character Database.
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	if d := norm.NFD.Properties(buf[:n]).Decomposition(); d != nil {
+		r, _ = utf8.DecodeRune(d)
+	}
+	return r
+}
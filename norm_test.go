@@ -0,0 +1,166 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+	"unicode"
+
+	"golang.org/x/text/transform"
+)
+
+func TestStripDiacritics(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "precomposed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Crème brûlée",
+		out:     "Creme brulee",
+		outFull: "Creme brulee",
+		t:       StripDiacritics(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "decomposed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Cre\u0300me",
+		out:     "Creme",
+		outFull: "Creme",
+		t:       StripDiacritics(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "no diacritics",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, ø and 日本",
+		out:     "Hello, ø and 日本",
+		outFull: "Hello, ø and 日本",
+		t:       StripDiacritics(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestDiacriticsStripper(t *testing.T) {
+	strip := NewTransformer(NewDiacriticsStripper())
+	testCases := []transformTest{{
+		desc:    "decomposed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Cre\u0300me bru\u0302le\u0301e",
+		out:     "Creme brulee",
+		outFull: "Creme brulee",
+		t:       strip,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "precomposed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Cr\u00e8me br\u00fbl\u00e9e",
+		out:     "Cr\u00e8me br\u00fbl\u00e9e",
+		outFull: "Cr\u00e8me br\u00fbl\u00e9e",
+		t:       strip,
+	}, {
+		desc:    "precomposed after NFD",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Cr\u00e8me br\u00fbl\u00e9e",
+		out:     "Creme brulee",
+		outFull: "Creme brulee",
+		t:       ChainTransformers(NFD(), strip),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "Hebrew cantillation and vowel points",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u05d1\u05bc\u05b0\u05e8\u05b5\u0591\u05d0\u05e9\u05b4\u05c1\u0596\u05d9\u05ea",
+		out:     "\u05d1\u05e8\u05d0\u05e9\u05d9\u05ea",
+		outFull: "\u05d1\u05e8\u05d0\u05e9\u05d9\u05ea",
+		t:       strip,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "enclosing and spacing marks",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u20ddb\u0903",
+		out:     "a\u20ddb\u0903",
+		outFull: "a\u20ddb\u0903",
+		t:       strip,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestNFC(t *testing.T) {
+	if got, want := NFC().String("e\u0301"), "é"; got != want {
+		t.Errorf("NFC: got %+q; want %+q", got, want)
+	}
+	if got, want := NFD().String("é"), "e\u0301"; got != want {
+		t.Errorf("NFD: got %+q; want %+q", got, want)
+	}
+}
+
+func TestNormalizeCJKCompatibility(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "compatibility ideographs",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\uf900\uf901\uf902\uf91d\uf9ff\ufa10\ufa2e\ufa70\ufad9",
+		out:     "a\u8c48\u66f4\u8eca\u6b04\u523a\u585a\u90de\u4e26\u9f8e",
+		outFull: "a\u8c48\u66f4\u8eca\u6b04\u523a\u585a\u90de\u4e26\u9f8e",
+		t:       NormalizeCJKCompatibility(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "supplement",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\U0002f800\U0002fa1d",
+		out:     "a\u4e3d\U0002a600",
+		outFull: "a\u4e3d\U0002a600",
+		t:       NormalizeCJKCompatibility(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "unchanged",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u8c48\u4e00 abc \ufa0e\ufa0f\ufb00\uff21",
+		out:     "\u8c48\u4e00 abc \ufa0e\ufa0f\ufb00\uff21",
+		outFull: "\u8c48\u4e00 abc \ufa0e\ufa0f\ufb00\uff21",
+		t:       NormalizeCJKCompatibility(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestCJKCompatibilityBlock(t *testing.T) {
+	n := 0
+	for r := rune(0xf900); r <= 0xfaff; r++ {
+		got := cjkCompatibility(r)
+		if got == r {
+			continue
+		}
+		n++
+		if !unicode.Is(unicode.Han, got) || got >= 0xf900 && got <= 0xfaff {
+			t.Errorf("%U: got %U; want unified ideograph", r, got)
+		}
+		if want := NFC().String(string(r)); string(got) != want {
+			t.Errorf("%U: got %U; want %+q", r, got, want)
+		}
+	}
+	// The block has 460 ideographs with a canonical decomposition, 12 unified
+	// ideographs and 40 unassigned code points.
+	if n != 460 {
+		t.Errorf("got %d mapped ideographs; want 460", n)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrNotNormalized is the error wrapped by a NormalizationError.
+var ErrNotNormalized = errors.New("textutil: text not normalized")
+
+// A NormalizationError is returned by the Transformer returned by
+// VerifyNormalization for input that is not in the verified form. Offsets are
+// counted in bytes from the start of input or the last Reset.
+type NormalizationError struct {
+	Form norm.Form
+
+	// Offset is the position of the first rune that is not normalized.
+	Offset int64
+
+	// Start and End delimit the normalization segment containing the rune,
+	// with Start <= Offset < End. Normalizing the text in this range in
+	// isolation yields the normalized text for the range.
+	Start, End int64
+}
+
+func (e *NormalizationError) Error() string {
+	return fmt.Sprintf("textutil: text not in %s at byte offset %d", formName(e.Form), e.Offset)
+}
+
+// Unwrap returns ErrNotNormalized.
+func (e *NormalizationError) Unwrap() error { return ErrNotNormalized }
+
+func formName(f norm.Form) string {
+	switch f {
+	case norm.NFC:
+		return "NFC"
+	case norm.NFD:
+		return "NFD"
+	case norm.NFKC:
+		return "NFKC"
+	case norm.NFKD:
+		return "NFKD"
+	}
+	return fmt.Sprintf("norm.Form(%d)", int(f))
+}
+
+// VerifyNormalization returns a Transformer that copies its input verbatim
+// and returns a *NormalizationError at the first rune that is not in the
+// given normalization form. The output contains all input up to that rune.
+// Once the error is returned, it is returned for all further input until
+// Reset is called.
+//
+// Verification uses the quick check of form and only normalizes the segments
+// for which it is inconclusive.
+func VerifyNormalization(form norm.Form) Transformer {
+	return Transformer{&verifyNorm{form: form}}
+}
+
+type verifyNorm struct {
+	form norm.Form
+
+	// offset is the number of bytes read since the last Reset.
+	offset int64
+	err    error
+}
+
+func (v *verifyNorm) Reset() {
+	v.offset, v.err = 0, nil
+}
+
+func (v *verifyNorm) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if v.err != nil {
+		return 0, 0, v.err
+	}
+	n, err := v.Span(src, atEOF)
+	if n > len(dst) {
+		n, err = len(dst), transform.ErrShortDst
+	}
+	if _, ok := err.(*NormalizationError); ok {
+		v.err = err
+	}
+	v.offset += int64(n)
+	return copy(dst, src[:n]), n, err
+}
+
+// Span returns the length of the normalized prefix of src. The input is
+// verified as by Transform, but without changing the state of v.
+func (v *verifyNorm) Span(src []byte, atEOF bool) (n int, err error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	for n < len(src) {
+		// The quick check may report a segment as not normalized even if
+		// it is.
+		k, err := v.form.Span(src[n:], atEOF)
+		if n += k; err != transform.ErrEndOfSpan {
+			return n, err
+		}
+		k = v.form.NextBoundary(src[n:], atEOF)
+		if k < 0 {
+			return n, transform.ErrShortSrc
+		}
+		seg := src[n : n+k]
+		if i := firstNotNormalized(seg, v.form.Bytes(seg)); i < len(seg) {
+			start := v.offset + int64(n)
+			return n + i, &NormalizationError{
+				Form:   v.form,
+				Offset: start + int64(i),
+				Start:  start,
+				End:    start + int64(k),
+			}
+		}
+		n += k
+	}
+	return n, nil
+}
+
+// firstNotNormalized returns the position of the first rune in seg that
+// differs from its normalized form want, or len(seg) if they are equal.
+func firstNotNormalized(seg, want []byte) int {
+	i := 0
+	for ; i < len(seg) && i < len(want) && seg[i] == want[i]; i++ {
+	}
+	if i == len(seg) && i == len(want) {
+		return i
+	}
+	if i == len(seg) {
+		// The normalized segment is longer; blame the last rune.
+		i--
+	}
+	for i > 0 && !utf8.RuneStart(seg[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestVerifyNormalization(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "NFC",
+		szDst:   large,
+		atEOF:   true,
+		in:      input,
+		out:     input,
+		outFull: input,
+		t:       VerifyNormalization(norm.NFC),
+	}, {
+		desc:    "inconclusive quick check",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x\u0301 \u0301",
+		out:     "x\u0301 \u0301",
+		outFull: "x\u0301 \u0301",
+		t:       VerifyNormalization(norm.NFC),
+	}, {
+		desc:    "NFD",
+		szDst:   large,
+		atEOF:   true,
+		in:      "the\u0301 \u1100\u1161",
+		out:     "the\u0301 \u1100\u1161",
+		outFull: "the\u0301 \u1100\u1161",
+		t:       VerifyNormalization(norm.NFD),
+	}, {
+		desc:    "segment at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "abc",
+		out:     "ab",
+		outFull: "abc",
+		err:     transform.ErrShortSrc,
+		t:       VerifyNormalization(norm.NFC),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   2,
+	}, {
+		desc:    "short destination",
+		szDst:   4,
+		atEOF:   true,
+		in:      "Th\u00e9 q\u00fcick",
+		out:     "Th\u00e9",
+		outFull: "Th\u00e9 q\u00fcick",
+		err:     transform.ErrShortDst,
+		t:       VerifyNormalization(norm.NFC),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestVerifyNormalizationError(t *testing.T) {
+	testCases := []struct {
+		form norm.Form
+		in   string
+		out  string
+
+		offset, start, end int64
+	}{
+		{norm.NFC, "abe\u0301c", "ab", 2, 2, 5},
+		{norm.NFC, "x\u0301\u0301e\u0316\u0301", "x\u0301\u0301", 5, 5, 10},
+		{norm.NFC, "\u212b", "", 0, 0, 3},
+		{norm.NFD, "caf\u00e9", "caf", 3, 3, 5},
+		{norm.NFD, "a\u0301\u0316", "a", 1, 0, 5},
+		{norm.NFKC, "\uff76\u30ab", "", 0, 0, 3},
+		{norm.NFKD, "x\u2460", "x", 1, 1, 4},
+		{norm.NFC, strings.Repeat("a", 300) + "e\u0301", strings.Repeat("a", 300), 300, 300, 303},
+	}
+	for _, tc := range testCases {
+		tr := VerifyNormalization(tc.form)
+		out, _, err := transform.String(tr, tc.in)
+		if out != tc.out {
+			t.Errorf("%+q: got %+q; want %+q", tc.in, out, tc.out)
+		}
+		e, ok := err.(*NormalizationError)
+		if !ok {
+			t.Errorf("%+q: got error %v; want *NormalizationError", tc.in, err)
+			continue
+		}
+		if e.Form != tc.form || e.Offset != tc.offset || e.Start != tc.start || e.End != tc.end {
+			t.Errorf("%+q: got %+v; want offset %d in [%d, %d)", tc.in, *e, tc.offset, tc.start, tc.end)
+		}
+		if !errors.Is(err, ErrNotNormalized) {
+			t.Errorf("%+q: error %v does not wrap ErrNotNormalized", tc.in, err)
+		}
+
+		// The error persists until Reset.
+		if _, _, err := tr.Transform(make([]byte, 10), []byte("a"), true); err != e {
+			t.Errorf("%+q: got %v after error; want %v", tc.in, err, e)
+		}
+
+		// Normalizing the reported segment fixes the error.
+		fixed := tc.in[:e.Start] + tc.form.String(tc.in[e.Start:e.End]) + tc.in[e.End:]
+		tr.Reset()
+		if out, _, err := transform.String(tr, fixed); out != fixed || err != nil {
+			t.Errorf("%+q: got %+q, %v after fixing; want %+q, <nil>", tc.in, out, err, fixed)
+		}
+	}
+}
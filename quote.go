@@ -0,0 +1,130 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+// StraightToTypographic returns a Transformer that replaces straight double
+// and single quotes with the typographic quotation marks used for lang, such
+// as “…” and ‘…’ for English, „…“ and ‚…‘ for German, and «…» and ‹…› for
+// French. Languages without specific support use the English quotes.
+//
+// A quote at the start of input, after white space, an opening bracket, or a
+// dash opens a quotation. Any other quote closes the innermost quotation of its
+// kind. A single quote within a word or outside a single quotation becomes an
+// apostrophe (’). A double quote that closes no quotation, such as in 5", is
+// left unchanged.
+func StraightToTypographic(lang language.Tag) Transformer {
+	return NewTransformer(&typographicQuotes{style: quoteStyleOf(lang)})
+}
+
+type quoteStyle struct {
+	open, close             rune
+	openSingle, closeSingle rune
+}
+
+// quoteStyles maps base languages to their quotation marks.
+var quoteStyles = map[string]quoteStyle{
+	"en": {'“', '”', '‘', '’'},
+	"de": {'„', '“', '‚', '‘'},
+	"cs": {'„', '“', '‚', '‘'},
+	"fr": {'«', '»', '‹', '›'},
+	"ru": {'«', '»', '„', '“'},
+	"uk": {'«', '»', '„', '“'},
+	"es": {'«', '»', '“', '”'},
+	"it": {'«', '»', '“', '”'},
+	"pl": {'„', '”', '«', '»'},
+	"sv": {'”', '”', '’', '’'},
+	"fi": {'”', '”', '’', '’'},
+	"ja": {'「', '」', '『', '』'},
+}
+
+func quoteStyleOf(lang language.Tag) quoteStyle {
+	base, _ := lang.Base()
+	if s, ok := quoteStyles[base.String()]; ok {
+		return s
+	}
+	return quoteStyles["en"]
+}
+
+const apostrophe = '’'
+
+type typographicQuotes struct {
+	style quoteStyle
+
+	// prev is the last rune read, if any. opened is the straight quote
+	// character if prev opened a quotation, or 0 otherwise.
+	prev    rune
+	hasPrev bool
+	opened  rune
+	// depth holds the number of open double and single quotations.
+	depth [2]int
+}
+
+func (q *typographicQuotes) Reset() {
+	*q = typographicQuotes{style: q.style}
+}
+
+func (q *typographicQuotes) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	if r != '"' && r != '\'' {
+		if s.WriteRune(r) {
+			q.prev, q.hasPrev, q.opened = r, true, 0
+		}
+		return
+	}
+	kind, open, close := 0, q.style.open, q.style.close
+	if r == '\'' {
+		kind, open, close = 1, q.style.openSingle, q.style.closeSingle
+	}
+	depth := q.depth[kind]
+	out, opened := r, rune(0)
+	switch {
+	case q.opened == r:
+		// An empty quotation.
+		out, depth = close, depth-1
+	case !q.hasPrev || q.opened != 0 || opensQuote(q.prev):
+		out, opened, depth = open, r, depth+1
+	case r == '\'' && unicode.IsLetter(q.prev):
+		// Unless it is within a quotation and at the end of a word, a single
+		// quote after a letter is an apostrophe.
+		next, size := s.ReadRune()
+		s.UnreadRune()
+		if size == 0 {
+			// Either the end of input or ReadRune has set ErrShortSrc.
+			if depth > 0 {
+				s.WriteRune(close)
+			} else {
+				s.WriteRune(apostrophe)
+			}
+			return
+		}
+		out = apostrophe
+		if depth > 0 && !unicode.IsLetter(next) {
+			out, depth = close, depth-1
+		}
+	case depth > 0:
+		out, depth = close, depth-1
+	case r == '\'':
+		out = apostrophe
+	}
+	if s.WriteRune(out) {
+		q.prev, q.hasPrev, q.opened = r, true, opened
+		q.depth[kind] = depth
+	}
+}
+
+// opensQuote reports whether a quote following r opens a quotation.
+func opensQuote(r rune) bool {
+	switch r {
+	case '(', '[', '{', '-', '–', '—', '/':
+		return true
+	}
+	return unicode.IsSpace(r)
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/transform"
+)
+
+func TestStraightToTypographic(t *testing.T) {
+	en := StraightToTypographic(language.English)
+	testCases := []transformTest{{
+		desc:    "simple quotation",
+		szDst:   large,
+		atEOF:   true,
+		in:      `He said "hello" and 'bye'.`,
+		out:     "He said “hello” and ‘bye’.",
+		outFull: "He said “hello” and ‘bye’.",
+		t:       en,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("He said "),
+	}, {
+		desc:    "nested quotes",
+		szDst:   large,
+		atEOF:   true,
+		in:      `"She said 'no'." ("'x'")`,
+		out:     "“She said ‘no’.” (“‘x’”)",
+		outFull: "“She said ‘no’.” (“‘x’”)",
+		t:       en,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "apostrophes",
+		szDst:   large,
+		atEOF:   true,
+		in:      `x don't 'rock 'n' roll' the dogs' bone`,
+		out:     "x don’t ‘rock ‘n’ roll’ the dogs’ bone",
+		outFull: "x don’t ‘rock ‘n’ roll’ the dogs’ bone",
+		t:       en,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("x don"),
+	}, {
+		desc:    "empty quotations",
+		szDst:   large,
+		atEOF:   true,
+		in:      `x "" '' ("")`,
+		out:     "x “” ‘’ (“”)",
+		outFull: "x “” ‘’ (“”)",
+		t:       en,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "unmatched double quote",
+		szDst:   large,
+		atEOF:   true,
+		in:      `a 5" screen`,
+		out:     `a 5" screen`,
+		outFull: `a 5" screen`,
+		t:       en,
+	}, {
+		desc:    "apostrophe at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "'rock'",
+		out:     "‘rock",
+		outFull: "‘rock’",
+		err:     transform.ErrShortSrc,
+		t:       en,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "German",
+		szDst:   large,
+		atEOF:   true,
+		in:      `"Er sagte 'ja'"`,
+		out:     "„Er sagte ‚ja‘“",
+		outFull: "„Er sagte ‚ja‘“",
+		t:       StraightToTypographic(language.German),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "French",
+		szDst:   large,
+		atEOF:   true,
+		in:      `"Il a dit 'oui'" l'an`,
+		out:     "«Il a dit ‹oui›» l’an",
+		outFull: "«Il a dit ‹oui›» l’an",
+		t:       StraightToTypographic(language.MustParse("fr-CA")),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "Russian",
+		szDst:   large,
+		atEOF:   true,
+		in:      `"Он сказал 'да'"`,
+		out:     "«Он сказал „да“»",
+		outFull: "«Он сказал „да“»",
+		t:       StraightToTypographic(language.Russian),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unsupported language",
+		szDst:   large,
+		atEOF:   true,
+		in:      `"ok"`,
+		out:     "“ok”",
+		outFull: "“ok”",
+		t:       StraightToTypographic(language.Und),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.t.Reset()
+		tt.check(t, i)
+	}
+}
+
+func TestStraightToTypographicReset(t *testing.T) {
+	tr := StraightToTypographic(language.English)
+	dst := make([]byte, large)
+	tr.Transform(dst, []byte(`"open`), true)
+	tr.Reset()
+	n, _, _ := tr.Transform(dst, []byte(`x"`), true)
+	if got, want := string(dst[:n]), `x"`; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
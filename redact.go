@@ -0,0 +1,190 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// ErrEmptyPattern is returned by Transformers created by Redact and RedactPII
+// if any of their patterns matches the empty string.
+var ErrEmptyPattern = errors.New("textutil: redact pattern matches the empty string")
+
+// Redact returns a Transformer that replaces each match of pattern with mask.
+// Matches are found from left to right and do not overlap. The pattern is used
+// as is, including its flags and Longest setting, and is matched against the
+// input remaining at each position. Hence ^ and \A match at any position.
+//
+// Input that may be the start of a match is held back until the match is
+// complete or no longer possible. Patterns should therefore only match short
+// and bounded sequences.
+func Redact(pattern *regexp.Regexp, mask string) Transformer {
+	return RedactPII([]*regexp.Regexp{pattern}, mask)
+}
+
+// RedactPII returns a Transformer that replaces each match of any of the
+// given patterns with mask. If multiple patterns match at the same position,
+// the longest match is replaced. Like Redact, it only holds back input that
+// may be the start of a match.
+func RedactPII(patterns []*regexp.Regexp, mask string) Transformer {
+	r := &redacter{mask: mask}
+	for _, p := range patterns {
+		if p.MatchString("") {
+			r.err = ErrEmptyPattern
+		}
+		r.patterns = append(r.patterns, p)
+		r.starts = append(r.starts, anchoredSuperset(p))
+	}
+	return Transformer{r}
+}
+
+// anchoredSuperset returns a regular expression that only matches at the start
+// of the input and that matches at least every prefix matched by p. Unlike p,
+// it does not need to read past the end of the input when no match at the start
+// is possible.
+func anchoredSuperset(p *regexp.Regexp) *regexp.Regexp {
+	// Perl syntax accepts any pattern accepted by the syntax of p and its
+	// character classes match at least as much.
+	re, err := syntax.Parse(p.String(), syntax.Perl)
+	if err != nil {
+		panic(err)
+	}
+	a := regexp.MustCompile(`\A(?:` + dropAssertions(re).String() + `)`)
+	a.Longest()
+	return a
+}
+
+// dropAssertions replaces all empty-width assertions in re with empty matches.
+func dropAssertions(re *syntax.Regexp) *syntax.Regexp {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch}
+	}
+	for i, sub := range re.Sub {
+		re.Sub[i] = dropAssertions(sub)
+	}
+	return re
+}
+
+type redacter struct {
+	transform.NopResetter
+
+	patterns []*regexp.Regexp
+	starts   []*regexp.Regexp // starts[i] is anchoredSuperset(patterns[i])
+	mask     string
+	err      error
+	reader   reader
+}
+
+// match reports the length of the longest match at the start of src. If more
+// input might change the result, it reports ok is false.
+func (r *redacter) match(src []byte, atEOF bool) (n int, ok bool) {
+	n = -1
+	for i, p := range r.patterns {
+		r.reader = reader{src: src}
+		if r.starts[i].FindReaderIndex(&r.reader) == nil && !r.reader.hitEnd {
+			continue
+		}
+		if !atEOF && r.reader.hitEnd {
+			return 0, false
+		}
+		// Once p matches at the start, it stops considering later positions,
+		// so it only reads past the end if the match itself may change.
+		r.reader = reader{src: src}
+		loc := p.FindReaderIndex(&r.reader)
+		if loc == nil || loc[0] != 0 {
+			continue
+		}
+		if !atEOF && r.reader.hitEnd {
+			return 0, false
+		}
+		if loc[1] > n {
+			n = loc[1]
+		}
+	}
+	return n, true
+}
+
+func (r *redacter) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if r.err != nil {
+		return 0, 0, r.err
+	}
+	// Invariant: src[nSrc:p] needs to be copied verbatim.
+	for p := 0; ; {
+		n, ok := -1, true
+		if p < len(src) {
+			n, ok = r.match(src[p:], atEOF)
+		}
+		if p == len(src) || n > 0 || !ok {
+			if len(dst)-nDst < p-nSrc {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			nDst += copy(dst[nDst:], src[nSrc:p])
+			nSrc = p
+		}
+		switch {
+		case p == len(src):
+			return nDst, nSrc, nil
+		case !ok:
+			return nDst, nSrc, transform.ErrShortSrc
+		case n > 0:
+			if len(dst)-nDst < len(r.mask) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			nDst += copy(dst[nDst:], r.mask)
+			nSrc += n
+			p += n
+		default:
+			_, size := utf8.DecodeRune(src[p:])
+			p += size
+		}
+	}
+}
+
+func (r *redacter) Span(src []byte, atEOF bool) (n int, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	for n < len(src) {
+		switch m, ok := r.match(src[n:], atEOF); {
+		case !ok:
+			return n, transform.ErrShortSrc
+		case m > 0:
+			return n, transform.ErrEndOfSpan
+		}
+		_, size := utf8.DecodeRune(src[n:])
+		n += size
+	}
+	return n, nil
+}
+
+// A reader is an io.RuneReader that records whether it was read past the end.
+type reader struct {
+	src    []byte
+	pos    int
+	hitEnd bool
+}
+
+func (r *reader) ReadRune() (c rune, size int, err error) {
+	if r.pos == len(r.src) {
+		r.hitEnd = true
+		return 0, 0, io.EOF
+	}
+	c, size = utf8.DecodeRune(r.src[r.pos:])
+	if c == utf8.RuneError && !utf8.FullRune(r.src[r.pos:]) {
+		// An incomplete rune at the end may be completed by more input.
+		r.hitEnd = true
+	}
+	r.pos += size
+	return c, size, nil
+}
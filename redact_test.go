@@ -0,0 +1,179 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestRedact(t *testing.T) {
+	ssn := regexp.MustCompile(`\d{3}-\d{2}-\d{4}`)
+	email := regexp.MustCompile(`[a-z]+@[a-z]+\.[a-z]+`)
+	digits := regexp.MustCompile(`\d+`)
+	longest := regexp.MustCompile(`a|ab`)
+	longest.Longest()
+
+	testCases := []transformTest{{
+		desc:    "single match",
+		szDst:   large,
+		atEOF:   true,
+		in:      "SSN: 123-45-6789.",
+		out:     "SSN: ***.",
+		outFull: "SSN: ***.",
+		t:       Redact(ssn, "***"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "longer mask",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a 12 b",
+		out:     "a [REDACTED] b",
+		outFull: "a [REDACTED] b",
+		t:       Redact(digits, "[REDACTED]"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "empty mask",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a 12 b 3",
+		out:     "a  b ",
+		outFull: "a  b ",
+		t:       Redact(digits, ""),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "consecutive matches",
+		szDst:   large,
+		atEOF:   true,
+		in:      "123-45-6789123-45-6789 x",
+		out:     "## x",
+		outFull: "## x",
+		t:       Redact(ssn, "#"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "match at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "call 12",
+		out:     "call ",
+		outFull: "call #",
+		err:     transform.ErrShortSrc,
+		t:       Redact(digits, "#"),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   len("call "),
+	}, {
+		desc:    "possible match at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a 123-4",
+		out:     "a ",
+		outFull: "a 123-4",
+		err:     transform.ErrShortSrc,
+		t:       Redact(ssn, "#"),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   len("a "),
+	}, {
+		desc:    "short destination",
+		szDst:   4,
+		atEOF:   true,
+		in:      "ab 12",
+		out:     "ab ",
+		outFull: "ab ####",
+		err:     transform.ErrShortDst,
+		t:       Redact(digits, "####"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no match",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Héllo, wörld 12-3",
+		out:     "Héllo, wörld 12-3",
+		outFull: "Héllo, wörld 12-3",
+		t:       Redact(ssn, "#"),
+	}, {
+		desc:    "multiple patterns",
+		szDst:   large,
+		atEOF:   true,
+		in:      "mail bob@example.com, SSN 123-45-6789 or 42",
+		out:     "mail ?, SSN ? or ?",
+		outFull: "mail ?, SSN ? or ?",
+		t:       RedactPII([]*regexp.Regexp{email, digits, ssn}, "?"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "leftmost-first",
+		szDst:   large,
+		atEOF:   true,
+		in:      "xaby",
+		out:     "x#by",
+		outFull: "x#by",
+		t:       Redact(regexp.MustCompile(`a|ab`), "#"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "longest match",
+		szDst:   large,
+		atEOF:   true,
+		in:      "xaby",
+		out:     "x#y",
+		outFull: "x#y",
+		t:       Redact(longest, "#"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "POSIX character class",
+		szDst:   large,
+		atEOF:   true,
+		in:      "x\nxb",
+		out:     "x\n#",
+		outFull: "x\n#",
+		t:       Redact(regexp.MustCompilePOSIX(`x[^a]`), "#"),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("x\n"),
+	}, {
+		desc:    "end of text",
+		szDst:   large,
+		atEOF:   true,
+		in:      "12 34",
+		out:     "12 #",
+		outFull: "12 #",
+		t:       Redact(regexp.MustCompile(`\d+$`), "#"),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "possible end of text at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "12 34",
+		out:     "12 ",
+		outFull: "12 #",
+		err:     transform.ErrShortSrc,
+		t:       Redact(regexp.MustCompile(`\d+$`), "#"),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   len("12 "),
+	}, {
+		desc:    "empty pattern",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		err:     ErrEmptyPattern,
+		t:       RedactPII([]*regexp.Regexp{digits, regexp.MustCompile(`x*`)}, "?"),
+		errSpan: ErrEmptyPattern,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestRedactString(t *testing.T) {
+	// Matches spanning the internal buffers of transform.String.
+	ssn := regexp.MustCompile(`\d{3}-\d{2}-\d{4}`)
+	for n := 115; n < 130; n++ {
+		in := strings.Repeat("x", n) + " 123-45-6789 " + strings.Repeat("y", n)
+		want := strings.Repeat("x", n) + " *** " + strings.Repeat("y", n)
+		if got := Redact(ssn, "***").String(in); got != want {
+			t.Errorf("%d: got %q; want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// NewTrieReplacer returns a Rewriter that replaces each occurrence of a key of
+// table with its value. Matches are found from left to right and do not
+// overlap. If multiple keys match at the same position, the longest one is
+// replaced. Replacements are not rescanned for matches. Invalid UTF-8 in the
+// input is replaced with U+FFFD.
+//
+// The keys are compiled into an Aho-Corasick automaton. Input that may be part
+// of a match is held back until the match is complete or no longer possible,
+// so this only requires buffering input of about twice the length of the
+// longest key. It panics if table contains an empty key.
+func NewTrieReplacer(table map[string]string) Rewriter {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		if k == "" {
+			panic("textutil: empty key in replacement table")
+		}
+		keys = append(keys, k)
+	}
+	// Sort the keys to make the numbering of nodes deterministic.
+	sort.Strings(keys)
+
+	t := &trieReplacer{nodes: []trieNode{{}}}
+	for _, k := range keys {
+		q := int32(0)
+		for _, r := range k {
+			c, ok := t.nodes[q].next[r]
+			if !ok {
+				c = int32(len(t.nodes))
+				t.nodes = append(t.nodes, trieNode{depth: t.nodes[q].depth + utf8.RuneLen(r)})
+				if t.nodes[q].next == nil {
+					t.nodes[q].next = map[rune]int32{}
+				}
+				t.nodes[q].next[r] = c
+			}
+			q = c
+		}
+		t.nodes[q].match, t.nodes[q].repl = q, table[k]
+	}
+
+	// Compute the failure links in breadth-first order, so that the links of
+	// all shallower nodes are known.
+	queue := []int32{0}
+	for len(queue) > 0 {
+		q := queue[0]
+		queue = queue[1:]
+		for r, c := range t.nodes[q].next {
+			queue = append(queue, c)
+			if q == 0 {
+				continue
+			}
+			f := t.advance(t.nodes[q].fail, r)
+			t.nodes[c].fail = f
+			if t.nodes[c].match == 0 {
+				t.nodes[c].match = t.nodes[f].match
+			}
+		}
+	}
+	return t
+}
+
+type trieNode struct {
+	next map[rune]int32
+
+	// fail is the node for the longest proper suffix of the path to this node
+	// that is also a path in the trie.
+	fail int32
+
+	// match is the node of the longest key that is a suffix of the path to
+	// this node, or 0 if there is none.
+	match int32
+
+	depth int // length of the path in bytes
+	repl  string
+}
+
+type trieReplacer struct {
+	nodes []trieNode
+
+	// cur is the committed state. next is the state computed by Rewrite,
+	// which replaces cur if all writes succeed.
+	cur, next trieState
+	out       []byte
+}
+
+type trieState struct {
+	q int32
+
+	// buf holds the input that has not yet been written. The first n bytes
+	// have been passed to the automaton.
+	buf []byte
+	n   int
+
+	// If end > 0, buf[start:end] is the leftmost longest match so far, which
+	// is to be replaced with repl.
+	start, end int
+	repl       string
+}
+
+func (t *trieReplacer) Reset() {
+	t.cur = trieState{buf: t.cur.buf[:0]}
+}
+
+// advance returns the node reached from q for r.
+func (t *trieReplacer) advance(q int32, r rune) int32 {
+	for {
+		if c, ok := t.nodes[q].next[r]; ok {
+			return c
+		}
+		if q == 0 {
+			return 0
+		}
+		q = t.nodes[q].fail
+	}
+}
+
+func (t *trieReplacer) Rewrite(s State) {
+	r, _ := s.ReadRune()
+
+	m := &t.next
+	buf := append(m.buf[:0], t.cur.buf...)
+	*m = t.cur
+	m.buf = utf8.AppendRune(buf, r)
+	out := t.scan(m, t.out[:0])
+	if len(m.buf) > 0 {
+		_, size := s.PeekRune()
+		if size == 0 && !s.IsAtEOF() {
+			return // PeekRune set ErrShortSrc.
+		}
+		if s.IsSpan() {
+			// Holding back input ends a span.
+			s.SetError(transform.ErrEndOfSpan)
+			return
+		}
+		if size == 0 {
+			out = t.flush(m, out)
+		}
+	}
+	t.out = out
+	if s.WriteBytes(out) {
+		t.cur, t.next = t.next, t.cur
+	}
+}
+
+// scan passes the remaining input in m.buf to the automaton and appends the
+// output that is no longer needed for matching to out.
+func (t *trieReplacer) scan(m *trieState, out []byte) []byte {
+	for m.n < len(m.buf) {
+		r, size := utf8.DecodeRune(m.buf[m.n:])
+		m.n += size
+		m.q = t.advance(m.q, r)
+
+		// The longest key ending here is the one that starts first.
+		if k := t.nodes[m.q].match; k != 0 {
+			start := m.n - t.nodes[k].depth
+			if m.end == 0 || start <= m.start {
+				m.start, m.end, m.repl = start, m.n, t.nodes[k].repl
+			}
+		}
+
+		// Any match that is yet to be completed starts at or after w.
+		w := m.n - t.nodes[m.q].depth
+		if m.end > 0 && w > m.start {
+			out = m.replace(out)
+		} else {
+			out = append(out, m.buf[:w]...)
+			m.shift(w)
+		}
+	}
+	return out
+}
+
+// flush appends the output for all remaining input to out.
+func (t *trieReplacer) flush(m *trieState, out []byte) []byte {
+	for m.end > 0 {
+		out = t.scan(m, m.replace(out))
+	}
+	out = append(out, m.buf...)
+	m.buf = m.buf[:0]
+	m.q, m.n = 0, 0
+	return out
+}
+
+// replace appends the input up to and including the replacement for the
+// current match to out and resets the automaton to rescan the input after
+// the match.
+func (m *trieState) replace(out []byte) []byte {
+	out = append(out, m.buf[:m.start]...)
+	out = append(out, m.repl...)
+	m.shift(m.end)
+	m.q, m.n, m.end = 0, 0, 0
+	return out
+}
+
+// shift removes the first n bytes from buf.
+func (m *trieState) shift(n int) {
+	m.buf = m.buf[:copy(m.buf, m.buf[n:])]
+	m.n -= n
+	if m.end > 0 {
+		m.start -= n
+		m.end -= n
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestTrieReplacer(t *testing.T) {
+	replace := func(table map[string]string) Transformer {
+		return NewTransformer(NewTrieReplacer(table))
+	}
+	spelling := replace(map[string]string{
+		"colour": "color",
+		"--":     "\u2014",
+		"...":    "\u2026",
+	})
+	overlap := replace(map[string]string{
+		"a":    "1",
+		"ab":   "2",
+		"abcd": "3",
+		"bc":   "4",
+		"c":    "5",
+	})
+
+	testCases := []transformTest{{
+		desc:    "replace",
+		szDst:   large,
+		atEOF:   true,
+		in:      "The colour -- red...",
+		out:     "The color \u2014 red\u2026",
+		outFull: "The color \u2014 red\u2026",
+		t:       spelling,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   4,
+	}, {
+		desc:    "no match",
+		szDst:   large,
+		atEOF:   true,
+		in:      "The colou in - .. col",
+		out:     "The colou in - .. col",
+		outFull: "The colou in - .. col",
+		t:       spelling,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   4,
+	}, {
+		desc:    "leftmost longest",
+		szDst:   large,
+		atEOF:   true,
+		in:      "xabcdxabcxbcxabxa",
+		out:     "x3x25x4x2x1",
+		outFull: "x3x25x4x2x1",
+		t:       overlap,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "rescan after failed extension",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "25",
+		outFull: "25",
+		t:       overlap,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "match split across buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a colo",
+		out:     "a ",
+		outFull: "a colo",
+		err:     transform.ErrShortSrc,
+		t:       spelling,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
+	}, {
+		desc:    "non-ASCII keys",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Gr\u00fc\u00dfe, Stra\u00dfe",
+		out:     "Gr\u00fcsse, Strasse",
+		outFull: "Gr\u00fcsse, Strasse",
+		t:       replace(map[string]string{"\u00df": "ss"}),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   4,
+	}, {
+		desc:    "short destination",
+		szDst:   4,
+		atEOF:   true,
+		in:      "ab--c",
+		out:     "ab",
+		outFull: "ab\u2014c",
+		err:     transform.ErrShortDst,
+		t:       spelling,
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+// replaceNaive replaces the leftmost longest matches of the keys of table in
+// s by trying all keys at each position.
+func replaceNaive(table map[string]string, s string) string {
+	var b strings.Builder
+	for s != "" {
+		n := 0
+		for k := range table {
+			if len(k) > n && strings.HasPrefix(s, k) {
+				n = len(k)
+			}
+		}
+		if n > 0 {
+			b.WriteString(table[s[:n]])
+			s = s[n:]
+		} else {
+			b.WriteByte(s[0])
+			s = s[1:]
+		}
+	}
+	return b.String()
+}
+
+func TestTrieReplacerRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randString := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = "abc"[rng.Intn(3)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 200; i++ {
+		table := map[string]string{}
+		for j := rng.Intn(6); j >= 0; j-- {
+			table[randString(1+rng.Intn(4))] = randString(rng.Intn(3))
+		}
+		tr := NewTransformer(NewTrieReplacer(table))
+		in := randString(rng.Intn(40))
+		want := replaceNaive(table, in)
+		if got := tr.String(in); got != want {
+			t.Errorf("%v:%q: got %q; want %q", table, in, got, want)
+		}
+		for _, sz := range [][2]int{{1, 8}, {3, 16}} {
+			if got, err := transformChunks(tr, in, sz[0], sz[1]); got != want || err != nil {
+				t.Errorf("%v:%q:%v: got %q, %v; want %q, <nil>", table, in, sz, got, err, want)
+			}
+		}
+	}
+}
+
+func TestTrieReplacerEmptyKey(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("did not panic")
+		}
+	}()
+	NewTrieReplacer(map[string]string{"": "x"})
+}
@@ -0,0 +1,89 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// ReverseGraphemeClusters returns a Transformer that reverses the order of the
+// grapheme clusters of its input, as determined by TruncateAtGrapheme. Unlike
+// reversing runes, this keeps combining marks with their base character and
+// emoji sequences intact. As the last cluster is only known at the end of
+// input, all input is buffered and written once atEOF is true.
+func ReverseGraphemeClusters() Transformer {
+	return Transformer{&reverseGraphemes{}}
+}
+
+type reverseGraphemes struct {
+	// buf holds the input read so far. Once the end of input is reached, it
+	// holds the reversed output, of which the first written bytes have been
+	// written.
+	buf      []byte
+	reversed bool
+	written  int
+}
+
+func (t *reverseGraphemes) Reset() {
+	t.buf, t.reversed, t.written = t.buf[:0], false, 0
+}
+
+func (t *reverseGraphemes) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if !t.reversed {
+		nSrc = len(src)
+		if !atEOF {
+			// Leave an incomplete rune at the end for the next call.
+			for i := 0; i < utf8.UTFMax && i < nSrc; i++ {
+				if utf8.RuneStart(src[nSrc-1-i]) {
+					if !utf8.FullRune(src[nSrc-1-i:]) {
+						nSrc -= i + 1
+						err = transform.ErrShortSrc
+					}
+					break
+				}
+			}
+			t.buf = append(t.buf, src[:nSrc]...)
+			return 0, nSrc, err
+		}
+		t.buf = reverseClusters(append(t.buf, src...))
+		t.reversed = true
+	}
+	nDst = copy(dst, t.buf[t.written:])
+	t.written += nDst
+	if t.written < len(t.buf) {
+		return nDst, nSrc, transform.ErrShortDst
+	}
+	return nDst, nSrc, nil
+}
+
+// reverseClusters returns the grapheme clusters of b in reverse order.
+func reverseClusters(b []byte) []byte {
+	out := make([]byte, len(b))
+	var state graphemeState
+	end := len(out)
+	start := 0 // start of the current cluster in b
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		var isBreak bool
+		if state, isBreak = state.next(r); isBreak && i > 0 {
+			end -= copy(out[end-(i-start):], b[start:i])
+			start = i
+		}
+		i += size
+	}
+	copy(out[:end], b[start:])
+	return out
+}
+
+// Span reports that the output differs from any non-empty input, as the
+// result cannot be determined for a prefix of the input.
+func (t *reverseGraphemes) Span(src []byte, atEOF bool) (n int, err error) {
+	if len(src) == 0 {
+		return 0, nil
+	}
+	return 0, transform.ErrEndOfSpan
+}
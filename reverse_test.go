@@ -0,0 +1,122 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestReverseGraphemeClusters(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "ASCII",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Hello, world",
+		out:     "dlrow ,olleH",
+		outFull: "dlrow ,olleH",
+		t:       ReverseGraphemeClusters(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "combining acute",
+		szDst:   large,
+		atEOF:   true,
+		in:      "cafe\u0301s",
+		out:     "se\u0301fac",
+		outFull: "se\u0301fac",
+		t:       ReverseGraphemeClusters(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "emoji modifier",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\U0001F44B\U0001F3FDb",
+		out:     "b\U0001F44B\U0001F3FDa",
+		outFull: "b\U0001F44B\U0001F3FDa",
+		t:       ReverseGraphemeClusters(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "ZWJ sequence",
+		szDst:   large,
+		atEOF:   true,
+		in:      "(\U0001F469\u200d\U0001F469\u200d\U0001F467)",
+		out:     ")\U0001F469\u200d\U0001F469\u200d\U0001F467(",
+		outFull: ")\U0001F469\u200d\U0001F469\u200d\U0001F467(",
+		t:       ReverseGraphemeClusters(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "CRLF",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\r\nb",
+		out:     "b\r\na",
+		outFull: "b\r\na",
+		t:       ReverseGraphemeClusters(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "more input",
+		szDst:   large,
+		atEOF:   false,
+		in:      "ab\xc3",
+		out:     "",
+		outFull: "\xc3ba",
+		err:     transform.ErrShortSrc,
+		t:       ReverseGraphemeClusters(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "abc",
+		out:     "cb",
+		outFull: "cba",
+		err:     transform.ErrShortDst,
+		t:       ReverseGraphemeClusters(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "empty",
+		szDst:   large,
+		atEOF:   true,
+		in:      "",
+		out:     "",
+		outFull: "",
+		t:       ReverseGraphemeClusters(),
+	}}
+	for i, tt := range testCases {
+		tt.t.Reset()
+		tt.check(t, i)
+	}
+}
+
+func TestReverseGraphemeClustersChunks(t *testing.T) {
+	tr := ReverseGraphemeClusters()
+	dst := make([]byte, large)
+
+	// The combining mark arrives in the second chunk.
+	nDst, nSrc, err := tr.Transform(dst, []byte("abe"), false)
+	if nDst != 0 || nSrc != 3 || err != nil {
+		t.Fatalf("got %d, %d, %v; want 0, 3, <nil>", nDst, nSrc, err)
+	}
+	nDst, nSrc, err = tr.Transform(dst, []byte("\u0301c"), true)
+	if got, want := string(dst[:nDst]), "ce\u0301ba"; got != want || err != nil {
+		t.Errorf("got %+q, %v; want %+q, <nil>", got, err, want)
+	}
+
+	// Reset clears the buffer.
+	tr.Reset()
+	nDst, _, _ = tr.Transform(dst, []byte("xy"), true)
+	if got, want := string(dst[:nDst]), "yx"; got != want {
+		t.Errorf("after Reset: got %q; want %q", got, want)
+	}
+
+	// Input spanning the internal buffers of transform.String.
+	in := strings.Repeat("e\u0301x", 200)
+	want := strings.Repeat("xe\u0301", 200)
+	if got := ReverseGraphemeClusters().String(in); got != want {
+		t.Errorf("got %+q; want %+q", got, want)
+	}
+}
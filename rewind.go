@@ -0,0 +1,67 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "golang.org/x/text/transform"
+
+// A RewindableState is a State that can return to an earlier point in the
+// current call to Rewrite. It allows a Rewriter to attempt several
+// alternatives of arbitrary length, where UnreadRune only allows going back a
+// few runes.
+type RewindableState interface {
+	State
+
+	// Mark returns a Token for the current read and write positions.
+	Mark() Token
+
+	// Rollback returns to the positions recorded by the given Token,
+	// discarding the runes read and written since. Errors set since are
+	// cleared as well, except for ErrShortSrc: an attempt that ran out of
+	// input may yet succeed once more input is available. Rollback panics if
+	// the Token was obtained before the last call to Commit.
+	Rollback(t Token)
+
+	// Commit accepts all reads and writes so far, invalidating all
+	// outstanding Tokens.
+	Commit()
+}
+
+// A Token marks a position in a RewindableState.
+type Token struct {
+	s   spanState
+	gen int
+}
+
+// NewRewindableState returns a RewindableState that reads from and writes to
+// s. The Tokens of a RewindableState are only valid during the call to Rewrite
+// to which s was passed. Reads and writes are tentative until Rewrite returns,
+// as with any State, so there is no need to Commit before returning.
+func NewRewindableState(s State) RewindableState {
+	b, _ := s.base()
+	return &rewindableState{State: s, b: b}
+}
+
+type rewindableState struct {
+	State
+	b   *spanState
+	gen int
+}
+
+func (s *rewindableState) Mark() Token {
+	return Token{s: *s.b, gen: s.gen}
+}
+
+func (s *rewindableState) Rollback(t Token) {
+	if t.gen != s.gen {
+		panic("textutil: Rollback called with committed Token")
+	}
+	shortSrc := s.b.shortSrc
+	*s.b = t.s
+	if shortSrc {
+		s.b.SetError(transform.ErrShortSrc)
+	}
+}
+
+func (s *rewindableState) Commit() { s.gen++ }
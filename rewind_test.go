@@ -0,0 +1,124 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+var arrows = []struct {
+	in  string
+	out rune
+}{
+	{"<=>", '\u21d4'},
+	{"<=", '\u21d0'},
+}
+
+// rwArrows replaces "<=>" and "<=" with arrows. It writes the arrow before
+// verifying the input to exercise the rollback of writes.
+func rwArrows(s State) {
+	rs := NewRewindableState(s)
+	m := rs.Mark()
+	for _, a := range arrows {
+		rs.WriteRune(a.out)
+		if readString(rs, a.in) {
+			return
+		}
+		rs.Rollback(m)
+	}
+	r, _ := rs.ReadRune()
+	rs.WriteRune(r)
+}
+
+// readString reads str from s and reports whether it matched the input.
+func readString(s State, str string) bool {
+	for _, want := range str {
+		if r, size := s.ReadRune(); r != want || size == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRewindableState(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "first alternative",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a<=>b",
+		out:     "a\u21d4b",
+		outFull: "a\u21d4b",
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "second alternative",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a<=b<=",
+		out:     "a\u21d0b\u21d0",
+		outFull: "a\u21d0b\u21d0",
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no match",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a<b=>",
+		out:     "a<b=>",
+		outFull: "a<b=>",
+	}, {
+		desc:    "incomplete input",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a<=",
+		out:     "a",
+		outFull: "a\u21d0",
+		err:     transform.ErrShortSrc,
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "a<=b",
+		out:     "a",
+		outFull: "a\u21d0b",
+		err:     transform.ErrShortDst,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}}
+	for i, tt := range testCases {
+		tt.t = NewTransformerFromFunc(rwArrows)
+		tt.check(t, i)
+	}
+
+	in := strings.Repeat("<=><=<<==>=>", 3)
+	want := strings.NewReplacer("<=>", "\u21d4", "<=", "\u21d0").Replace(in)
+	tr := NewTransformerFromFunc(rwArrows)
+	for szSrc := 1; szSrc < 5; szSrc++ {
+		got, err := transformChunks(tr, in, szSrc, 3)
+		if got != want || err != nil {
+			t.Errorf("%d: got %q, %v; want %q, <nil>", szSrc, got, err, want)
+		}
+	}
+}
+
+func TestRewindableStateCommit(t *testing.T) {
+	var msg interface{}
+	tr := NewTransformerFromFunc(func(s State) {
+		rs := NewRewindableState(s)
+		m := rs.Mark()
+		r, _ := rs.ReadRune()
+		rs.WriteRune(r)
+		rs.Commit()
+		defer func() { msg = recover() }()
+		rs.Rollback(m)
+	})
+	tr.String("a")
+	if msg == nil {
+		t.Error("Rollback with committed Token did not panic")
+	}
+}
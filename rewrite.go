@@ -5,6 +5,9 @@
 package textutil
 
 import (
+	"context"
+	"errors"
+	"sync"
 	"unicode/utf8"
 
 	"golang.org/x/text/transform"
@@ -15,6 +18,56 @@ type rewriterFunc func(s State)
 
 func (r rewriterFunc) Rewrite(s State) { r(s) }
 func (r rewriterFunc) Reset()          {}
+func (r rewriterFunc) Clone() Rewriter { return r }
+
+// NilRewriter is a stateless Rewriter that writes each rune of its input
+// unchanged, other than replacing invalid UTF-8 with U+FFFD. It may serve as a
+// placeholder where a Rewriter is required but no rewriting should take place.
+var NilRewriter Rewriter = rewriterFunc(func(s State) {
+	r, _ := s.ReadRune()
+	s.WriteRune(r)
+})
+
+// NewStatefulRewriterFunc returns a Rewriter that calls rewrite for each call
+// to Rewrite and reset for each call to Reset. It allows a Rewriter that keeps
+// state in variables captured by the closures to be defined without declaring
+// a type. The functions must follow the same guidelines as the corresponding
+// methods of Rewriter.
+func NewStatefulRewriterFunc(rewrite func(State), reset func()) Rewriter {
+	return &statefulRewriterFunc{rewrite, reset}
+}
+
+type statefulRewriterFunc struct {
+	rewrite func(State)
+	reset   func()
+}
+
+func (r *statefulRewriterFunc) Rewrite(s State) { r.rewrite(s) }
+func (r *statefulRewriterFunc) Reset()          { r.reset() }
+
+// NewConditionalRewriter returns a Rewriter that calls r.Rewrite if the next
+// rune satisfies pred and copies the rune otherwise. The rune is not consumed
+// before r is called, so r reads it as the first rune and may unread it as
+// usual. Reset calls r.Reset.
+func NewConditionalRewriter(pred func(rune) bool, r Rewriter) Rewriter {
+	return &conditionalRewriter{pred, r}
+}
+
+type conditionalRewriter struct {
+	pred func(rune) bool
+	r    Rewriter
+}
+
+func (c *conditionalRewriter) Rewrite(s State) {
+	if r, _ := s.PeekRune(); c.pred(r) {
+		c.r.Rewrite(s)
+		return
+	}
+	r, _ := s.ReadRune()
+	s.WriteRune(r)
+}
+
+func (c *conditionalRewriter) Reset() { c.r.Reset() }
 
 // A Rewriter rewrites UTF-8 bytes.
 type Rewriter interface {
@@ -30,6 +83,15 @@ type Rewriter interface {
 	Reset()
 }
 
+// A Cloner is a Rewriter that can be copied, as needed by Transformer.Clone.
+type Cloner interface {
+	Rewriter
+
+	// Clone returns a new Rewriter with a copy of the state of the Rewriter.
+	// Subsequent calls to either Rewriter must not affect the other.
+	Clone() Rewriter
+}
+
 // NewTransformer returns a Transformer that uses the given Rewriter to
 // transform input by repeatedly calling Rewrite until all input has been
 // processed or an error is encountered.
@@ -37,6 +99,80 @@ func NewTransformer(r Rewriter) Transformer {
 	return Transformer{&rewriter{rewrite: r}}
 }
 
+// NewTransformerWithOptions returns a Transformer like NewTransformer,
+// configured by the given options.
+func NewTransformerWithOptions(r Rewriter, opts ...Option) Transformer {
+	t := &rewriter{rewrite: r}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return Transformer{t}
+}
+
+// An Option configures a Transformer created by NewTransformerWithOptions.
+type Option func(t *rewriter)
+
+// An InvalidUTF8Policy determines how a Transformer created from a Rewriter
+// handles invalid UTF-8 in its input.
+type InvalidUTF8Policy int
+
+const (
+	// ReplaceInvalidUTF8 passes each invalid byte to the Rewriter as
+	// utf8.RuneError, which is typically written as U+FFFD. This is the
+	// default.
+	ReplaceInvalidUTF8 InvalidUTF8Policy = iota
+
+	// SkipInvalidUTF8 discards invalid bytes without passing them to the
+	// Rewriter.
+	SkipInvalidUTF8
+
+	// ErrorOnInvalidUTF8 stops the transformation with ErrInvalidUTF8 at the
+	// first invalid byte read by the Rewriter.
+	ErrorOnInvalidUTF8
+)
+
+// ErrInvalidUTF8 is reported by Transformers using ErrorOnInvalidUTF8 for
+// input that is not valid UTF-8.
+var ErrInvalidUTF8 = errors.New("textutil: invalid UTF-8")
+
+// InvalidUTF8 returns an Option that sets the policy for invalid UTF-8. An
+// incomplete rune at the end of the input is invalid as well. The policy
+// applies to the runes read by ReadRune, PeekRune and Skip of the State passed
+// to the Rewriter, in both Transform and Span.
+func InvalidUTF8(policy InvalidUTF8Policy) Option {
+	return func(t *rewriter) { t.invalid = policy }
+}
+
+// NewContextTransformer returns a Transformer like NewTransformer that stops
+// with ctx.Err() once ctx is done. The context is checked before each call to
+// Rewrite, so the Transformer returns the output for all input rewritten
+// before the cancellation with the error. It is equivalent to calling
+// WithContext on the result of NewTransformer.
+func NewContextTransformer(ctx context.Context, r Rewriter) Transformer {
+	return Transformer{&rewriter{rewrite: r, ctx: ctx}}
+}
+
+// NewPooledTransformer returns a function that provides a Transformer for a
+// Rewriter created by newRewriter, along with a function to release it. Released
+// Transformers are kept in a sync.Pool for reuse by later calls, avoiding an
+// allocation for each Transformer if they are created and discarded at a high
+// rate. The Rewriter is reset when its Transformer is provided. A Transformer
+// must not be used after it is released.
+func NewPooledTransformer(newRewriter func() Rewriter) func() (t Transformer, release func()) {
+	pool := &sync.Pool{New: func() interface{} {
+		return &rewriter{rewrite: newRewriter()}
+	}}
+	return func() (Transformer, func()) {
+		r := pool.Get().(*rewriter)
+		r.Reset()
+		return Transformer{r}, func() {
+			// Do not hold on to the buffers of the last call to Transform.
+			r.state = state{}
+			pool.Put(r)
+		}
+	}
+}
+
 // NewTransformerFromFunc calls NewTransform with a stateless Rewriter created
 // from rewrite, which must follow the same guidelines as the Rewrite method of
 // a Rewriter.
@@ -49,20 +185,65 @@ func NewTransformerFromFunc(rewrite func(State)) Transformer {
 type rewriter struct {
 	rewrite Rewriter
 
+	// ctx, if not nil, is checked for cancellation before each call to
+	// Rewrite in Transform.
+	ctx context.Context
+
+	// offset is the number of bytes of input consumed by Transform since the
+	// last Reset.
+	offset int
+
+	invalid InvalidUTF8Policy
+
 	state state
 }
 
-func (t *rewriter) Reset() { t.rewrite.Reset() }
+func (t *rewriter) Reset() {
+	t.rewrite.Reset()
+	t.offset = 0
+}
 
+// Transform implements transform.Transformer. Errors set by the Rewriter,
+// other than transform.ErrShortDst and transform.ErrShortSrc, are reported
+// as a *TextError.
 func (t *rewriter) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
-	t.state = state{dst: dst, spanState: spanState{src: src, atEOF: atEOF}}
+	nDst, nSrc, err = t.transform(dst, src, atEOF)
+	if err != nil && err != transform.ErrShortDst && err != transform.ErrShortSrc && !t.isContextErr(err) {
+		err = newTextError(err, t.offset+nSrc, src, nSrc)
+	}
+	t.offset += nSrc
+	return nDst, nSrc, err
+}
+
+func (t *rewriter) isContextErr(err error) bool {
+	return t.ctx != nil && err == t.ctx.Err()
+}
+
+// transform is like Transform, but returns errors as set by the Rewriter.
+func (t *rewriter) transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	t.state = state{dst: dst, spanState: spanState{src: src, atEOF: atEOF, invalid: t.invalid}}
 	s := &t.state
 
 	for s.pSrc < len(src) {
+		if t.ctx != nil {
+			select {
+			case <-t.ctx.Done():
+				return nDst, nSrc, t.ctx.Err()
+			default:
+			}
+		}
+		if t.invalid == SkipInvalidUTF8 {
+			if n := invalidPrefix(src[s.pSrc:], atEOF); n > 0 {
+				s.pSrc += n
+				nSrc = s.pSrc
+				continue
+			}
+		}
 		if !atEOF && !utf8.FullRune(src[s.pSrc:]) {
 			return nDst, nSrc, transform.ErrShortSrc
 		}
 
+		s.nPrev, s.pSrcStart, s.pDstStart = 0, s.pSrc, s.pDst
 		if t.rewrite.Rewrite(s); s.err != nil {
 			return nDst, nSrc, s.err
 		}
@@ -73,17 +254,27 @@ func (t *rewriter) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err e
 }
 
 func (t *rewriter) Span(src []byte, atEOF bool) (nSrc int, err error) {
-	t.state.spanState = spanState{src: src, atEOF: atEOF}
+	t.state.spanState = spanState{src: src, atEOF: atEOF, invalid: t.invalid}
 	s := &t.state.spanState
 
 	for s.pSrc < len(src) {
+		if t.invalid == SkipInvalidUTF8 && invalidPrefix(src[s.pSrc:], atEOF) > 0 {
+			// Removing bytes ends the span.
+			return nSrc, transform.ErrEndOfSpan
+		}
 		if !atEOF && !utf8.FullRune(src[s.pSrc:]) {
 			return nSrc, transform.ErrShortSrc
 		}
 
+		s.nPrev, s.pSrcStart, s.pDstStart = 0, s.pSrc, s.pDst
 		if t.rewrite.Rewrite(s); s.err != nil {
 			return nSrc, s.err
 		}
+		// The output differs from the input if the number of bytes written
+		// does not match the number of bytes read.
+		if s.pDst != s.pSrc {
+			return nSrc, transform.ErrEndOfSpan
+		}
 		// Checkpoint the progress.
 		nSrc = s.pSrc
 	}
@@ -92,15 +283,65 @@ func (t *rewriter) Span(src []byte, atEOF bool) (nSrc int, err error) {
 
 // State tracks the transformation of a minimal chunk of input. Reads and writes
 // on a State will either be committed in full or not at all.
+//
+// A State can only be implemented outside this package by embedding a State
+// passed to Rewrite, which allows wrapping the State passed to a Rewriter
+// before passing it on to another.
 type State interface {
 	// ReadRune returns the next rune from the source and the number of bytes
-	// consumed. It returns (RuneError, 1) for Invalid UTF-8 bytes. If the
+	// consumed. It returns (RuneError, 1) for Invalid UTF-8 bytes, unless the
+	// Transformer was configured with a different InvalidUTF8Policy. If the
 	// source buffer is empty, it will return (RuneError, 0).
 	ReadRune() (r rune, size int)
 
-	// UnreadRune unreads the most recently read rune and makes it available for
-	// a next call to Rewrite. Only one call to UnreadRune is allowed per
-	// Rewrite.
+	// PeekRune returns the next rune from the source and its size like
+	// ReadRune, but without consuming it. It does not affect a subsequent call
+	// to UnreadRune. As with ReadRune, a size of 0 indicates the end of the
+	// source buffer, in which case ErrShortSrc is set if more input may follow.
+	PeekRune() (r rune, size int)
+
+	// Skip reads the next rune from the source, like ReadRune, and discards
+	// it. Unlike reading a rune without writing it, Skip makes explicit that
+	// the output differs from the input.
+	Skip()
+
+	// Available returns the number of bytes of the source buffer that have not
+	// yet been read. If more input may follow the buffer, this is not the
+	// number of bytes remaining in the input: a result of 0 then only means
+	// that the next call to ReadRune will set ErrShortSrc.
+	Available() int
+
+	// IsAtEOF reports whether the end of the source buffer is the end of the
+	// input. If it returns false, more input may follow.
+	IsAtEOF() bool
+
+	// Written returns the number of bytes written so far in the current call
+	// to Rewrite.
+	Written() int
+
+	// Consumed returns the bytes of the source read so far in the current call
+	// to Rewrite, including invalid UTF-8 as is. The result must not be
+	// modified and is only valid during the call.
+	Consumed() []byte
+
+	// Output returns the bytes written so far in the current call to Rewrite.
+	// The result must not be modified and is only valid during the call.
+	Output() []byte
+
+	// IsSpan reports whether the State is used by the Span method of a
+	// Transformer. Only writes that reproduce the input then succeed. A
+	// Rewriter that would consume input without writing it all, keeping the
+	// rest in its own state, must instead set transform.ErrEndOfSpan before
+	// changing its state, as Transform is called for the same input next.
+	IsSpan() bool
+
+	// Err returns the first error set in the current call to Rewrite, if any.
+	Err() error
+
+	// UnreadRune unreads the most recently read rune that has not yet been
+	// unread and makes it available for a next call to ReadRune or Rewrite.
+	// Up to four consecutive calls to UnreadRune are allowed; UnreadRune
+	// panics if it is called more often than ReadRune in the same Rewrite.
 	UnreadRune()
 
 	// WriteBytes writes the given byte slice to the destination and reports
@@ -115,70 +356,178 @@ type State interface {
 	// the write was successful.
 	WriteRune(r rune) bool
 
+	// WriteRuneN writes n copies of the given rune to the destination and
+	// reports whether the write was successful. Nothing is written if not all
+	// copies fit.
+	WriteRuneN(r rune, n int) bool
+
 	// Write implements io.Writer. The user is advised to use WriteBytes when
 	// conformance to io.Writer is not needed.
 	Write(b []byte) (n int, err error)
 
 	// SetError reports invalid source bytes.
 	SetError(err error)
+
+	// base returns the spanState underlying the State and, if it writes to a
+	// destination buffer, the state.
+	base() (*spanState, *state)
 }
 
+// maxUnread is the maximum number of consecutive calls to UnreadRune. It is
+// documented in State.UnreadRune.
+const maxUnread = 4
+
 // A spanState is passed to a Rewriter for reading from and writing to the source
 // and destination buffers.
 type spanState struct {
-	err         error
-	pDst, pSrc  int
-	src         []byte
-	atEOF       bool
-	readPastEnd bool // Used for UnreadRune.
+	err        error
+	pDst, pSrc int
+	pSrcStart  int // value of pSrc at the start of the Rewrite call
+	pDstStart  int // value of pDst at the start of the Rewrite call
+	src        []byte
+	atEOF      bool
+	invalid    InvalidUTF8Policy
+
+	// prev is a ring buffer holding the values of pSrc before the last nPrev
+	// calls to ReadRune, the most recent of which is at prev[top-1].
+	prev  [maxUnread]int
+	top   int
+	nPrev int
+
+	// shortSrc is set if ErrShortSrc was set, even if another error was set
+	// first. It allows a RewindableState to preserve ErrShortSrc on Rollback.
+	shortSrc bool
 }
 
 func (s *spanState) SetError(err error) {
 	if s.err == nil {
 		s.err = err
 	}
+	if err == transform.ErrShortSrc {
+		s.shortSrc = true
+	}
 }
 
 func (s *spanState) ReadRune() (r rune, size int) {
-	// TODO: ASCII fast path.
-	r, size = utf8.DecodeRune(s.src[s.pSrc:])
-	if r == utf8.RuneError && size <= 1 {
-		s.readPastEnd = size == 0
-		if !s.atEOF && !utf8.FullRune(s.src[s.pSrc:]) {
+	s.prev[s.top] = s.pSrc
+	s.top = (s.top + 1) % maxUnread
+	if s.nPrev < maxUnread {
+		s.nPrev++
+	}
+	if s.pSrc < len(s.src) && s.src[s.pSrc] < utf8.RuneSelf {
+		r = rune(s.src[s.pSrc])
+		s.pSrc++
+		return r, 1
+	}
+	r, size = s.decodeRune()
+	s.pSrc += size
+	return
+}
+
+func (s *spanState) PeekRune() (r rune, size int) {
+	if s.pSrc < len(s.src) && s.src[s.pSrc] < utf8.RuneSelf {
+		return rune(s.src[s.pSrc]), 1
+	}
+	return s.decodeRune()
+}
+
+// decodeRune decodes the next rune for ReadRune and PeekRune, applying the
+// policy for invalid UTF-8.
+func (s *spanState) decodeRune() (r rune, size int) {
+	src := s.src[s.pSrc:]
+	r, size = utf8.DecodeRune(src)
+	if r != utf8.RuneError || size > 1 {
+		return r, size
+	}
+	if !s.atEOF && !utf8.FullRune(src) {
+		s.SetError(transform.ErrShortSrc)
+		return r, 0
+	}
+	if size == 0 {
+		return r, 0
+	}
+	switch s.invalid {
+	case ErrorOnInvalidUTF8:
+		s.SetError(ErrInvalidUTF8)
+	case SkipInvalidUTF8:
+		n := invalidPrefix(src, s.atEOF)
+		if n == len(src) {
+			// Only invalid bytes remain, which the Transformer skips before
+			// calling Rewrite again.
+			if !s.atEOF {
+				s.SetError(transform.ErrShortSrc)
+			}
+			return utf8.RuneError, 0
+		}
+		r, size = utf8.DecodeRune(src[n:])
+		if r == utf8.RuneError && size <= 1 {
 			s.SetError(transform.ErrShortSrc)
 			return r, 0
 		}
+		return r, n + size
 	}
-	s.pSrc += size
-	return
+	return r, size
 }
 
-func (s *spanState) UnreadRune() {
-	if s.readPastEnd {
-		return
+// invalidPrefix returns the number of invalid UTF-8 bytes at the start of src.
+// If atEOF is false, an incomplete rune at the end of src does not count as
+// invalid.
+func invalidPrefix(src []byte, atEOF bool) (n int) {
+	for n < len(src) {
+		r, size := utf8.DecodeRune(src[n:])
+		if r != utf8.RuneError || size != 1 || !atEOF && !utf8.FullRune(src[n:]) {
+			break
+		}
+		n++
 	}
-	if s.pSrc == 0 {
-		panic("Unread called without any prior input read.")
+	return n
+}
+
+func (s *spanState) Skip() {
+	// Removing a rune ends the span.
+	if _, size := s.ReadRune(); size > 0 {
+		s.SetError(transform.ErrEndOfSpan)
 	}
-	_, sz := utf8.DecodeLastRune(s.src[:s.pSrc])
-	s.pSrc -= sz
-	return
+}
+
+func (s *spanState) Available() int { return len(s.src) - s.pSrc }
+
+func (s *spanState) IsAtEOF() bool { return s.atEOF }
+
+func (s *spanState) Written() int { return s.pDst - s.pDstStart }
+
+func (s *spanState) Consumed() []byte { return s.src[s.pSrcStart:s.pSrc] }
+
+// Output returns the written bytes, which equal the source bytes at the same
+// position in a Span.
+func (s *spanState) Output() []byte { return s.src[s.pDstStart:s.pDst] }
+
+func (s *spanState) IsSpan() bool { return true }
+
+func (s *spanState) Err() error { return s.err }
+
+func (s *spanState) base() (*spanState, *state) { return s, nil }
+
+func (s *spanState) UnreadRune() {
+	if s.nPrev == 0 {
+		panic("textutil: UnreadRune called without a matching call to ReadRune")
+	}
+	s.top = (s.top + maxUnread - 1) % maxUnread
+	s.pSrc = s.prev[s.top]
+	s.nPrev--
 }
 
 func (s *spanState) Write(b []byte) (n int, err error) {
-	if max := len(s.src) - s.pDst; len(b) > max {
-		b = b[:max]
-	}
-	for i, c := range s.src[s.pDst : s.pDst+len(b)] {
-		if b[i] != c {
-			if s.err == nil {
-				s.err = transform.ErrEndOfSpan
-				return i, s.err
-			}
+	src := s.src[s.pDst:]
+	for ; n < len(b); n++ {
+		// Writing past the end of the source also ends the span.
+		if n == len(src) || b[n] != src[n] {
+			s.SetError(transform.ErrEndOfSpan)
+			return n, transform.ErrEndOfSpan
 		}
 	}
-	s.pDst += len(b)
-	return len(b), nil
+	s.pDst += n
+	return n, nil
 }
 
 func (s *spanState) WriteBytes(b []byte) bool {
@@ -187,29 +536,52 @@ func (s *spanState) WriteBytes(b []byte) bool {
 }
 
 func (s *spanState) WriteString(str string) bool {
-	if max := len(s.src) - s.pDst; len(str) > max {
-		str = str[:max]
-	}
-	for i, c := range s.src[s.pDst : s.pDst+len(str)] {
-		if str[i] != c {
-			if s.err == nil {
-				s.err = transform.ErrEndOfSpan
-				return true
-			}
+	src := s.src[s.pDst:]
+	for i := 0; i < len(str); i++ {
+		if i == len(src) || str[i] != src[i] {
+			s.SetError(transform.ErrEndOfSpan)
+			return false
 		}
 	}
 	s.pDst += len(str)
-	return false
+	return true
 }
 
 func (s *spanState) WriteRune(r rune) bool {
-	// TODO: ASCII fast path and other optimizations.
+	if uint32(r) < utf8.RuneSelf {
+		if s.pDst == len(s.src) || s.src[s.pDst] != byte(r) {
+			s.SetError(transform.ErrEndOfSpan)
+			return false
+		}
+		s.pDst++
+		return true
+	}
 	var b [utf8.UTFMax]byte
 	sz := utf8.EncodeRune(b[:], r)
 	_, err := s.Write(b[:sz])
 	return err == nil
 }
 
+func (s *spanState) WriteRuneN(r rune, n int) bool {
+	var b [utf8.UTFMax]byte
+	sz := utf8.EncodeRune(b[:], r)
+	src := s.src[s.pDst:]
+	if n*sz > len(src) {
+		s.SetError(transform.ErrEndOfSpan)
+		return false
+	}
+	for i := 0; i < n*sz; i += sz {
+		if string(src[i:i+sz]) != string(b[:sz]) {
+			s.SetError(transform.ErrEndOfSpan)
+			return false
+		}
+	}
+	if n > 0 {
+		s.pDst += n * sz
+	}
+	return true
+}
+
 // A state is passed to a Rewriter for reading from and writing to the source
 // and destination buffers.
 type state struct {
@@ -217,6 +589,34 @@ type state struct {
 	dst []byte
 }
 
+func (s *state) WriteRuneN(r rune, n int) bool {
+	if n <= 0 {
+		return true
+	}
+	var b [utf8.UTFMax]byte
+	sz := utf8.EncodeRune(b[:], r)
+	dst := s.dst[s.pDst:]
+	if n*sz > len(dst) {
+		s.SetError(transform.ErrShortDst)
+		return false
+	}
+	// Fill dst by doubling the encoded copies.
+	dst = dst[:n*sz]
+	for i := copy(dst, b[:sz]); i < len(dst); i *= 2 {
+		copy(dst[i:], dst[:i])
+	}
+	s.pDst += len(dst)
+	return true
+}
+
+func (s *state) Skip() { s.ReadRune() }
+
+func (s *state) Output() []byte { return s.dst[s.pDstStart:s.pDst] }
+
+func (s *state) IsSpan() bool { return false }
+
+func (s *state) base() (*spanState, *state) { return &s.spanState, s }
+
 func (s *state) Write(b []byte) (n int, err error) {
 	if copy(s.dst[s.pDst:], b) != len(b) {
 		s.SetError(transform.ErrShortDst)
@@ -245,7 +645,15 @@ func (s *state) WriteString(str string) bool {
 }
 
 func (s *state) WriteRune(r rune) bool {
-	// TODO: ASCII fast path and other optimizations.
+	if uint32(r) < utf8.RuneSelf {
+		if s.pDst == len(s.dst) {
+			s.SetError(transform.ErrShortDst)
+			return false
+		}
+		s.dst[s.pDst] = byte(r)
+		s.pDst++
+		return true
+	}
 	var b [utf8.UTFMax]byte
 	n := utf8.EncodeRune(b[:], r)
 	if copy(s.dst[s.pDst:], b[:n]) != n {
@@ -5,9 +5,13 @@
 package textutil
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"strings"
 	"testing"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/transform"
 )
@@ -22,6 +26,38 @@ func (rwCopy) Rewrite(s State) {
 	s.WriteRune(r)
 }
 
+// rwCollapse collapses runs of identical runes.
+func rwCollapse(s State) {
+	r, _ := s.ReadRune()
+	if p, size := s.PeekRune(); size == 0 || p != r {
+		s.WriteRune(r)
+	}
+}
+
+// rwSkipDigits removes ASCII digits.
+func rwSkipDigits(s State) {
+	if r, _ := s.PeekRune(); '0' <= r && r <= '9' {
+		s.Skip()
+		return
+	}
+	r, _ := s.ReadRune()
+	s.WriteRune(r)
+}
+
+// rwRuns copies runs of identical runes, writing each run at once.
+func rwRuns(s State) {
+	r, _ := s.ReadRune()
+	n := 1
+	for {
+		if next, size := s.PeekRune(); size == 0 || next != r {
+			break
+		}
+		s.ReadRune()
+		n++
+	}
+	s.WriteRuneN(r, n)
+}
+
 // rwReplaceAll rewrites all incoming runes to 'a'.
 type rwReplaceAll struct{}
 
@@ -50,11 +86,19 @@ func rwLast(f func(State) bool) transform.SpanningTransformer {
 }
 
 func TestRewriteMain(t *testing.T) {
+	for i, tt := range rewriteMainTests(t) {
+		tt.check(t, i)
+	}
+}
+
+// rewriteMainTests returns the test cases of TestRewriteMain, which also seed
+// the fuzz tests.
+func rewriteMainTests(t testing.TB) []transformTest {
 	var myError = errors.New("my error")
 
 	const large = 1000
 
-	testCases := []transformTest{{
+	return []transformTest{{
 		desc:  "Don't call with empty input.",
 		szDst: large,
 		atEOF: true,
@@ -166,6 +210,94 @@ func TestRewriteMain(t *testing.T) {
 			s.UnreadRune()
 		}),
 		nSpan: len("a\u0300\u2208\U0001030fx"),
+	}, {
+		desc:    "PeekRune.",
+		szDst:   large,
+		atEOF:   true,
+		in:      "baa\u2208\u2208\u2208c\x80\x80",
+		out:     "ba\u2208c\ufffd",
+		outFull: "ba\u2208c\ufffd",
+		t:       rw(rwCollapse),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "PeekRune at end of buffer.",
+		szDst:   large,
+		atEOF:   false,
+		in:      "ab\xcc",
+		out:     "a",
+		outFull: "ab\ufffd",
+		err:     transform.ErrShortSrc,
+		t:       rw(rwCollapse),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "PeekRune at end of input.",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u0300",
+		out:     "a\u0300",
+		outFull: "a\u0300",
+		t: rw(func(s State) {
+			r, _ := s.ReadRune()
+			s.WriteRune(r)
+			// PeekRune should not consume the unread slot.
+			if p, _ := s.PeekRune(); p != utf8.RuneError {
+				if q, _ := s.ReadRune(); q != p {
+					t.Errorf("ReadRune: got %U; want %U", q, p)
+				}
+				s.UnreadRune()
+			} else if _, size := s.PeekRune(); size != 0 {
+				t.Errorf("PeekRune at end: got size %d; want 0", size)
+			}
+		}),
+	}, {
+		desc:    "Available.",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ab\u2208c\u2208",
+		out:     "ab\u2208c*",
+		outFull: "ab\u2208c*",
+		t: rw(func(s State) {
+			n := s.Available()
+			r, size := s.ReadRune()
+			if s.Available() != n-size {
+				t.Errorf("Available: got %d; want %d", s.Available(), n-size)
+			}
+			if s.Available() == 0 {
+				r = '*'
+			}
+			s.WriteRune(r)
+		}),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "Skip all.",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ab\u2208",
+		out:     "",
+		outFull: "",
+		t:       rw(func(s State) { s.Skip() }),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "Skip some.",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u2208b1c",
+		out:     "a\u2208bc",
+		outFull: "a\u2208bc",
+		t:       rw(rwSkipDigits),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "Skip at end of buffer.",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a\xe2\x88",
+		out:     "a",
+		outFull: "a\ufffd\ufffd",
+		err:     transform.ErrShortSrc,
+		t:       rw(rwSkipDigits),
+		errSpan: transform.ErrShortSrc,
 	}, {
 		desc:    "WriteRune, return value.",
 		szDst:   5,
@@ -205,6 +337,46 @@ func TestRewriteMain(t *testing.T) {
 			return s.WriteBytes([]byte(string(r)))
 		}),
 		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "WriteRuneN, return value.",
+		szDst:   8,
+		atEOF:   true,
+		in:      "a\u0300bx",
+		out:     "FaaT\u0300\u0300",
+		outFull: "FaaT\u0300\u0300FbbTxx",
+		err:     transform.ErrShortDst,
+		t: rwLast(func(s State) bool {
+			r, _ := s.ReadRune()
+			return s.WriteRuneN(r, 2)
+		}),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "WriteRuneN, span.",
+		szDst:   large,
+		atEOF:   true,
+		in:      "aaa\u2208\u2208\u2208\u2208b",
+		out:     "aaa\u2208\u2208\u2208\u2208b",
+		outFull: "aaa\u2208\u2208\u2208\u2208b",
+		t:       rw(rwRuns),
+	}, {
+		desc:    "WriteRuneN, end of span.",
+		szDst:   large,
+		atEOF:   true,
+		in:      "aa\u2208\u2208\u2208",
+		out:     "aa\u2208\u2208\u2208\u2208",
+		outFull: "aa\u2208\u2208\u2208\u2208",
+		t: rw(func(s State) {
+			r, _ := s.ReadRune()
+			if r != 'a' {
+				s.ReadRune()
+				s.ReadRune()
+				s.WriteRuneN(r, 4)
+				return
+			}
+			s.WriteRuneN(r, 1)
+		}),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   2,
 	}, {
 		desc:    "Write, size return value.",
 		szDst:   6,
@@ -271,10 +443,432 @@ func TestRewriteMain(t *testing.T) {
 		}),
 		errSpan: transform.ErrEndOfSpan,
 		nSpan:   len("123123"),
+	}, {
+		desc:    "IsAtEOF",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ab",
+		out:     "ab.",
+		outFull: "ab.",
+		t:       rw(rwTerminate),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "IsAtEOF, more input may follow",
+		szDst:   large,
+		atEOF:   false,
+		in:      "ab",
+		out:     "ab",
+		outFull: "ab", // Rewrite is not called for empty input.
+		t:       rw(rwTerminate),
+		nSpan:   2,
+	}, {
+		desc:    "Written",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u00e9ab",
+		out:     "\u00e9a!b!",
+		outFull: "\u00e9a!b!",
+		t:       rw(rwExclaim),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("\u00e9"),
+	}, {
+		desc:    "Written, no additions",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u00e9\u00e9",
+		out:     "\u00e9\u00e9",
+		outFull: "\u00e9\u00e9",
+		t:       rw(rwExclaim),
+	}, {
+		desc:    "UnreadRune twice",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a<=b<=>c<",
+		out:     "a<=b\u21d4c<",
+		outFull: "a<=b\u21d4c<",
+		t:       rw(rwArrow),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "UnreadRune twice at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a<=>b<=",
+		out:     "a\u21d4b",
+		outFull: "a\u21d4b<=",
+		err:     transform.ErrShortSrc,
+		t:       rw(rwArrow),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}}
+}
+
+// rwTerminate appends a full stop to the input if the last rune is read at
+// the end of input.
+func rwTerminate(s State) {
+	r, _ := s.ReadRune()
+	if s.WriteRune(r) && s.Available() == 0 && s.IsAtEOF() {
+		s.WriteRune('.')
+	}
+}
+
+// rwExclaim appends an exclamation mark to each rune that was written as a
+// single byte.
+func rwExclaim(s State) {
+	r, _ := s.ReadRune()
+	if s.WriteRune(r) && s.Written() < 2 {
+		s.WriteRune('!')
+	}
+}
+
+// rwArrow replaces "<=>" with U+21D4, unreading up to two runes if the input
+// does not match.
+func rwArrow(s State) {
+	r, _ := s.ReadRune()
+	if r != '<' {
+		s.WriteRune(r)
+		return
+	}
+	if r, _ := s.ReadRune(); r == '=' {
+		if r, _ := s.ReadRune(); r == '>' {
+			s.WriteRune('\u21d4')
+			return
+		}
+		s.UnreadRune()
+	}
+	s.UnreadRune()
+	s.UnreadRune()
+	r, _ = s.ReadRune()
+	s.WriteRune(r)
+}
+
+func TestUnreadRune(t *testing.T) {
+	// All bytes read in a single call to Rewrite can be unread.
+	got := NewTransformerFromFunc(func(s State) {
+		s.ReadRune()
+		s.ReadRune()
+		s.UnreadRune()
+		s.UnreadRune()
+		r, _ := s.ReadRune()
+		s.Skip()
+		s.UnreadRune()
+		s.UnreadRune()
+		s.ReadRune()
+		s.WriteRune(r)
+	}).String("a\u00e9\u2208\U0001030f")
+	if want := "a\u00e9\u2208\U0001030f"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+
+	testCases := []struct {
+		desc  string
+		in    string
+		reads int
+	}{
+		{"no read", "abc", 0},
+		{"more unreads than reads", "abc", 2},
+		{"more than maxUnread", "abcdefgh", maxUnread + 1},
+	}
+	for _, tc := range testCases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: UnreadRune did not panic", tc.desc)
+				}
+			}()
+			NewTransformerFromFunc(func(s State) {
+				for i := 0; i < tc.reads; i++ {
+					s.ReadRune()
+				}
+				for i := 0; i <= tc.reads; i++ {
+					s.UnreadRune()
+				}
+				r, _ := s.ReadRune()
+				s.WriteRune(r)
+			}).String(tc.in)
+		}()
+	}
+}
+
+func TestStatefulRewriterFunc(t *testing.T) {
+	// Number the lines of the input.
+	n := 0
+	atLineStart := true
+	tr := NewTransformer(NewStatefulRewriterFunc(func(s State) {
+		r, _ := s.ReadRune()
+		if atLineStart && !s.WriteRune('0'+rune(n%10)) || !s.WriteRune(r) {
+			return
+		}
+		if atLineStart {
+			n++
+		}
+		atLineStart = r == '\n'
+	}, func() {
+		n, atLineStart = 0, true
+	}))
+	for i := 0; i < 2; i++ {
+		// String calls Reset.
+		if got, want := tr.String("a\nb\nc"), "0a\n1b\n2c"; got != want {
+			t.Errorf("%d: got %q; want %q", i, got, want)
+		}
+	}
+}
+
+func TestNilRewriter(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "copy",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a b\u00e9",
+		out:     "a b\u00e9",
+		outFull: "a b\u00e9",
+		t:       NewTransformer(NilRewriter),
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xffb",
+		out:     "a\ufffdb",
+		outFull: "a\ufffdb",
+		t:       NewTransformer(NilRewriter),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	replace := NewTransformerWithOptions(NilRewriter, InvalidUTF8(ReplaceInvalidUTF8))
+	skip := NewTransformerWithOptions(NilRewriter, InvalidUTF8(SkipInvalidUTF8))
+	fail := NewTransformerWithOptions(NilRewriter, InvalidUTF8(ErrorOnInvalidUTF8))
+	testCases := []transformTest{{
+		desc:    "replace",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xffb\xe2\x80",
+		out:     "a\ufffdb\ufffd\ufffd",
+		outFull: "a\ufffdb\ufffd\ufffd",
+		t:       replace,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "skip",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xffb\xfe\xfd\u00e9\xe2\x80",
+		out:     "ab\u00e9",
+		outFull: "ab\u00e9",
+		t:       skip,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "skip incomplete rune",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a\xe2\x80",
+		out:     "a",
+		outFull: "a",
+		err:     transform.ErrShortSrc,
+		t:       skip,
+		errSpan: transform.ErrShortSrc,
+	}, {
+		desc:    "skip valid",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u00e9",
+		out:     "a\u00e9",
+		outFull: "a\u00e9",
+		t:       skip,
+	}, {
+		desc:    "skip within lookahead",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a..\xff.b",
+		out:     "a\u2026b",
+		outFull: "a\u2026b",
+		t:       NewTransformerWithOptions(&ellipsis{}, InvalidUTF8(SkipInvalidUTF8)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "error",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xffb",
+		out:     "a",
+		outFull: "a",
+		err:     ErrInvalidUTF8,
+		t:       fail,
+		errSpan: ErrInvalidUTF8,
+		nSpan:   1,
+	}, {
+		desc:    "error at incomplete rune",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xe2\x80",
+		out:     "a",
+		outFull: "a",
+		err:     ErrInvalidUTF8,
+		t:       fail,
+		errSpan: ErrInvalidUTF8,
+		nSpan:   1,
+	}, {
+		desc:    "error valid",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\u00e9",
+		out:     "a\u00e9",
+		outFull: "a\u00e9",
+		t:       fail,
 	}}
 	for i, tt := range testCases {
 		tt.check(t, i)
 	}
+
+	in := "x\xff\u00e9\xe2\x80y\xe2\x82\xac"
+	for _, sz := range [][2]int{{1, 3}, {1, 4}, {2, 3}, {100, 100}} {
+		got, err := transformChunks(skip, in, sz[0], sz[1])
+		if want := "x\u00e9y\u20ac"; got != want || err != nil {
+			t.Errorf("%v: got %q, %v; want %q, <nil>", sz, got, err, want)
+		}
+	}
+
+	// The policy is retained by WithContext.
+	ctx := fail.WithContext(context.Background())
+	if got, err := ctx.StringErr("a\x80b"); got != "a" || !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("WithContext: got %q, %v; want %q, %v", got, err, "a", ErrInvalidUTF8)
+	}
+
+	// A chain flushes its output if the input ends with skipped bytes.
+	chain := NewTransformerWithOptions(ChainRewriters(
+		rewriterOf(DenormalizeEllipsis()),
+		rewriterOf(NormalizeEllipsis()),
+	), InvalidUTF8(SkipInvalidUTF8))
+	for _, in := range []string{"ab\u2026\x80", "ab\u2026\x80\xff", "ab\u2026\xe2\x80"} {
+		for _, sz := range [][2]int{{1, 1}, {1, 3}, {2, 3}, {100, 100}} {
+			got, err := transformChunks(chain, in, sz[0], sz[1])
+			if want := "ab\u2026"; got != want || err != nil {
+				t.Errorf("chain:%q:%v: got %q, %v; want %q, <nil>", in, sz, got, err, want)
+			}
+		}
+	}
+}
+
+func TestPooledTransformer(t *testing.T) {
+	resets := 0
+	get := NewPooledTransformer(func() Rewriter {
+		return NewStatefulRewriterFunc(func(s State) {
+			r, _ := s.ReadRune()
+			s.WriteRune(unicode.ToUpper(r))
+		}, func() { resets++ })
+	})
+
+	tr, release := get()
+	if got := tr.String("abc"); got != "ABC" {
+		t.Errorf("got %q; want %q", got, "ABC")
+	}
+	if resets == 0 {
+		t.Error("Reset not called on acquisition")
+	}
+
+	// A sync.Pool may drop items at any time, so try a few times.
+	reused := false
+	for i := 0; i < 10 && !reused; i++ {
+		p := tr.SpanningTransformer
+		release()
+		n := resets
+		tr, release = get()
+		if resets != n+1 {
+			t.Errorf("%d: got %d calls to Reset; want 1", i, resets-n)
+		}
+		reused = tr.SpanningTransformer == p
+	}
+	release()
+	if !reused {
+		t.Error("released Transformer not reused")
+	}
+}
+
+func TestConditionalRewriter(t *testing.T) {
+	// pairs writes the next two runes in reverse order.
+	n := 0
+	pairs := NewStatefulRewriterFunc(func(s State) {
+		a, _ := s.ReadRune()
+		b, size := s.ReadRune()
+		if size == 0 || !unicode.IsDigit(b) {
+			// Let b be handled by the next call to Rewrite.
+			s.UnreadRune()
+			s.WriteRune(a)
+		} else {
+			s.WriteRune(b)
+			s.WriteRune(a)
+		}
+		n++
+	}, func() { n = 0 })
+	tr := NewTransformer(NewConditionalRewriter(unicode.IsDigit, pairs))
+
+	testCases := []struct{ in, out string }{
+		{"", ""},
+		{"abc", "abc"},
+		{"12", "21"},
+		{"a123b4c56", "a213b4c65"},
+		{"1a", "1a"},
+		{"\u2208\u0663\u0664x", "\u2208\u0664\u0663x"},
+	}
+	for _, tc := range testCases {
+		if got := tr.String(tc.in); got != tc.out {
+			t.Errorf("%+q: got %+q; want %+q", tc.in, got, tc.out)
+		}
+	}
+	if n == 0 {
+		t.Fatal("rewriter not called")
+	}
+	tr.Reset()
+	if n != 0 {
+		t.Errorf("Reset not forwarded: got %d; want 0", n)
+	}
+}
+
+// wrapState wraps the State passed to Rewrite, as a Rewriter defined outside
+// this package would.
+type wrapState struct {
+	State
+}
+
+type wrapRewriter struct{ r Rewriter }
+
+func (w wrapRewriter) Rewrite(s State) { w.r.Rewrite(wrapState{s}) }
+func (w wrapRewriter) Reset()          { w.r.Reset() }
+
+func TestWrappedState(t *testing.T) {
+	var log bytes.Buffer
+	testCases := []struct {
+		desc    string
+		r       Rewriter
+		in, out string
+	}{
+		{"CStringEscape", CStringEscape(), "a\x01\xff", `a\001\377`},
+		{"NewGoStringEscaper", NewGoStringEscaper(), "a\xff\u00e9", `a\xff\u00e9`},
+		{"NewTrieReplacer", NewTrieReplacer(map[string]string{"ab": "x"}), "cabc", "cxc"},
+		{"NewDebugRewriter", NewDebugRewriter(NilRewriter, &log), "ab", "ab"},
+		{"ChainRewriters", ChainRewriters(NilRewriter, NewMapRewriter(map[rune]rune{'a': 'b'})), "abc", "bbc"},
+	}
+	for i, tc := range testCases {
+		tt := transformTest{
+			desc:    tc.desc,
+			szDst:   large,
+			atEOF:   true,
+			in:      tc.in,
+			out:     tc.out,
+			outFull: tc.out,
+			t:       NewTransformer(wrapRewriter{tc.r}),
+			errSpan: transform.ErrEndOfSpan,
+		}
+		if tc.in == tc.out {
+			tt.errSpan = nil
+		}
+		tt.check(t, i)
+	}
+	if want := "READ: [U+0061] WROTE: [U+0061] ERR: <nil>\n"; !strings.HasPrefix(log.String(), want) {
+		t.Errorf("log: got %q; want prefix %q", log.String(), want)
+	}
 }
 
 func TestRewriteAlloc(t *testing.T) {
@@ -309,3 +903,15 @@ func BenchmarkRewriteNone(t *testing.B) {
 		r.Transform(dst, src, true)
 	}
 }
+
+func BenchmarkRewriteASCII(t *testing.B) {
+	src := []byte(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100))
+	dst := make([]byte, len(src))
+
+	r := NewTransformer(rwCopy{})
+
+	t.SetBytes(int64(len(src)))
+	for i := 0; i < t.N; i++ {
+		r.Transform(dst, src, true)
+	}
+}
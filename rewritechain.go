@@ -0,0 +1,188 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// ChainRewriters returns a Rewriter that applies the given Rewriters in
+// sequence: the output of each Rewriter is the input of the next. Reset resets
+// all Rewriters.
+//
+// Output of a Rewriter that is not yet consumed by the next is buffered
+// between calls to Rewrite.
+func ChainRewriters(rewriters ...Rewriter) Rewriter {
+	if len(rewriters) == 1 {
+		return rewriters[0]
+	}
+	c := &rewriterChain{rewriters: rewriters}
+	for _, r := range rewriters {
+		c.stages = append(c.stages, stage{r: &rewriter{rewrite: r}})
+	}
+	return c
+}
+
+type rewriterChain struct {
+	rewriters []Rewriter
+
+	// stages[i] holds the input of rewriters[i] and is unused for i == 0,
+	// which reads from the State passed to Rewrite.
+	stages  []stage
+	scratch []byte
+
+	// out holds the output of the final Rewriter that is not yet written.
+	out []byte
+
+	// held is the number of bytes of input that have been processed, but not
+	// yet reported as read, because the output for it has not yet been
+	// written completely. These bytes are at the start of the input of the
+	// next call to Rewrite.
+	held int
+}
+
+type stage struct {
+	r   *rewriter
+	in  []byte
+	out []byte
+}
+
+func (c *rewriterChain) Reset() {
+	for i, r := range c.rewriters {
+		r.Reset()
+		c.stages[i].in = c.stages[i].in[:0]
+	}
+	c.out = c.out[:0]
+	c.held = 0
+}
+
+func (c *rewriterChain) Rewrite(s State) {
+	b, dst := s.base()
+	if len(c.out) > 0 || c.held > 0 {
+		if dst == nil {
+			// The input for the pending output has already been processed,
+			// so the span ends here. Transform writes the output instead.
+			s.SetError(transform.ErrEndOfSpan)
+			return
+		}
+		if len(dst.dst) == dst.pDst {
+			s.SetError(transform.ErrShortDst)
+			return
+		}
+		c.flush(s, b, dst)
+		return
+	}
+
+	// If more input may follow, the last rune is not passed to the first
+	// Rewriter, so that it is only processed once we know whether it is
+	// the end of input. Otherwise output pending in later Rewriters could not
+	// be flushed. Invalid bytes following it are held back as well if they are
+	// skipped, as the Transformer does not call Rewrite for them.
+	src := b.src[b.pSrc:]
+	if !b.atEOF {
+		for b.invalid == SkipInvalidUTF8 && len(src) > 0 {
+			if r, size := utf8.DecodeLastRune(src); r != utf8.RuneError || size != 1 {
+				break
+			}
+			src = src[:len(src)-1]
+		}
+		_, size := utf8.DecodeLastRune(src)
+		src = src[:len(src)-size]
+	}
+	if len(src) == 0 {
+		s.SetError(transform.ErrShortSrc)
+		return
+	}
+	v := state{spanState: spanState{src: src, atEOF: b.atEOF, invalid: b.invalid}}
+	for {
+		v.dst = c.scratch[:cap(c.scratch)]
+		if c.rewriters[0].Rewrite(&v); v.err != transform.ErrShortDst {
+			break
+		}
+		c.scratch = make([]byte, 2*cap(c.scratch)+utf8.UTFMax)
+		v.err, v.pDst, v.pSrc, v.nPrev, v.pDstStart, v.shortSrc = nil, 0, 0, 0, 0, false
+	}
+	if v.err != nil {
+		s.SetError(v.err)
+		return
+	}
+	atEOF := b.atEOF && atEnd(b, b.pSrc+v.pSrc)
+
+	out := v.dst[:v.pDst]
+	for i := 1; i < len(c.stages); i++ {
+		var err error
+		if out, err = c.stages[i].transform(out, atEOF); err != nil {
+			s.SetError(err)
+			return
+		}
+	}
+	// The input is only reported as read once all of its output is written,
+	// so that a Span that ends here is followed by a Transform of the same
+	// input, which then writes the output.
+	c.out = append(c.out, out...)
+	c.held = v.pSrc
+	if dst == nil && len(c.out) != c.held {
+		s.SetError(transform.ErrEndOfSpan)
+		return
+	}
+	c.flush(s, b, dst)
+}
+
+// atEnd reports whether all input of b from position p has been read, not
+// counting invalid bytes that the Transformer skips before it would call
+// Rewrite again.
+func atEnd(b *spanState, p int) bool {
+	rest := b.src[p:]
+	return len(rest) == 0 || b.invalid == SkipInvalidUTF8 && invalidPrefix(rest, b.atEOF) == len(rest)
+}
+
+// flush writes as much of the pending output as fits in dst and reports the
+// held input as read once all output is written. In a Span, the output is
+// compared at once.
+func (c *rewriterChain) flush(s State, b *spanState, dst *state) {
+	n := len(c.out)
+	if dst != nil && n > len(dst.dst)-dst.pDst {
+		n = len(dst.dst) - dst.pDst
+	}
+	if !s.WriteBytes(c.out[:n]) {
+		return
+	}
+	c.out = c.out[:copy(c.out, c.out[n:])]
+	if len(c.out) == 0 {
+		b.pSrc += c.held
+		c.held = 0
+	}
+}
+
+// transform appends in to the input of the stage and returns the output for
+// as much of the input as can be processed.
+func (p *stage) transform(in []byte, atEOF bool) ([]byte, error) {
+	p.in = append(p.in, in...)
+	out := p.out[:0]
+	src := p.in
+	for {
+		if len(out) == cap(out) {
+			out = append(out, 0)[:len(out)]
+		}
+		// Errors are wrapped, if at all, by the Transformer of the chain.
+		nDst, nSrc, err := p.r.transform(out[len(out):cap(out)], src, atEOF)
+		out = out[:len(out)+nDst]
+		src = src[nSrc:]
+		switch {
+		case err == transform.ErrShortDst:
+			if nDst == 0 {
+				out = append(out[:cap(out)], 0)[:len(out)]
+			}
+			continue
+		case err == transform.ErrShortSrc && !atEOF:
+			err = nil
+		}
+		p.in = p.in[:copy(p.in, src)]
+		p.out = out
+		return out, err
+	}
+}
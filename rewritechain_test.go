@@ -0,0 +1,160 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+
+	"golang.org/x/text/transform"
+)
+
+// rewriterOf returns the Rewriter of a Transformer created by NewTransformer.
+func rewriterOf(t Transformer) Rewriter {
+	return t.SpanningTransformer.(*rewriter).rewrite
+}
+
+// transformChunks transforms in using t, passing at most szSrc bytes of new
+// input and providing a destination of szDst bytes for each call to
+// Transform.
+func transformChunks(t transform.Transformer, in string, szSrc, szDst int) (string, error) {
+	t.Reset()
+	var out, src []byte
+	dst := make([]byte, szDst)
+	for i := 0; i < 100000; i++ {
+		n := szSrc
+		if n > len(in) {
+			n = len(in)
+		}
+		src = append(src, in[:n]...)
+		in = in[n:]
+		atEOF := in == ""
+		nDst, nSrc, err := t.Transform(dst, src, atEOF)
+		out = append(out, dst[:nDst]...)
+		src = src[nSrc:]
+		switch {
+		case err == nil && atEOF:
+			return string(out), nil
+		case err == transform.ErrShortSrc && atEOF:
+			return string(out), err
+		case err != nil && err != transform.ErrShortDst && err != transform.ErrShortSrc:
+			return string(out), err
+		}
+	}
+	return string(out), errors.New("no progress")
+}
+
+func TestChainRewriters(t *testing.T) {
+	upper := func() Transformer { return MapRune(unicode.ToUpper) }
+	collapse := func() Transformer { return CollapseRuns(unicode.IsSpace, ' ') }
+	trim := func() Transformer { return Trim(unicode.IsSpace) }
+	lf := func() Transformer { return NormalizeLineBreaks('\n') }
+	testCases := []struct {
+		desc string
+		t    []func() Transformer
+	}{
+		{"single", []func() Transformer{NormalizeEllipsis}},
+		{"round trip", []func() Transformer{DenormalizeEllipsis, NormalizeEllipsis}},
+		{"expand and collapse", []func() Transformer{DenormalizeEllipsis, upper, NormalizeEllipsis}},
+		{"stateful", []func() Transformer{trim, collapse, DenormalizeEllipsis}},
+		{"lookahead", []func() Transformer{lf, collapse, trim, NormalizeEllipsis}},
+	}
+	inputs := []string{
+		"",
+		"a",
+		"...",
+		"Wait...   what…  \r\n  ....  ..",
+		"  leading and trailing  ",
+		"\r\n\r\n",
+		"…",
+		strings.Repeat("Thé qüick... brøwn  føx…\r\n", 20),
+	}
+	for _, tc := range testCases {
+		var want []transform.Transformer
+		var rewriters []Rewriter
+		for _, f := range tc.t {
+			want = append(want, f())
+			rewriters = append(rewriters, rewriterOf(f()))
+		}
+		ref := transform.Chain(want...)
+		tr := NewTransformer(ChainRewriters(rewriters...))
+		for _, in := range inputs {
+			wantOut, _, _ := transform.String(ref, in)
+			if got := tr.String(in); got != wantOut {
+				t.Errorf("%s:%q: got %q; want %q", tc.desc, in, got, wantOut)
+			}
+			sizes := [][2]int{{1, 4}, {1, 5}, {3, 4}, {7, 8}}
+			if len(rewriters) > 1 {
+				// Output of a chain is written in pieces if needed.
+				sizes = append(sizes, [2]int{1, 1}, [2]int{3, 1})
+			}
+			for _, sz := range sizes {
+				got, err := transformChunks(tr, in, sz[0], sz[1])
+				if got != wantOut || err != nil {
+					t.Errorf("%s:%q:%v: got %q, %v; want %q, <nil>", tc.desc, in, sz, got, err, wantOut)
+				}
+			}
+		}
+	}
+}
+
+func TestChainRewritersNested(t *testing.T) {
+	inner := ChainRewriters(rewriterOf(DenormalizeEllipsis()), rewriterOf(MapRune(unicode.ToUpper)))
+	tr := NewTransformer(ChainRewriters(inner, rewriterOf(NormalizeEllipsis())))
+	for _, sz := range [][2]int{{1, 1}, {2, 3}, {100, 100}} {
+		got, err := transformChunks(tr, "a…b", sz[0], sz[1])
+		if want := "A…B"; got != want || err != nil {
+			t.Errorf("%v: got %q, %v; want %q, <nil>", sz, got, err, want)
+		}
+	}
+}
+
+func TestChainRewritersSpan(t *testing.T) {
+	tr := NewTransformer(ChainRewriters(rewriterOf(DenormalizeEllipsis()), rewriterOf(NormalizeEllipsis())))
+	if n, err := tr.Span([]byte("abc"), true); n != 3 || err != nil {
+		t.Errorf("got %d, %v; want 3, <nil>", n, err)
+	}
+	tr.Reset()
+	if n, err := tr.Span([]byte("a....b"), true); n != 1 || err != transform.ErrEndOfSpan {
+		t.Errorf("got %d, %v; want 1, %v", n, err, transform.ErrEndOfSpan)
+	}
+
+	// Transform continues where Span ended without processing input twice.
+	upper := rewriterOf(MapRune(unicode.ToUpper))
+	collapse := rewriterOf(CollapseRuns(unicode.IsSpace, ' '))
+	testCases := []struct {
+		r       Rewriter
+		in, out string
+	}{
+		{ChainRewriters(upper, collapse), "aB", "AB"},
+		{ChainRewriters(upper, collapse), "AB  c", "AB C"},
+		{ChainRewriters(rewriterOf(DenormalizeEllipsis()), rewriterOf(NormalizeEllipsis())), "ab...c", "ab\u2026c"},
+		{ChainRewriters(rewriterOf(Trim(unicode.IsSpace)), collapse, rewriterOf(DenormalizeEllipsis())), "x  y", "x y"},
+	}
+	for _, tc := range testCases {
+		if got, err := spanTransform(NewTransformer(tc.r), tc.in); got != tc.out || err != nil {
+			t.Errorf("%q: got %q, %v; want %q, <nil>", tc.in, got, err, tc.out)
+		}
+	}
+}
+
+func TestChainRewritersError(t *testing.T) {
+	tr := NewTransformer(ChainRewriters(
+		rewriterOf(MapRune(unicode.ToUpper)),
+		rewriterOf(RemoveFunc(unicode.IsSpace)),
+		rewriterOf(NewTransformerFromFunc(func(s State) {
+			if r, _ := s.ReadRune(); r == 'X' {
+				s.SetError(ErrTooLong)
+			} else {
+				s.WriteRune(r)
+			}
+		})),
+	))
+	if got, err := tr.StringErr("a b x c"); got != "AB" || !errors.Is(err, ErrTooLong) {
+		t.Errorf("got %q, %v; want %q, %v", got, err, "AB", ErrTooLong)
+	}
+}
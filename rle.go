@@ -0,0 +1,160 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strconv"
+
+	"golang.org/x/text/transform"
+)
+
+const (
+	// runLengthSep separates the count from the rune in run-length encoded
+	// text.
+	runLengthSep = '×'
+
+	// maxRunLength is the largest count written by RunLengthEncode and read by
+	// RunLengthDecode, and maxRunLengthDigits its number of digits. Longer
+	// runs are encoded as multiple runs.
+	maxRunLength       = 999999999
+	maxRunLengthDigits = 9
+
+	// maxRunLengthChunk is the maximum number of runes RunLengthDecode writes
+	// per call to Rewrite, so that long runs can be written to a small
+	// destination buffer.
+	maxRunLengthChunk = 64
+)
+
+// RunLengthEncode returns a Transformer that replaces each run of at least
+// minRun identical runes with its length, a multiplication sign (U+00D7), and
+// the rune, so "aaaaaaa" becomes "7×a". Shorter runs are written verbatim. It
+// panics if minRun is less than 1.
+//
+// Only the output for a minRun of 1 can be decoded unambiguously by
+// RunLengthDecode, as otherwise digits followed by a multiplication sign may
+// appear verbatim in the output.
+func RunLengthEncode(minRun int) Transformer {
+	if minRun < 1 {
+		panic("textutil: run length less than 1")
+	}
+	return NewTransformer(&runLengthEncoder{minRun: minRun})
+}
+
+type runLengthEncoder struct {
+	minRun int
+
+	// n is the number of runes of the current run that have been read in
+	// previous calls to Rewrite.
+	n int
+}
+
+func (e *runLengthEncoder) Reset() { e.n = 0 }
+
+func (e *runLengthEncoder) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	// Peek to determine whether the run continues. If more input may follow,
+	// ReadRune has set ErrShortSrc and we will be called again.
+	next, size := s.ReadRune()
+	s.UnreadRune()
+
+	n := e.n + 1
+	if size > 0 && next == r && n < maxRunLength {
+		if s.IsSpan() {
+			// Holding back the rune ends a span.
+			s.SetError(transform.ErrEndOfSpan)
+			return
+		}
+		e.n = n
+		return
+	}
+	if n >= e.minRun {
+		if !s.WriteString(strconv.Itoa(n)) || !s.WriteRune(runLengthSep) || !s.WriteRune(r) {
+			return
+		}
+	} else if !s.WriteRuneN(r, n) {
+		return
+	}
+	if size > 0 {
+		// At the end of input the state no longer matters, and otherwise all
+		// writes will be discarded.
+		e.n = 0
+	}
+}
+
+// RunLengthDecode returns a Transformer that replaces each run-length encoded
+// run, a decimal count followed by a multiplication sign (U+00D7) and a rune,
+// with count copies of the rune. It reverses RunLengthEncode(1). Text that is
+// not a run with a positive count is written verbatim. A count has at most
+// nine digits, so longer sequences of digits are partly written verbatim.
+func RunLengthDecode() Transformer {
+	return NewTransformer(&runLengthDecoder{})
+}
+
+type runLengthDecoder struct {
+	// n is the number of copies of the next rune that remain to be written.
+	n int
+}
+
+func (d *runLengthDecoder) Reset() { d.n = 0 }
+
+func (d *runLengthDecoder) Rewrite(s State) {
+	if d.n > 0 {
+		r, _ := s.ReadRune()
+		k := d.n
+		if k > maxRunLengthChunk {
+			k = maxRunLengthChunk
+		}
+		if !s.WriteRuneN(r, k) {
+			return
+		}
+		if d.n -= k; d.n > 0 {
+			// Read the rune again in the next call.
+			s.UnreadRune()
+		}
+		return
+	}
+
+	r, _ := s.ReadRune()
+	if !isDigit(r) {
+		s.WriteRune(r)
+		return
+	}
+	n, digits := int(r-'0'), 1
+	r, size := s.ReadRune()
+	for ; size > 0 && isDigit(r) && digits < maxRunLengthDigits; r, size = s.ReadRune() {
+		n = n*10 + int(r-'0')
+		digits++
+	}
+	switch {
+	case size == 0:
+		// Either the input is exhausted or all writes will be discarded.
+		d.writeDigits(s, n, digits)
+		return
+	case r != runLengthSep || n == 0:
+		s.UnreadRune()
+		d.writeDigits(s, n, digits)
+		return
+	}
+	// Peek to verify that a rune follows the separator.
+	if _, size := s.ReadRune(); size == 0 {
+		if d.writeDigits(s, n, digits) {
+			s.WriteRune(runLengthSep)
+		}
+		return
+	}
+	s.UnreadRune()
+	if s.IsSpan() {
+		// Consuming the count without writing it ends a span.
+		s.SetError(transform.ErrEndOfSpan)
+		return
+	}
+	d.n = n
+}
+
+// writeDigits writes the digits read for n verbatim, including leading zeros.
+func (d *runLengthDecoder) writeDigits(s State, n, digits int) bool {
+	str := strconv.Itoa(n)
+	return s.WriteRuneN('0', digits-len(str)) && s.WriteString(str)
+}
@@ -0,0 +1,186 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestRunLengthEncode(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "shorter than minRun",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abbcccd",
+		out:     "abbcccd",
+		outFull: "abbcccd",
+		t:       RunLengthEncode(4),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "exactly minRun",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abbbbc",
+		out:     "a4×bc",
+		outFull: "a4×bc",
+		t:       RunLengthEncode(4),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "longer than minRun",
+		szDst:   large,
+		atEOF:   true,
+		in:      "xaaaaaaa",
+		out:     "x7×a",
+		outFull: "x7×a",
+		t:       RunLengthEncode(3),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "minRun 1",
+		szDst:   large,
+		atEOF:   true,
+		in:      "aébbé",
+		out:     "1×a1×é2×b1×é",
+		outFull: "1×a1×é2×b1×é",
+		t:       RunLengthEncode(1),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "run at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "abbbb",
+		out:     "a",
+		outFull: "a4×b",
+		err:     transform.ErrShortSrc,
+		t:       RunLengthEncode(2),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "short destination",
+		szDst:   4,
+		atEOF:   true,
+		in:      "abbbbbc",
+		out:     "a",
+		outFull: "a5×bc",
+		err:     transform.ErrShortDst,
+		t:       RunLengthEncode(2),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}}
+	for i, tt := range testCases {
+		tt.t.Reset()
+		tt.check(t, i)
+	}
+}
+
+func TestRunLengthDecode(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "decode",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a4×bc12×-",
+		out:     "abbbbc------------",
+		outFull: "abbbbc------------",
+		t:       RunLengthDecode(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "verbatim",
+		szDst:   large,
+		atEOF:   true,
+		in:      "12 ×0×a 007 3×",
+		out:     "12 ×0×a 007 3×",
+		outFull: "12 ×0×a 007 3×",
+		t:       RunLengthDecode(),
+	}, {
+		desc:    "leading zeros",
+		szDst:   large,
+		atEOF:   true,
+		in:      "003×a",
+		out:     "aaa",
+		outFull: "aaa",
+		t:       RunLengthDecode(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "too many digits",
+		szDst:   large,
+		atEOF:   true,
+		in:      "12345678901×a",
+		out:     "123456789a",
+		outFull: "123456789a",
+		t:       RunLengthDecode(),
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   9,
+	}, {
+		desc:    "separator at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a3×",
+		out:     "a",
+		outFull: "a3×",
+		err:     transform.ErrShortSrc,
+		t:       RunLengthDecode(),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "long run",
+		szDst:   100,
+		atEOF:   true,
+		in:      "1000×a",
+		out:     strings.Repeat("a", 64),
+		outFull: strings.Repeat("a", 1000),
+		err:     transform.ErrShortDst,
+		t:       RunLengthDecode(),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.t.Reset()
+		tt.check(t, i)
+	}
+}
+
+func TestRunLengthBoundary(t *testing.T) {
+	// Runs spanning the internal buffers of transform.String.
+	in := "x" + strings.Repeat("é", 300) + "y"
+	want := "x300×éy"
+	if got := RunLengthEncode(2).String(in); got != want {
+		t.Errorf("encode: got %q; want %q", got, want)
+	}
+	if got := RunLengthDecode().String(want); got != in {
+		t.Errorf("decode: got %q; want %q", got, in)
+	}
+}
+
+func TestRunLengthRoundTrip(t *testing.T) {
+	roundTrip(t, RunLengthEncode(1), RunLengthDecode(), []string{
+		"",
+		"a",
+		"aaaaaaa",
+		"Thé qüick brøwn føx",
+		"1122333×××",
+		"0×a 3×b",
+		strings.Repeat("ab", 100) + strings.Repeat("c", 1000),
+	})
+}
+
+func TestRunLengthSpan(t *testing.T) {
+	testCases := []struct {
+		t       Transformer
+		in, out string
+	}{
+		{RunLengthEncode(3), "ab...c", "ab3×.c"},
+		{RunLengthEncode(2), "Hello", "He2×lo"},
+		{RunLengthDecode(), "ab3×.c", "ab...c"},
+		{RunLengthDecode(), "a0003×bc", "abbbc"},
+	}
+	for _, tc := range testCases {
+		if got, err := spanTransform(tc.t, tc.in); got != tc.out || err != nil {
+			t.Errorf("%q: got %q, %v; want %q, <nil>", tc.in, got, err, tc.out)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// ExpandRomanNumerals returns a Transformer that replaces the Roman numeral
+// characters of the Number Forms block (U+2160–U+2188) with the equivalent
+// sequence of ASCII letters, such as Ⅻ to XII and ⅿ to m. Archaic numerals
+// without a common ASCII representation, like ↁ (five thousand), and the
+// reversed letter C are left unchanged.
+func ExpandRomanNumerals() Transformer {
+	return FlatMap(lookup(romanNumerals))
+}
+
+var romanNumerals = map[rune]string{
+	'Ⅰ': "I",
+	'Ⅱ': "II",
+	'Ⅲ': "III",
+	'Ⅳ': "IV",
+	'Ⅴ': "V",
+	'Ⅵ': "VI",
+	'Ⅶ': "VII",
+	'Ⅷ': "VIII",
+	'Ⅸ': "IX",
+	'Ⅹ': "X",
+	'Ⅺ': "XI",
+	'Ⅻ': "XII",
+	'Ⅼ': "L",
+	'Ⅽ': "C",
+	'Ⅾ': "D",
+	'Ⅿ': "M",
+
+	'ⅰ': "i",
+	'ⅱ': "ii",
+	'ⅲ': "iii",
+	'ⅳ': "iv",
+	'ⅴ': "v",
+	'ⅵ': "vi",
+	'ⅶ': "vii",
+	'ⅷ': "viii",
+	'ⅸ': "ix",
+	'ⅹ': "x",
+	'ⅺ': "xi",
+	'ⅻ': "xii",
+	'ⅼ': "l",
+	'ⅽ': "c",
+	'ⅾ': "d",
+	'ⅿ': "m",
+
+	'ↀ': "M",  // ROMAN NUMERAL ONE THOUSAND C D
+	'ↅ': "VI", // ROMAN NUMERAL SIX LATE FORM
+	'ↆ': "L",  // ROMAN NUMERAL FIFTY EARLY FORM
+}
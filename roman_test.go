@@ -0,0 +1,76 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestExpandRomanNumerals(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "uppercase",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Henry Ⅷ, chapter Ⅻ",
+		out:     "Henry VIII, chapter XII",
+		outFull: "Henry VIII, chapter XII",
+		t:       ExpandRomanNumerals(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "lowercase",
+		szDst:   large,
+		atEOF:   true,
+		in:      "page ⅳ and ⅿ",
+		out:     "page iv and m",
+		outFull: "page iv and m",
+		t:       ExpandRomanNumerals(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   5,
+		atEOF:   true,
+		in:      "ⅢⅧ",
+		out:     "III",
+		outFull: "IIIVIII",
+		err:     transform.ErrShortDst,
+		t:       ExpandRomanNumerals(),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no numerals",
+		szDst:   large,
+		atEOF:   true,
+		in:      "XIV ↁↂↃↄↇↈ ⅓",
+		out:     "XIV ↁↂↃↄↇↈ ⅓",
+		outFull: "XIV ↁↂↃↄↇↈ ⅓",
+		t:       ExpandRomanNumerals(),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestRomanNumeralTable(t *testing.T) {
+	for r := rune(0x2160); r <= 0x2188; r++ {
+		got := ExpandRomanNumerals().String(string(r))
+		want := string(r)
+		if s, ok := romanNumerals[r]; ok {
+			want = s
+		}
+		if got != want {
+			t.Errorf("%U: got %q; want %q", r, got, want)
+		}
+		// Where a compatibility decomposition exists, it should be the same.
+		if d := norm.NFKC.String(string(r)); d != string(r) && d != got {
+			t.Errorf("%U: got %q; want %q", r, got, d)
+		}
+		if lower := strings.ToLower(got); r >= 0x2170 && r <= 0x217f && got != lower {
+			t.Errorf("%U: got %q; want lowercase", r, got)
+		}
+	}
+}
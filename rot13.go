@@ -0,0 +1,21 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// NewROT13Rewriter returns a Rewriter that replaces each ASCII letter with the
+// letter 13 positions further in the alphabet, wrapping around from z to a.
+// All other runes are left unchanged. The mapping is its own inverse.
+func NewROT13Rewriter() Rewriter {
+	return rewriterFunc(func(s State) {
+		r, _ := s.ReadRune()
+		switch {
+		case 'a' <= r && r <= 'm', 'A' <= r && r <= 'M':
+			r += 13
+		case 'n' <= r && r <= 'z', 'N' <= r && r <= 'Z':
+			r -= 13
+		}
+		s.WriteRune(r)
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestROT13(t *testing.T) {
+	rot13 := NewTransformer(NewROT13Rewriter())
+	testCases := []transformTest{{
+		desc:    "letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abcmnopxyzABCMNOPXYZ",
+		out:     "nopzabcklmNOPZABCKLM",
+		outFull: "nopzabcklmNOPZABCKLM",
+		t:       rot13,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "mixed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "123, Hello w\u00f8rld!",
+		out:     "123, Uryyb j\u00f8eyq!",
+		outFull: "123, Uryyb j\u00f8eyq!",
+		t:       rot13,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("123, "),
+	}, {
+		desc:    "no letters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "123 @[`{ \u00e9\u00f8",
+		out:     "123 @[`{ \u00e9\u00f8",
+		outFull: "123 @[`{ \u00e9\u00f8",
+		t:       rot13,
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "abc",
+		out:     "no",
+		outFull: "nop",
+		err:     transform.ErrShortDst,
+		t:       rot13,
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	roundTrip(t, rot13, rot13, []string{
+		"",
+		"The Quick Brown Fox Jumps Over The Lazy Dog.",
+		"H\u00e9llo, w\u00f8rld! 123",
+	})
+}
@@ -0,0 +1,50 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode"
+
+// Slugify returns a Transformer that converts text to a URL-safe slug. The
+// input is lowercased and stripped of diacritics, each run of runes that are
+// neither letters nor digits is replaced by a single sep, and leading and
+// trailing separators are removed. Letters and digits of scripts other than
+// Latin are retained.
+func Slugify(sep rune) Transformer {
+	return ChainTransformers(
+		NFC(),
+		StripDiacritics(),
+		MapRune(unicode.ToLower),
+		CollapseRuns(isNotAlnum, sep),
+		Trim(func(r rune) bool { return r == sep }),
+	)
+}
+
+func isNotAlnum(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// CanonicalizeSlug returns a Transformer that converts text to a canonical
+// slug. Unlike Slugify, runes that are neither letters, digits, nor white space
+// are removed rather than treated as separators, so "don't stop" becomes
+// "dont-stop". Each run of white space and sep is replaced by a single sep and
+// leading and trailing separators are removed. The sep rune should not be a
+// letter or digit.
+//
+// The result is deterministic under canonical equivalence: the input is
+// converted to NFC first, so canonically equivalent strings always produce the
+// same slug. The converse does not hold, as the mapping discards case,
+// diacritics and punctuation. Slugs are fixed points: canonicalizing a slug
+// returns it unchanged.
+func CanonicalizeSlug(sep rune) Transformer {
+	isSep := func(r rune) bool { return r == sep || unicode.IsSpace(r) }
+	return ChainTransformers(
+		NFC(),
+		StripDiacritics(),
+		MapRune(unicode.ToLower),
+		RemoveFunc(func(r rune) bool { return isNotAlnum(r) && !isSep(r) }),
+		CollapseRuns(isSep, sep),
+		Trim(func(r rune) bool { return r == sep }),
+	)
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	testCases := []struct {
+		sep     rune
+		in, out string
+	}{
+		{'-', "Hello World", "hello-world"},
+		{'-', "Crème Brûlée", "creme-brulee"},
+		{'-', "Cre\u0300me Bru\u0302le\u0301e", "creme-brulee"},
+		{'-', "  Hello,   World!  ", "hello-world"},
+		{'-', "a--b__c", "a-b-c"},
+		{'-', "--a--", "a"},
+		{'_', "\u00dcber stra\u00dfe", "uber_stra\u00dfe"},
+		{'-', "東京 タワー", "東京-タワー"},
+		{'-', "Привет, мир", "привет-мир"},
+		{'-', "Go 1.8 release", "go-1-8-release"},
+		{'-', "", ""},
+		{'-', "!!!", ""},
+	}
+	for i, tc := range testCases {
+		slug := Slugify(tc.sep)
+		got := slug.String(tc.in)
+		if got != tc.out {
+			t.Errorf("%d:%q: got %q; want %q", i, tc.in, got, tc.out)
+		}
+		if again := slug.String(got); again != got {
+			t.Errorf("%d:%q: not idempotent: got %q; want %q", i, tc.in, again, got)
+		}
+	}
+}
+
+func TestCanonicalizeSlug(t *testing.T) {
+	testCases := []struct {
+		sep     rune
+		in, out string
+	}{
+		{'-', "Hello World", "hello-world"},
+		{'-', "Crème Brûlée", "creme-brulee"},
+		{'-', "Don't stop (believin')", "dont-stop-believin"},
+		{'-', "a -- b  \t c", "a-b-c"},
+		{'-', "a__b", "ab"},
+		{'_', "a__b - c", "a_b_c"},
+		{'-', " -- a b -- ", "a-b"},
+		{'-', "Go 1.8 release", "go-18-release"},
+		{'-', "Привет, мир", "привет-мир"},
+		{'-', "", ""},
+		{'-', "!!!", ""},
+	}
+	for i, tc := range testCases {
+		slug := CanonicalizeSlug(tc.sep)
+		got := slug.String(tc.in)
+		if got != tc.out {
+			t.Errorf("%d:%q: got %q; want %q", i, tc.in, got, tc.out)
+		}
+		if again := slug.String(got); again != got {
+			t.Errorf("%d:%q: not idempotent: got %q; want %q", i, tc.in, again, got)
+		}
+	}
+}
+
+func TestCanonicalizeSlugEquivalence(t *testing.T) {
+	// Each pair is canonically equivalent.
+	testCases := []struct{ a, b string }{
+		{"Cr\u00e8me", "Cre\u0300me"},
+		{"\u00c5ngstr\u00f6m", "\u212bngstro\u0308m"},
+		{"\u1e69 x", "s\u0323\u0307 x"},
+		{"\u1e69 x", "s\u0307\u0323 x"},
+		{"\uac00 \uac00", "\u1100\u1161 \uac00"},
+		{"\u0041\u030a b", "\u212b b"},
+	}
+	slug := CanonicalizeSlug('-')
+	for i, tc := range testCases {
+		a, b := slug.String(tc.a), slug.String(tc.b)
+		if a != b {
+			t.Errorf("%d: %+q and %+q produce different slugs %+q and %+q", i, tc.a, tc.b, a, b)
+		}
+	}
+}
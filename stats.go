@@ -0,0 +1,72 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode"
+
+// TextStats holds statistics about the text seen by the Transformer returned
+// by NewTextStatsCollector.
+type TextStats struct {
+	// Runes and Bytes are the number of runes and bytes. Each byte of invalid
+	// UTF-8 counts as a single rune.
+	Runes, Bytes int64
+
+	// Lines is the number of line feeds (U+000A).
+	Lines int64
+
+	// Words is the number of runs of runes separated by white space, as
+	// defined by unicode.IsSpace.
+	Words int64
+
+	// UniqueRunes maps each rune to its number of occurrences. Invalid UTF-8
+	// is counted as utf8.RuneError.
+	UniqueRunes map[rune]int
+}
+
+// NewTextStatsCollector returns a TextStats and a Transformer that copies its
+// input verbatim and accumulates statistics about it. Reset clears the
+// statistics. The statistics are updated as the input is read, which restricts
+// reading them concurrently, as described for Synchronized.
+func NewTextStatsCollector() (*TextStats, Transformer) {
+	c := &statsCollector{stats: &TextStats{UniqueRunes: map[rune]int{}}}
+	return c.stats, Transformer{c}
+}
+
+type statsCollector struct {
+	stats  *TextStats
+	inWord bool
+}
+
+func (c *statsCollector) Reset() {
+	*c.stats = TextStats{UniqueRunes: map[rune]int{}}
+	c.inWord = false
+}
+
+func (c *statsCollector) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	nDst, nSrc, err = copyRunes(dst, src, atEOF, c.add)
+	c.stats.Bytes += int64(nSrc)
+	return nDst, nSrc, err
+}
+
+func (c *statsCollector) add(r rune) {
+	s := c.stats
+	s.Runes++
+	s.UniqueRunes[r]++
+	if r == '\n' {
+		s.Lines++
+	}
+	if unicode.IsSpace(r) {
+		c.inWord = false
+	} else if !c.inWord {
+		c.inWord = true
+		s.Words++
+	}
+}
+
+// Span reports the entire input as unchanged. Statistics are only collected
+// by Transform.
+func (c *statsCollector) Span(src []byte, atEOF bool) (n int, err error) {
+	return len(src), nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTextStats(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want TextStats
+	}{{
+		in:   "",
+		want: TextStats{UniqueRunes: map[rune]int{}},
+	}, {
+		in: "aba",
+		want: TextStats{
+			Runes: 3, Bytes: 3, Words: 1,
+			UniqueRunes: map[rune]int{'a': 2, 'b': 1},
+		},
+	}, {
+		in: "thé cät\n\nsät\n",
+		want: TextStats{
+			Runes: 13, Bytes: 16, Lines: 3, Words: 3,
+			UniqueRunes: map[rune]int{
+				't': 3, 'h': 1, 'é': 1, ' ': 1, 'c': 1, 'ä': 2, '\n': 3, 's': 1,
+			},
+		},
+	}, {
+		in: "  leading\u3000and\ttrailing  ",
+		want: TextStats{
+			Runes: 24, Bytes: 26, Words: 3,
+			UniqueRunes: map[rune]int{
+				' ': 4, 'l': 2, 'e': 1, 'a': 3, 'd': 2, 'i': 3, 'n': 3,
+				'g': 2, '\u3000': 1, '\t': 1, 't': 1, 'r': 1,
+			},
+		},
+	}, {
+		in: "a\x80\xff",
+		want: TextStats{
+			Runes: 3, Bytes: 3, Words: 1,
+			UniqueRunes: map[rune]int{'a': 1, utf8.RuneError: 2},
+		},
+	}}
+	for _, tc := range testCases {
+		stats, tr := NewTextStatsCollector()
+		if got := tr.String(tc.in); got != tc.in {
+			t.Errorf("%q: output %q differs from input", tc.in, got)
+		}
+		if !reflect.DeepEqual(*stats, tc.want) {
+			t.Errorf("%q: got %+v; want %+v", tc.in, *stats, tc.want)
+		}
+	}
+}
+
+func TestTextStatsPassThrough(t *testing.T) {
+	testPassThrough(t, func() Transformer {
+		_, tr := NewTextStatsCollector()
+		return tr
+	})
+}
+
+func TestTextStatsBoundary(t *testing.T) {
+	// Words and runes spanning the internal buffers of transform.String.
+	in := strings.Repeat("thé qüick\n", 100)
+	stats, tr := NewTextStatsCollector()
+	tr.String(in)
+	want := TextStats{
+		Runes: 1000, Bytes: 1200, Lines: 100, Words: 200,
+		UniqueRunes: map[rune]int{
+			't': 100, 'h': 100, 'é': 100, ' ': 100, 'q': 100,
+			'ü': 100, 'i': 100, 'c': 100, 'k': 100, '\n': 100,
+		},
+	}
+	if !reflect.DeepEqual(*stats, want) {
+		t.Errorf("got %+v; want %+v", *stats, want)
+	}
+
+	tr.Reset()
+	if want := (TextStats{UniqueRunes: map[rune]int{}}); !reflect.DeepEqual(*stats, want) {
+		t.Errorf("after Reset: got %+v; want %+v", *stats, want)
+	}
+}
+
+func BenchmarkTextStats(b *testing.B) {
+	for _, n := range []int{1, 10, 100} {
+		src := []byte(strings.Repeat("Thé qüick brøwn føx.\n", n))
+		dst := make([]byte, len(src))
+		b.Run(fmt.Sprint(len(src)), func(b *testing.B) {
+			b.SetBytes(int64(len(src)))
+			_, t := NewTextStatsCollector()
+			for i := 0; i < b.N; i++ {
+				t.Transform(dst, src, true)
+			}
+		})
+	}
+}
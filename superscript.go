@@ -0,0 +1,97 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// ToSuperscript returns a Transformer that converts ASCII digits and those
+// lowercase ASCII letters that have a superscript form to that form. All other
+// runes are left unchanged.
+func ToSuperscript() Transformer { return MapRune(mapTable(superscripts)) }
+
+// FromSuperscript returns a Transformer that is the inverse of ToSuperscript.
+func FromSuperscript() Transformer { return MapRune(mapTable(fromSuperscripts)) }
+
+// ToSubscript returns a Transformer that converts ASCII digits and those
+// lowercase ASCII letters that have a subscript form to that form. All other
+// runes are left unchanged.
+func ToSubscript() Transformer { return MapRune(mapTable(subscripts)) }
+
+// FromSubscript returns a Transformer that is the inverse of ToSubscript.
+func FromSubscript() Transformer { return MapRune(mapTable(fromSubscripts)) }
+
+var (
+	fromSuperscripts = invert(superscripts)
+	fromSubscripts   = invert(subscripts)
+)
+
+var superscripts = map[rune]rune{
+	'0': '⁰',
+	'1': '¹',
+	'2': '²',
+	'3': '³',
+	'4': '⁴',
+	'5': '⁵',
+	'6': '⁶',
+	'7': '⁷',
+	'8': '⁸',
+	'9': '⁹',
+
+	// There is no superscript q.
+	'a': 'ᵃ',
+	'b': 'ᵇ',
+	'c': 'ᶜ',
+	'd': 'ᵈ',
+	'e': 'ᵉ',
+	'f': 'ᶠ',
+	'g': 'ᵍ',
+	'h': 'ʰ',
+	'i': 'ⁱ',
+	'j': 'ʲ',
+	'k': 'ᵏ',
+	'l': 'ˡ',
+	'm': 'ᵐ',
+	'n': 'ⁿ',
+	'o': 'ᵒ',
+	'p': 'ᵖ',
+	'r': 'ʳ',
+	's': 'ˢ',
+	't': 'ᵗ',
+	'u': 'ᵘ',
+	'v': 'ᵛ',
+	'w': 'ʷ',
+	'x': 'ˣ',
+	'y': 'ʸ',
+	'z': 'ᶻ',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀',
+	'1': '₁',
+	'2': '₂',
+	'3': '₃',
+	'4': '₄',
+	'5': '₅',
+	'6': '₆',
+	'7': '₇',
+	'8': '₈',
+	'9': '₉',
+
+	'a': 'ₐ',
+	'e': 'ₑ',
+	'h': 'ₕ',
+	'i': 'ᵢ',
+	'j': 'ⱼ',
+	'k': 'ₖ',
+	'l': 'ₗ',
+	'm': 'ₘ',
+	'n': 'ₙ',
+	'o': 'ₒ',
+	'p': 'ₚ',
+	'r': 'ᵣ',
+	's': 'ₛ',
+	't': 'ₜ',
+	'u': 'ᵤ',
+	'v': 'ᵥ',
+	'x': 'ₓ',
+}
@@ -0,0 +1,60 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestSuperscript(t *testing.T) {
+	testCases := []struct {
+		t       Transformer
+		in, out string
+	}{
+		{ToSuperscript(), "0123456789", "⁰¹²³⁴⁵⁶⁷⁸⁹"},
+		{ToSubscript(), "0123456789", "₀₁₂₃₄₅₆₇₈₉"},
+		{ToSuperscript(), "x2+y2", "ˣ²+ʸ²"},
+		{ToSubscript(), "H2O", "H₂O"},
+		{ToSuperscript(), "qQ-", "qQ-"},
+		{ToSubscript(), "bzZ", "bzZ"},
+		{FromSuperscript(), "E=mc²", "E=mc2"},
+		{FromSubscript(), "CO₂ aₙ", "CO2 an"},
+		{FromSuperscript(), "123", "123"},
+	}
+	for i, tc := range testCases {
+		if got := tc.t.String(tc.in); got != tc.out {
+			t.Errorf("%d: got %q; want %q", i, got, tc.out)
+		}
+	}
+}
+
+func TestSuperscriptTables(t *testing.T) {
+	// All mapped runes are compatibility variants of the original.
+	for _, table := range []map[rune]rune{superscripts, subscripts} {
+		for r, x := range table {
+			if got := norm.NFKC.String(string(x)); got != string(r) {
+				t.Errorf("%U: NFKC(%U) is %q; want %q", r, x, got, r)
+			}
+		}
+	}
+	if len(fromSuperscripts) != len(superscripts) {
+		t.Errorf("superscripts: mapping is not one-to-one")
+	}
+	if len(fromSubscripts) != len(subscripts) {
+		t.Errorf("subscripts: mapping is not one-to-one")
+	}
+}
+
+func TestSuperscriptRoundTrip(t *testing.T) {
+	inputs := []string{"0123456789", "abcdefghijklmnopqrstuvwxyz", "aeox 42"}
+	roundTrip(t, ToSuperscript(), FromSuperscript(), inputs[:1])
+	roundTrip(t, ToSubscript(), FromSubscript(), inputs[:1])
+
+	// Letters without a superscript or subscript form are left unchanged.
+	roundTrip(t, ToSuperscript(), FromSuperscript(), inputs)
+	roundTrip(t, ToSubscript(), FromSubscript(), inputs)
+}
@@ -0,0 +1,97 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package textutiltest provides utilities for testing Rewriters.
+package textutiltest // import "github.com/mpvl/textutil/textutiltest"
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mpvl/textutil"
+)
+
+// DefaultSzDst is the size of the destination buffer used by Check if SzDst is
+// zero.
+const DefaultSzDst = 10240
+
+// A RewriterTest describes a single call to the Transform and Span methods of
+// a Transformer created from a Rewriter for use in table-driven tests.
+type RewriterTest struct {
+	Desc string
+
+	// In is the input passed to Transform and Span.
+	In string
+
+	// AtEOF is the value of atEOF passed to Transform and Span.
+	AtEOF bool
+
+	// SzDst is the size of the destination buffer passed to Transform. If it
+	// is 0, DefaultSzDst is used.
+	SzDst int
+
+	// Out and Err are the expected results of Transform. Err is compared
+	// using errors.Is, as errors set by a Rewriter are wrapped in a
+	// textutil.TextError.
+	Out string
+	Err error
+
+	// OutFull is the expected output for the complete input: Out followed by
+	// the output of a second call to Transform for the remaining input with
+	// atEOF set and a destination of DefaultSzDst bytes.
+	OutFull string
+
+	// NSpan and ErrSpan are the expected results of Span. If NSpan is 0, the
+	// length of the common prefix of In and OutFull is expected.
+	NSpan   int
+	ErrSpan error
+}
+
+// Check runs the test for a Transformer created from r using
+// textutil.NewTransformer. It checks the results of Transform, of a second
+// call to Transform for the remaining input, and of Span after a Reset. Finally
+// it runs Transform once more after another Reset to verify that it gives the
+// same result as the first time. Failures are reported using t.Errorf.
+func (tt *RewriterTest) Check(t *testing.T, r textutil.Rewriter) {
+	t.Helper()
+	tr := textutil.NewTransformer(r)
+	szDst := tt.SzDst
+	if szDst == 0 {
+		szDst = DefaultSzDst
+	}
+	src := []byte(tt.In)
+
+	dst := make([]byte, szDst)
+	nDst, nSrc, err := tr.Transform(dst, src, tt.AtEOF)
+	if !errors.Is(err, tt.Err) {
+		t.Errorf("%s:error: got %v; want %v", tt.Desc, err, tt.Err)
+	}
+	if got := string(dst[:nDst]); got != tt.Out {
+		t.Errorf("%s:out: got %q; want %q", tt.Desc, got, tt.Out)
+	}
+
+	// Transform the remainder of the input to test the nSrc return value.
+	out := make([]byte, len(dst[:nDst])+DefaultSzDst)
+	n := copy(out, dst[:nDst])
+	m, _, _ := tr.Transform(out[n:], src[nSrc:], true)
+	if got := string(out[:n+m]); got != tt.OutFull {
+		t.Errorf("%s:outFull: got %q; want %q", tt.Desc, got, tt.OutFull)
+	}
+
+	tr.Reset()
+	p := tt.NSpan
+	if p == 0 {
+		for ; p < len(tt.In) && p < len(tt.OutFull) && tt.In[p] == tt.OutFull[p]; p++ {
+		}
+	}
+	if n, err := tr.Span(src, tt.AtEOF); n != p || err != tt.ErrSpan {
+		t.Errorf("%s:span: got %d, %v; want %d, %v", tt.Desc, n, err, p, tt.ErrSpan)
+	}
+
+	tr.Reset()
+	nDst, _, err = tr.Transform(dst, src, tt.AtEOF)
+	if got := string(dst[:nDst]); got != tt.Out || !errors.Is(err, tt.Err) {
+		t.Errorf("%s:after Reset: got %q, %v; want %q, %v", tt.Desc, got, err, tt.Out, tt.Err)
+	}
+}
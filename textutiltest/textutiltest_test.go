@@ -0,0 +1,49 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutiltest
+
+import (
+	"testing"
+
+	"github.com/mpvl/textutil"
+	"golang.org/x/text/transform"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := []RewriterTest{{
+		Desc:    "unchanged",
+		In:      "a b",
+		AtEOF:   true,
+		Out:     "a b",
+		OutFull: "a b",
+	}, {
+		Desc:    "changed",
+		In:      "a  b",
+		AtEOF:   true,
+		Out:     "a b",
+		OutFull: "a b",
+		ErrSpan: transform.ErrEndOfSpan,
+	}, {
+		Desc:    "short destination",
+		In:      "ab  c",
+		AtEOF:   true,
+		SzDst:   2,
+		Out:     "ab",
+		OutFull: "ab c",
+		Err:     transform.ErrShortDst,
+		ErrSpan: transform.ErrEndOfSpan,
+	}, {
+		Desc:    "explicit span",
+		In:      "  a",
+		AtEOF:   true,
+		Out:     " a",
+		OutFull: " a",
+		ErrSpan: transform.ErrEndOfSpan,
+		NSpan:   1,
+	}}
+	for _, tt := range testCases {
+		tt.Check(t, textutil.NewRunDeduplicator(textutil.SpaceRunes))
+	}
+}
@@ -4,7 +4,14 @@
 
 package textutil
 
-import "golang.org/x/text/transform"
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/text/transform"
+)
 
 // A Transformer wraps a transform.SpanningTransformer providing convenience
 // methods for most of the functionality in the tranform package.
@@ -12,6 +19,13 @@ type Transformer struct {
 	transform.SpanningTransformer
 }
 
+// NewIdentityTransformer returns a Transformer that copies its input verbatim.
+// Its Span method always reports the complete input as unchanged. It wraps
+// transform.Nop.
+func NewIdentityTransformer() Transformer {
+	return Transformer{transform.Nop}
+}
+
 // Transform calls the Transform method of the underlying Transformer.
 func (t Transformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
 	return t.SpanningTransformer.Transform(dst, src, atEOF)
@@ -26,21 +40,337 @@ func (t Transformer) Span(b []byte, atEOF bool) (n int, err error) {
 func (t Transformer) Reset() { t.SpanningTransformer.Reset() }
 
 // String applies t to s and returns the result. This methods wraps
-// transform.String. It returns the empty string if any error occurred.
+// transform.String. It returns the empty string if any error occurred. Use
+// StringErr to distinguish errors from empty results.
 func (t Transformer) String(s string) string {
-	s, _, err := transform.String(t.SpanningTransformer, s)
+	s, err := t.StringErr(s)
 	if err != nil {
 		return ""
 	}
 	return s
 }
 
+// StringErr applies t to s and returns the result and any error encountered.
+// If an error occurs, the result holds the output produced up to that point,
+// as with transform.String.
+func (t Transformer) StringErr(s string) (string, error) {
+	s, _, err := transform.String(t.SpanningTransformer, s)
+	return s, err
+}
+
+// MustString is like StringErr but panics if an error occurs, with an error
+// wrapping it. It simplifies the use of Transformers that cannot fail for the
+// given input, such as in the initialization of global variables.
+func (t Transformer) MustString(s string) string {
+	s, err := t.StringErr(s)
+	if err != nil {
+		panic(fmt.Errorf("textutil: MustString: %w", err))
+	}
+	return s
+}
+
+// TransformStrings converts each element of in independently using t, which
+// is reset for each element, and returns the results. On the first error, it
+// returns the results for the elements preceding the failing one along with
+// the error.
+func (t Transformer) TransformStrings(in []string) ([]string, error) {
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		s, err := t.StringErr(s)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// TransformStringsLenient is like TransformStrings, but converts elements for
+// which an error occurs to the empty string, like String.
+func (t Transformer) TransformStringsLenient(in []string) []string {
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = t.String(s)
+	}
+	return out
+}
+
 // Bytes returns a new byte slice with the result of converting b using t. It
-// calls Reset on t. It returns nil if any error was found.
+// calls Reset on t. It returns nil if any error was found. Use BytesErr to
+// distinguish errors from empty results.
 func (t Transformer) Bytes(b []byte) []byte {
-	b, _, err := transform.Bytes(t, b)
+	b, err := t.BytesErr(b)
 	if err != nil {
 		return nil
 	}
 	return b
 }
+
+// BytesErr returns a new byte slice with the result of converting b using t
+// and any error encountered. It calls Reset on t. If an error occurs, the
+// result holds the output produced up to that point, as with transform.Bytes.
+func (t Transformer) BytesErr(b []byte) ([]byte, error) {
+	b, _, err := transform.Bytes(t, b)
+	return b, err
+}
+
+// MustBytes is like BytesErr but panics if an error occurs, with an error
+// wrapping it.
+func (t Transformer) MustBytes(b []byte) []byte {
+	b, err := t.BytesErr(b)
+	if err != nil {
+		panic(fmt.Errorf("textutil: MustBytes: %w", err))
+	}
+	return b
+}
+
+// SpanString returns the length of the longest prefix of s that t leaves
+// unchanged, treating s as the complete input. It calls Reset on t. The error
+// is transform.ErrEndOfSpan if the returned length is less than len(s) and t
+// would change the input that follows.
+func (t Transformer) SpanString(s string) (n int, err error) {
+	t.Reset()
+	return t.Span([]byte(s), true)
+}
+
+// SpanBytes is like SpanString, but for byte slices.
+func (t Transformer) SpanBytes(b []byte) (n int, err error) {
+	t.Reset()
+	return t.Span(b, true)
+}
+
+// AppendString appends the result of converting src using t to dst. It calls
+// Reset on t. If an error occurs, the returned string holds the output
+// produced up to that point.
+func (t Transformer) AppendString(dst, src string) (string, error) {
+	b := make([]byte, len(dst), len(dst)+len(src))
+	copy(b, dst)
+	b, _, err := transform.Append(t, b, []byte(src))
+	return string(b), err
+}
+
+// AppendBytes appends the result of converting src using t to dst and returns
+// the extended slice. It calls Reset on t. If an error occurs, the returned
+// slice holds the output produced up to that point.
+func (t Transformer) AppendBytes(dst, src []byte) ([]byte, error) {
+	if cap(dst)-len(dst) < len(src) {
+		b := make([]byte, len(dst), len(dst)+len(src))
+		dst = b[:copy(b, dst)]
+	}
+	dst, _, err := transform.Append(t, dst, src)
+	return dst, err
+}
+
+// NewReader returns a new io.Reader that reads from r and returns the result
+// of converting its input using t. It calls Reset on t. This method wraps
+// transform.NewReader.
+//
+// The returned Reader also implements io.WriterTo, so that io.Copy writes the
+// converted input to its destination without an intermediate buffer of its
+// own.
+func (t Transformer) NewReader(r io.Reader) io.Reader {
+	t.Reset()
+	return &transformReader{Reader: transform.NewReader(r, t.SpanningTransformer)}
+}
+
+type transformReader struct {
+	*transform.Reader
+	buf []byte
+}
+
+// readerBufSize is the size of the buffer used by WriteTo. It matches the size
+// of the buffers of transform.Reader.
+const readerBufSize = 4096
+
+// WriteTo implements io.WriterTo. It writes converted input to w until the
+// input is exhausted or an error occurs and returns the number of bytes
+// written.
+func (r *transformReader) WriteTo(w io.Writer) (n int64, err error) {
+	if r.buf == nil {
+		r.buf = make([]byte, readerBufSize)
+	}
+	for {
+		m, rerr := r.Read(r.buf)
+		if m > 0 {
+			k, werr := w.Write(r.buf[:m])
+			n += int64(k)
+			switch {
+			case werr != nil:
+				return n, werr
+			case k < m:
+				return n, io.ErrShortWrite
+			}
+		}
+		switch {
+		case rerr == io.EOF:
+			return n, nil
+		case rerr != nil:
+			return n, rerr
+		}
+	}
+}
+
+// NewWriter returns a new io.WriteCloser that converts the data written to it
+// using t and writes the result to w. Close must be called to flush any
+// buffered data; it does not close w. NewWriter calls Reset on t. This method
+// wraps transform.NewWriter.
+func (t Transformer) NewWriter(w io.Writer) io.WriteCloser {
+	t.Reset()
+	return transform.NewWriter(w, t.SpanningTransformer)
+}
+
+// Pipe returns the ends of a synchronous in-memory pipe that converts the data
+// written to w using t in a separate goroutine and provides the result for
+// reading from r. Closing w flushes any buffered data, after which reads from r
+// return io.EOF. If t returns an error, reads from r return that error. If r
+// is closed, writes to w fail. Pipe calls Reset on t. Use WithContext to stop
+// the conversion once a context is done.
+func (t Transformer) Pipe() (r *io.PipeReader, w *io.PipeWriter) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	src := t.NewReader(inR)
+	go func() {
+		_, err := io.Copy(outW, src)
+		// Unblock any writers if the output was closed or on error.
+		inR.CloseWithError(err)
+		outW.CloseWithError(err)
+	}()
+	return outR, inW
+}
+
+// Clone returns a new Transformer that starts with a copy of the state of t,
+// such as for processing alternative continuations of the same input. It
+// panics unless t was created from a Rewriter that implements Cloner, as are
+// the Rewriters of NewTransformerFromFunc, MapRune, NormalizeEllipsis and
+// CollapseRuns, among others.
+func (t Transformer) Clone() Transformer {
+	r, ok := t.SpanningTransformer.(*rewriter)
+	if !ok {
+		panic("textutil: Clone called on Transformer not created from a Rewriter")
+	}
+	c, ok := r.rewrite.(Cloner)
+	if !ok {
+		panic("textutil: Clone called on Transformer with a Rewriter that does not implement Cloner")
+	}
+	return Transformer{&rewriter{rewrite: c.Clone(), ctx: r.ctx, invalid: r.invalid}}
+}
+
+// WithContext returns a Transformer that stops with ctx.Err() once ctx is
+// done, returning the output produced so far. Transformers created from a
+// Rewriter check ctx before each call to Rewrite, and share the Rewriter with
+// t. Other Transformers check ctx before each call to Transform.
+func (t Transformer) WithContext(ctx context.Context) Transformer {
+	if r, ok := t.SpanningTransformer.(*rewriter); ok {
+		return Transformer{&rewriter{rewrite: r.rewrite, ctx: ctx, invalid: r.invalid}}
+	}
+	return Transformer{&withContext{t.SpanningTransformer, ctx}}
+}
+
+type withContext struct {
+	transform.SpanningTransformer
+	ctx context.Context
+}
+
+func (t *withContext) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if err := t.ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	return t.SpanningTransformer.Transform(dst, src, atEOF)
+}
+
+// Synchronized returns a Transformer that holds l during each call to the
+// methods of t.
+//
+// State that a Transformer updates as it converts its input, such as the counts
+// of WordFrequencyCounter, may only be accessed while the Transformer is not in
+// use. Wrapping it with Synchronized allows accessing this state from other
+// goroutines while transforming, by holding l.
+func Synchronized(t Transformer, l sync.Locker) Transformer {
+	return Transformer{&synchronized{t.SpanningTransformer, l}}
+}
+
+type synchronized struct {
+	t transform.SpanningTransformer
+	l sync.Locker
+}
+
+func (s *synchronized) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.t.Transform(dst, src, atEOF)
+}
+
+func (s *synchronized) Span(src []byte, atEOF bool) (n int, err error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.t.Span(src, atEOF)
+}
+
+func (s *synchronized) Reset() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.t.Reset()
+}
+
+// ChainTransformers returns a Transformer that applies the given Transformers
+// in sequence. It wraps transform.Chain.
+func ChainTransformers(t ...Transformer) Transformer {
+	a := make([]transform.Transformer, len(t))
+	for i, x := range t {
+		a[i] = x.SpanningTransformer
+	}
+	return Transformer{&chain{transform.Chain(a...), t}}
+}
+
+// Then returns a Transformer that applies t and then other. It is equivalent to
+// ChainTransformers(t, other).
+func (t Transformer) Then(other Transformer) Transformer {
+	return ChainTransformers(t, other)
+}
+
+// Repeat returns a Transformer that applies t n times in sequence, passing the
+// output of each application as the input of the next. Repeat(0) returns an
+// identity Transformer and Repeat(1) returns t. For larger n, the result
+// chains t with n-1 clones of t, so Repeat panics if t cannot be cloned, as
+// with Clone. Repeat also panics if n is negative.
+func (t Transformer) Repeat(n int) Transformer {
+	switch {
+	case n < 0:
+		panic("textutil: Repeat called with negative count")
+	case n == 0:
+		return NewIdentityTransformer()
+	case n == 1:
+		return t
+	}
+	a := make([]Transformer, n)
+	a[0] = t
+	for i := 1; i < n; i++ {
+		a[i] = t.Clone()
+	}
+	return ChainTransformers(a...)
+}
+
+// chain adds a Span method to the result of transform.Chain.
+type chain struct {
+	transform.Transformer
+	t []Transformer
+}
+
+// Span reports the longest prefix of src that is left unchanged by all
+// Transformers of the chain.
+func (c *chain) Span(src []byte, atEOF bool) (n int, err error) {
+	n = len(src)
+	for _, t := range c.t {
+		m, e := t.Span(src[:n], atEOF && n == len(src))
+		if e == transform.ErrShortSrc && n < len(src) {
+			// The remainder of the input to this Transformer differs from
+			// src, so we cannot get more input.
+			e = transform.ErrEndOfSpan
+		}
+		if e != nil {
+			err = e
+		}
+		n = m
+	}
+	return n, err
+}
@@ -5,8 +5,17 @@
 package textutil
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
+	"unicode"
 
 	"golang.org/x/text/transform"
 )
@@ -23,7 +32,7 @@ type transformTest struct {
 	outFull string // transform of entire input string
 	err     error
 	errSpan error
-	nSpan   int
+	nSpan   int // if not 0, the expected span; -1 for an empty span
 
 	// t transform.SpanningTransformer
 	t transform.SpanningTransformer
@@ -40,7 +49,7 @@ func (tt *transformTest) check(t *testing.T, i int) {
 	dst := make([]byte, tt.szDst)
 	src := []byte(tt.in)
 	nDst, nSrc, err := tt.t.Transform(dst, src, tt.atEOF)
-	if err != tt.err {
+	if !errors.Is(err, tt.err) {
 		t.Errorf("%d:%s:error: got %v; want %v", i, tt.desc, err, tt.err)
 	}
 	if got := string(dst[:nDst]); got != tt.out {
@@ -63,7 +72,536 @@ func (tt *transformTest) check(t *testing.T, i int) {
 	if tt.nSpan != 0 {
 		p = tt.nSpan
 	}
+	if p < 0 {
+		p = 0
+	}
 	if n, err = tt.t.Span([]byte(tt.in), tt.atEOF); n != p || err != tt.errSpan {
 		t.Errorf("%d:%s:span: got %d, %v; want %d, %v", i, tt.desc, n, err, p, tt.errSpan)
 	}
 }
+
+// spanTransform returns the output for in of calling Span and then, without a
+// call to Reset, Transform for the input following the span, as, for instance,
+// transform.String does.
+func spanTransform(t transform.SpanningTransformer, in string) (string, error) {
+	t.Reset()
+	src := []byte(in)
+	n, err := t.Span(src, true)
+	if err != nil && err != transform.ErrEndOfSpan {
+		return in[:n], err
+	}
+	dst := make([]byte, large)
+	nDst, _, err := t.Transform(dst, src[n:], true)
+	return in[:n] + string(dst[:nDst]), err
+}
+
+// roundTrip checks that applying t and then inverse to each of the inputs
+// yields the original input.
+func roundTrip(t *testing.T, tr, inverse Transformer, inputs []string) {
+	for _, in := range inputs {
+		out := tr.String(in)
+		if got := inverse.String(out); got != in {
+			t.Errorf("%q: got %q via %q; want %q", in, got, out, in)
+		}
+	}
+}
+
+func TestChainTransformers(t *testing.T) {
+	upper := MapRune(unicode.ToUpper)
+	testCases := []transformTest{{
+		desc:    "chain",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ABC de F",
+		out:     "ABCDEF",
+		outFull: "ABCDEF",
+		t:       ChainTransformers(upper, RemoveFunc(unicode.IsSpace)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "span shortened by later transformer",
+		szDst:   large,
+		atEOF:   true,
+		in:      "AB CDe",
+		out:     "ABCDE",
+		outFull: "ABCDE",
+		t:       ChainTransformers(upper, RemoveFunc(unicode.IsSpace)),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "identity",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ABC",
+		out:     "ABC",
+		outFull: "ABC",
+		t:       ChainTransformers(upper, RemoveFunc(unicode.IsSpace)),
+	}, {
+		desc:    "empty chain",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "abc",
+		outFull: "abc",
+		t:       ChainTransformers(),
+	}, {
+		desc:    "incomplete input",
+		szDst:   large,
+		atEOF:   false,
+		in:      "AB\r",
+		out:     "AB",
+		outFull: "AB\n",
+		t:       ChainTransformers(upper, NormalizeLineBreaks('\n')),
+		errSpan: transform.ErrShortSrc,
+		nSpan:   2,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel the context while rewriting the x.
+	tr := NewTransformerFromFunc(func(s State) {
+		r, _ := s.ReadRune()
+		if r == 'x' {
+			cancel()
+		}
+		s.WriteRune(unicode.ToUpper(r))
+	}).WithContext(ctx)
+
+	dst := make([]byte, large)
+	nDst, nSrc, err := tr.Transform(dst, []byte("abxcd"), true)
+	if got, want := string(dst[:nDst]), "ABX"; got != want || nSrc != 3 || err != context.Canceled {
+		t.Errorf("got %q, %d, %v; want %q, 3, %v", got, nSrc, err, want, context.Canceled)
+	}
+
+	// A context that is already canceled stops the transform immediately.
+	nDst, nSrc, err = tr.Transform(dst, []byte("abc"), true)
+	if nDst != 0 || nSrc != 0 || err != context.Canceled {
+		t.Errorf("canceled: got %d, %d, %v; want 0, 0, %v", nDst, nSrc, err, context.Canceled)
+	}
+	upper := MapRune(unicode.ToUpper)
+	if _, _, err := upper.WithContext(ctx).Transform(dst, []byte("abc"), true); err != context.Canceled {
+		t.Errorf("canceled: got %v; want %v", err, context.Canceled)
+	}
+	if got := ChainTransformers(upper).WithContext(ctx).String("abc"); got != "" {
+		t.Errorf("canceled chain: got %q; want %q", got, "")
+	}
+
+	// Transforms complete normally before the context is canceled.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	if got, want := upper.WithContext(ctx).String("abc"), "ABC"; got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+	if got, want := ChainTransformers(upper).WithContext(ctx).String("abc"), "ABC"; got != want {
+		t.Errorf("chain: got %q; want %q", got, want)
+	}
+}
+
+func TestNewContextTransformer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// The Rewriter blocks after the first rune until the context is done.
+	var n int
+	tr := NewContextTransformer(ctx, rewriterFunc(func(s State) {
+		if n++; n == 2 {
+			<-ctx.Done()
+		}
+		r, _ := s.ReadRune()
+		s.WriteRune(unicode.ToUpper(r))
+	}))
+	got, err := tr.StringErr(strings.Repeat("a", 1<<20))
+	if got != "AA" || err != context.DeadlineExceeded {
+		t.Errorf("got %q, %v; want %q, %v", got, err, "AA", context.DeadlineExceeded)
+	}
+	if n != 2 {
+		t.Errorf("got %d calls to Rewrite; want 2", n)
+	}
+}
+
+func TestClone(t *testing.T) {
+	dst := make([]byte, large)
+	run := func(tr Transformer, in string) string {
+		n, _, _ := tr.Transform(dst, []byte(in), false)
+		return string(dst[:n])
+	}
+	isSpace := func(r rune) bool { return r == ' ' }
+	orig := CollapseRuns(isSpace, '_')
+	if got := run(orig, "a "); got != "a_" {
+		t.Fatalf("got %q; want %q", got, "a_")
+	}
+
+	// Both Transformers continue the run of spaces.
+	clone := orig.Clone()
+	if got := run(clone, " b"); got != "b" {
+		t.Errorf("clone: got %q; want %q", got, "b")
+	}
+	// The clone is no longer in a run, but the original is.
+	if got := run(orig, " "); got != "" {
+		t.Errorf("original: got %q; want %q", got, "")
+	}
+	if got := run(clone, " "); got != "_" {
+		t.Errorf("clone after write: got %q; want %q", got, "_")
+	}
+
+	if got := MapRune(unicode.ToUpper).Clone().String("abc"); got != "ABC" {
+		t.Errorf("stateless: got %q; want %q", got, "ABC")
+	}
+
+	for _, tr := range []Transformer{
+		RequireMaxLength(1),
+		NewTransformer(NewStatefulRewriterFunc(func(State) {}, func() {})),
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%T: did not panic", tr.SpanningTransformer)
+				}
+			}()
+			tr.Clone()
+		}()
+	}
+}
+
+func TestIdentityTransformer(t *testing.T) {
+	for _, in := range []string{"", "abc", "a\xffb\u00e9"} {
+		tr := NewIdentityTransformer()
+		if got := tr.String(in); got != in {
+			t.Errorf("%q: got %q", in, got)
+		}
+		if n, err := tr.Span([]byte(in), true); n != len(in) || err != nil {
+			t.Errorf("%q: span: got %d, %v; want %d, <nil>", in, n, err, len(in))
+		}
+	}
+}
+
+func TestThen(t *testing.T) {
+	tr := MapRune(unicode.ToLower).
+		Then(StripDiacritics()).
+		Then(NewTransformer(NewURLPercentEncoder("")))
+	in := "Cr\u00e8me Br\u00fbl\u00e9e & Caf\u00e9"
+	want := "creme%20brulee%20%26%20cafe"
+	if got := tr.String(in); got != want {
+		t.Errorf("String: got %q; want %q", got, want)
+	}
+	if got := string(tr.Bytes([]byte(in))); got != want {
+		t.Errorf("Bytes: got %q; want %q", got, want)
+	}
+	got, err := ioutil.ReadAll(tr.NewReader(iotest.OneByteReader(strings.NewReader(in))))
+	if string(got) != want || err != nil {
+		t.Errorf("NewReader: got %q, %v; want %q, <nil>", got, err, want)
+	}
+	if n, err := tr.Span([]byte("abc"), true); n != 3 || err != nil {
+		t.Errorf("Span: got %d, %v; want 3, <nil>", n, err)
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	rot13 := NewTransformer(NewROT13Rewriter())
+	next := MapRune(func(r rune) rune { return r + 1 })
+	testCases := []struct {
+		t    Transformer
+		n    int
+		in   string
+		want string
+	}{
+		{rot13, 0, "Hello, World!", "Hello, World!"},
+		{rot13, 1, "Hello, World!", "Uryyb, Jbeyq!"},
+		{rot13, 2, "Hello, World!", "Hello, World!"},
+		{rot13, 3, "Hello, World!", "Uryyb, Jbeyq!"},
+		{next, 3, "abc", "def"},
+		{next, 10, strings.Repeat("a", 300), strings.Repeat("k", 300)},
+	}
+	for i, tc := range testCases {
+		tr := tc.t.Repeat(tc.n)
+		if got := tr.String(tc.in); got != tc.want {
+			t.Errorf("%d: got %q; want %q", i, got, tc.want)
+		}
+		if got, err := transformChunks(tr, tc.in, 1, 4); got != tc.want || err != nil {
+			t.Errorf("%d: chunks: got %q, %v; want %q, <nil>", i, got, err, tc.want)
+		}
+	}
+
+	for _, f := range []func(){
+		func() { rot13.Repeat(-1) },
+		func() { RequireMaxLength(1).Repeat(2) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("did not panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestMust(t *testing.T) {
+	upper := MapRune(unicode.ToUpper)
+	if got := upper.MustString("abc"); got != "ABC" {
+		t.Errorf("MustString: got %q; want %q", got, "ABC")
+	}
+	if got := string(upper.MustBytes([]byte("abc"))); got != "ABC" {
+		t.Errorf("MustBytes: got %q; want %q", got, "ABC")
+	}
+
+	tooLong := RequireMaxLength(2)
+	for _, f := range []func(){
+		func() { tooLong.MustString("abc") },
+		func() { tooLong.MustBytes([]byte("abc")) },
+	} {
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrTooLong) {
+					t.Errorf("got panic %v; want error wrapping %v", err, ErrTooLong)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestSpanString(t *testing.T) {
+	testCases := []struct {
+		t   Transformer
+		in  string
+		n   int
+		err error
+	}{
+		{NewIdentityTransformer(), "abc def", len("abc def"), nil},
+		{NewTransformer(NilRewriter), "abc def", len("abc def"), nil},
+		{NewTransformer(NilRewriter), "", 0, nil},
+		{NormalizeEllipsis(), "a\u00e9...b", len("a\u00e9"), transform.ErrEndOfSpan},
+		{MapRune(unicode.ToUpper), "AB\u00c9c", len("AB\u00c9"), transform.ErrEndOfSpan},
+	}
+	for i, tc := range testCases {
+		if n, err := tc.t.SpanString(tc.in); n != tc.n || err != tc.err {
+			t.Errorf("%d:SpanString: got %d, %v; want %d, %v", i, n, err, tc.n, tc.err)
+		}
+		if n, err := tc.t.SpanBytes([]byte(tc.in)); n != tc.n || err != tc.err {
+			t.Errorf("%d:SpanBytes: got %d, %v; want %d, %v", i, n, err, tc.n, tc.err)
+		}
+	}
+}
+
+func TestTransformStrings(t *testing.T) {
+	// Reset is needed between elements, as otherwise the count accumulates.
+	tr := RequireMaxLength(3)
+	in := []string{"abc", "de", "", "fghi", "j"}
+
+	got, err := tr.TransformStrings(in[:3])
+	if want := in[:3]; !reflect.DeepEqual(got, want) || err != nil {
+		t.Errorf("got %q, %v; want %q, <nil>", got, err, want)
+	}
+	got, err = tr.TransformStrings(in)
+	if want := in[:3]; !reflect.DeepEqual(got, want) || err != ErrTooLong {
+		t.Errorf("error: got %q, %v; want %q, %v", got, err, want, ErrTooLong)
+	}
+	got = tr.TransformStringsLenient(in)
+	if want := []string{"abc", "de", "", "", "j"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lenient: got %q; want %q", got, want)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	upper := MapRune(unicode.ToUpper)
+	if got, err := upper.AppendString("abc ", "déf"); got != "abc DÉF" || err != nil {
+		t.Errorf("AppendString: got %q, %v; want %q, <nil>", got, err, "abc DÉF")
+	}
+	if got, err := upper.AppendString("", ""); got != "" || err != nil {
+		t.Errorf("AppendString empty: got %q, %v; want %q, <nil>", got, err, "")
+	}
+
+	// AppendBytes uses the capacity of dst.
+	dst := make([]byte, 3, 16)
+	copy(dst, "abc")
+	got, err := upper.AppendBytes(dst, []byte("déf"))
+	if string(got) != "abcDÉF" || err != nil {
+		t.Errorf("AppendBytes: got %q, %v; want %q, <nil>", got, err, "abcDÉF")
+	}
+	if &got[0] != &dst[0] {
+		t.Error("AppendBytes: dst was reallocated")
+	}
+	got, err = upper.AppendBytes(nil, []byte(input))
+	if want := strings.ToUpper(input); string(got) != want || err != nil {
+		t.Errorf("AppendBytes nil: got %q, %v; want %q, <nil>", got, err, want)
+	}
+
+	// Errors are returned along with the output produced so far.
+	tooLong := RequireMaxLength(2)
+	if got, err := tooLong.AppendString("ab", "cde"); got != "abcd" || err != ErrTooLong {
+		t.Errorf("AppendString error: got %q, %v; want %q, %v", got, err, "abcd", ErrTooLong)
+	}
+	if got, err := tooLong.AppendBytes([]byte("ab"), []byte("cde")); string(got) != "abcd" || err != ErrTooLong {
+		t.Errorf("AppendBytes error: got %q, %v; want %q, %v", got, err, "abcd", ErrTooLong)
+	}
+}
+
+func TestStringErr(t *testing.T) {
+	myError := errors.New("my error")
+	tr := NewTransformerFromFunc(func(s State) {
+		r, _ := s.ReadRune()
+		if r == '!' {
+			s.SetError(myError)
+		}
+		s.WriteRune(unicode.ToUpper(r))
+	})
+	testCases := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"", "", nil},
+		{"abc", "ABC", nil},
+		{"ab!c", "AB", myError},
+		{"!", "", myError},
+	}
+	for _, tc := range testCases {
+		if got, err := tr.StringErr(tc.in); got != tc.want || !errors.Is(err, tc.err) {
+			t.Errorf("StringErr(%q): got %q, %v; want %q, %v", tc.in, got, err, tc.want, tc.err)
+		}
+		if got, err := tr.BytesErr([]byte(tc.in)); string(got) != tc.want || !errors.Is(err, tc.err) {
+			t.Errorf("BytesErr(%q): got %q, %v; want %q, %v", tc.in, got, err, tc.want, tc.err)
+		}
+
+		// String and Bytes return empty results on error.
+		want := tc.want
+		if tc.err != nil {
+			want = ""
+		}
+		if got := tr.String(tc.in); got != want {
+			t.Errorf("String(%q): got %q; want %q", tc.in, got, want)
+		}
+		if got := tr.Bytes([]byte(tc.in)); string(got) != want || (tc.err != nil && got != nil) {
+			t.Errorf("Bytes(%q): got %q; want %q", tc.in, got, want)
+		}
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	tr := NormalizeEllipsis()
+	in := strings.Repeat("Wait... what…  ", 100)
+	want := strings.Replace(in, "...", "…", -1)
+	for i := 0; i < 2; i++ {
+		got, err := ioutil.ReadAll(tr.NewReader(iotest.OneByteReader(strings.NewReader(in))))
+		if string(got) != want || err != nil {
+			t.Errorf("%d: got %q, %v; want %q, <nil>", i, got, err, want)
+		}
+	}
+
+	tooLong := RequireMaxLength(2)
+	got, err := ioutil.ReadAll(tooLong.NewReader(strings.NewReader("abc")))
+	if string(got) != "ab" || err != ErrTooLong {
+		t.Errorf("error: got %q, %v; want %q, %v", got, err, "ab", ErrTooLong)
+	}
+}
+
+func TestReaderWriteTo(t *testing.T) {
+	in := strings.Repeat("Wait... what\u2026  ", 1000)
+	want := strings.Replace(in, "...", "\u2026", -1)
+	r := NormalizeEllipsis().NewReader(strings.NewReader(in))
+	if _, ok := r.(io.WriterTo); !ok {
+		t.Fatal("Reader does not implement io.WriterTo")
+	}
+	var buf bytes.Buffer
+	if n, err := io.Copy(&buf, r); buf.String() != want || n != int64(len(want)) || err != nil {
+		t.Errorf("got %q, %d, %v; want %q, %d, <nil>", buf.String(), n, err, want, len(want))
+	}
+
+	// WriteTo continues where Read left off.
+	r = NormalizeEllipsis().NewReader(strings.NewReader("a...b...c"))
+	p := make([]byte, 1)
+	r.Read(p)
+	buf.Reset()
+	if n, err := r.(io.WriterTo).WriteTo(&buf); buf.String() != "\u2026b\u2026c" || n != 8 || err != nil {
+		t.Errorf("after Read: got %q, %d, %v; want %q, 8, <nil>", buf.String(), n, err, "\u2026b\u2026c")
+	}
+
+	buf.Reset()
+	r = RequireMaxLength(2).NewReader(strings.NewReader("abc"))
+	if n, err := io.Copy(&buf, r); buf.String() != "ab" || n != 2 || err != ErrTooLong {
+		t.Errorf("error: got %q, %d, %v; want %q, 2, %v", buf.String(), n, err, "ab", ErrTooLong)
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	in := strings.Repeat("Wait...  what…\r\n", 100)
+	want := strings.Repeat("WAIT… WHAT…\n", 100)
+	for _, n := range []int{1, 3, len(in)} {
+		var buf bytes.Buffer
+		upper := MapRune(unicode.ToUpper).NewWriter(&buf)
+		isSpace := func(r rune) bool { return r == ' ' }
+		w := NormalizeLineBreaks('\n').NewWriter(CollapseRuns(isSpace, ' ').NewWriter(upper))
+		w = NormalizeEllipsis().NewWriter(w)
+		for s := in; s != ""; {
+			k := n
+			if k > len(s) {
+				k = len(s)
+			}
+			if _, err := w.Write([]byte(s[:k])); err != nil {
+				t.Fatalf("%d: Write: %v", n, err)
+			}
+			s = s[k:]
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%d: Close: %v", n, err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("%d: got %q; want %q", n, got, want)
+		}
+	}
+}
+
+func TestPipe(t *testing.T) {
+	in := strings.Repeat("Wait...  what\u2026\r\n", 100)
+	want := strings.Repeat("Wait\u2026  what\u2026\r\n", 100)
+	r, w := NormalizeEllipsis().Pipe()
+	go func() {
+		for s := in; s != ""; {
+			k := 7
+			if k > len(s) {
+				k = len(s)
+			}
+			if _, err := w.Write([]byte(s[:k])); err != nil {
+				t.Errorf("Write: %v", err)
+			}
+			s = s[k:]
+		}
+		w.Close()
+	}()
+	if got, err := ioutil.ReadAll(r); string(got) != want || err != nil {
+		t.Errorf("got %q, %v; want %q, <nil>", got, err, want)
+	}
+}
+
+func TestPipeError(t *testing.T) {
+	r, w := RequireMaxLength(3).Pipe()
+	done := make(chan bool)
+	go func() {
+		// The error is only reported if the input is not yet consumed.
+		w.Write([]byte("abcdef"))
+		close(done)
+	}()
+	if got, err := ioutil.ReadAll(r); string(got) != "abc" || err != ErrTooLong {
+		t.Errorf("got %q, %v; want %q, %v", got, err, "abc", ErrTooLong)
+	}
+	<-done
+
+	// Writes fail once the reader is closed. The first writes may still be
+	// consumed by the converting goroutine.
+	r, w = NormalizeEllipsis().Pipe()
+	r.Close()
+	var err error
+	for i := 0; i < 100 && err == nil; i++ {
+		_, err = w.Write([]byte("abc"))
+	}
+	if err != io.ErrClosedPipe {
+		t.Errorf("Write after Close: got %v; want %v", err, io.ErrClosedPipe)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// NewTypographicNormalizer returns a Rewriter that replaces typographic
+// punctuation with its ASCII equivalent: double quotation marks such as \u201c
+// and \u201d with ", single quotation marks and apostrophes such as \u2018 and
+// \u2019 with ', en and em dashes with -, and the horizontal ellipsis with
+// three full stops.
+//
+// The replacement is lossy: NewTypographicEnhancer restores ellipses, but not
+// quotation marks or dashes, as both kinds of dash become a single hyphen.
+func NewTypographicNormalizer() Rewriter {
+	return NewStringMapRewriter(typographicASCII)
+}
+
+var typographicASCII = map[rune]string{
+	'\u2018': "'", // left single quotation mark
+	'\u2019': "'", // right single quotation mark
+	'\u201a': "'", // single low-9 quotation mark
+	'\u201b': "'", // single high-reversed-9 quotation mark
+	'\u201c': `"`, // left double quotation mark
+	'\u201d': `"`, // right double quotation mark
+	'\u201e': `"`, // double low-9 quotation mark
+	'\u201f': `"`, // double high-reversed-9 quotation mark
+	'\u2013': "-", // en dash
+	'\u2014': "-", // em dash
+	'\u2026': "...",
+}
+
+// NewTypographicEnhancer returns a Rewriter that replaces runs of exactly two
+// hyphens with an en dash (\u2013), runs of exactly three hyphens with an em
+// dash (\u2014), and runs of exactly three full stops with a horizontal
+// ellipsis (\u2026). Other runs are written verbatim. Use StraightToTypographic
+// to convert quotation marks.
+func NewTypographicEnhancer() Rewriter {
+	return &typographicEnhancer{}
+}
+
+type typographicEnhancer struct {
+	// inRun is the full stop or hyphen if the last written rune was part of a
+	// run of four or more of them, or 0 otherwise. As for ellipsis, this
+	// avoids having to buffer arbitrarily long runs.
+	inRun rune
+}
+
+func (e *typographicEnhancer) Reset() { e.inRun = 0 }
+
+func (e *typographicEnhancer) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	switch {
+	case r != '.' && r != '-':
+		if s.WriteRune(r) {
+			e.inRun = 0
+		}
+		return
+	case e.inRun == r:
+		s.WriteRune(r)
+		return
+	}
+
+	// Determine the length of the run, up to four. ReadRune sets ErrShortSrc
+	// if the input ends prematurely.
+	n := 1
+	for ; n < 4; n++ {
+		if c, _ := s.ReadRune(); c != r {
+			s.UnreadRune()
+			break
+		}
+	}
+	var ok bool
+	switch {
+	case n == 4:
+		if s.WriteRuneN(r, 4) {
+			e.inRun = r
+		}
+		return
+	case r == '.' && n == 3:
+		ok = s.WriteRune('\u2026')
+	case r == '-' && n == 2:
+		ok = s.WriteRune('\u2013')
+	case r == '-' && n == 3:
+		ok = s.WriteRune('\u2014')
+	default:
+		ok = s.WriteRuneN(r, n)
+	}
+	if ok {
+		e.inRun = 0
+	}
+}
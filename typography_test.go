@@ -0,0 +1,125 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestTypographicNormalizer(t *testing.T) {
+	normalize := NewTransformer(NewTypographicNormalizer())
+
+	testCases := []transformTest{{
+		desc:    "normalize",
+		szDst:   large,
+		atEOF:   true,
+		in:      "He said \u201cdon\u2019t\u201d \u2013 \u201ewait\u201c\u2014\u2018now\u2019\u2026",
+		out:     "He said \"don't\" - \"wait\"-'now'...",
+		outFull: "He said \"don't\" - \"wait\"-'now'...",
+		t:       normalize,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "ASCII",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\"a\" 'b' - ...",
+		out:     "\"a\" 'b' - ...",
+		outFull: "\"a\" 'b' - ...",
+		t:       normalize,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestTypographicEnhancer(t *testing.T) {
+	enhance := NewTransformer(NewTypographicEnhancer())
+
+	testCases := []transformTest{{
+		desc:    "enhance",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a--b---c...",
+		out:     "a\u2013b\u2014c\u2026",
+		outFull: "a\u2013b\u2014c\u2026",
+		t:       enhance,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "other runs",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a-b..c----d.....e-.-",
+		out:     "a-b..c----d.....e-.-",
+		outFull: "a-b..c----d.....e-.-",
+		t:       enhance,
+	}, {
+		desc:    "mixed runs",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a----...--",
+		out:     "a----\u2026\u2013",
+		outFull: "a----\u2026\u2013",
+		t:       enhance,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   5,
+	}, {
+		desc:    "run split across buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "a--",
+		out:     "a",
+		outFull: "a\u2013",
+		err:     transform.ErrShortSrc,
+		t:       enhance,
+		errSpan: transform.ErrShortSrc,
+		nSpan:   1,
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "a---",
+		out:     "a",
+		outFull: "a\u2014",
+		err:     transform.ErrShortDst,
+		t:       enhance,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	for _, in := range []string{"a--b", "x---", "...", "-----.--"} {
+		want := enhance.String(in)
+		for _, sz := range [][2]int{{1, 4}, {2, 8}, {3, 8}} {
+			if got, err := transformChunks(enhance, in, sz[0], sz[1]); got != want || err != nil {
+				t.Errorf("%q:%v: got %q, %v; want %q, <nil>", in, sz, got, err, want)
+			}
+		}
+	}
+}
+
+func TestTypographicRoundTrip(t *testing.T) {
+	normalize := NewTransformer(NewTypographicNormalizer())
+	enhance := NewTransformer(NewTypographicEnhancer())
+
+	// Ellipses and single hyphens survive a round trip.
+	roundTrip(t, enhance, normalize, []string{"", "a", "a-b...c", "-.-"})
+
+	// The normalization is lossy.
+	for _, in := range []string{"\u201cquote\u201d", "a\u2014b", "it\u2019s"} {
+		if got := enhance.String(normalize.String(in)); got == in {
+			t.Errorf("%q: round trip unexpectedly restored input", in)
+		}
+	}
+	for _, in := range []string{"a--b", "a---b"} {
+		if got := normalize.String(enhance.String(in)); got != "a-b" {
+			t.Errorf("%q: got %q; want %q", in, got, "a-b")
+		}
+	}
+}
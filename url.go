@@ -0,0 +1,164 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "unicode/utf8"
+
+// NewURLPercentEncoder returns a Rewriter that percent-encodes each byte of the
+// UTF-8 encoding of its input, except for ASCII letters and digits and the
+// characters in safe. For instance, a safe value of "-._~" leaves all
+// unreserved characters of RFC 3986 unchanged. Encoded bytes are written as %XX
+// with uppercase hexadecimal digits. Invalid UTF-8 is encoded as
+// utf8.RuneError. It panics if safe contains a percent sign or non-ASCII
+// characters.
+func NewURLPercentEncoder(safe string) Rewriter {
+	e := &percentEncoder{}
+	for _, c := range []byte(safe) {
+		if c == '%' || c >= utf8.RuneSelf {
+			panic("textutil: invalid safe character " + string(rune(c)))
+		}
+		e.safe[c] = true
+	}
+	return e
+}
+
+type percentEncoder struct {
+	safe [utf8.RuneSelf]bool
+}
+
+func (e *percentEncoder) Reset() {}
+
+func (e *percentEncoder) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+	case r < utf8.RuneSelf && e.safe[r]:
+	default:
+		var b [utf8.UTFMax]byte
+		var buf [3 * utf8.UTFMax]byte
+		n := utf8.EncodeRune(b[:], r)
+		for i, c := range b[:n] {
+			buf[3*i] = '%'
+			buf[3*i+1] = upperHexDigits[c>>4]
+			buf[3*i+2] = upperHexDigits[c&0xf]
+		}
+		s.WriteBytes(buf[:3*n])
+		return
+	}
+	s.WriteRune(r)
+}
+
+const upperHexDigits = "0123456789ABCDEF"
+
+// NewURLPercentDecoder returns a Rewriter that decodes each %XX sequence of its
+// input to the byte with hexadecimal value XX. Consecutive sequences that
+// denote the UTF-8 encoding of a rune are written as that rune. Other sequences
+// denoting bytes of 0x80 or higher are written as utf8.RuneError. A percent sign
+// that is not followed by two hexadecimal digits results in ErrInvalidEscape.
+func NewURLPercentDecoder() Rewriter {
+	return &percentDecoder{}
+}
+
+// NewURLQueryDecoder returns a Rewriter that decodes its input like
+// NewURLPercentDecoder and additionally replaces each plus sign with a space,
+// as is used in the query component of a URL.
+func NewURLQueryDecoder() Rewriter {
+	return &percentDecoder{plusAsSpace: true}
+}
+
+type percentDecoder struct {
+	plusAsSpace bool
+}
+
+func (d *percentDecoder) Reset() {}
+
+func (d *percentDecoder) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	switch {
+	case r == '+' && d.plusAsSpace:
+		s.WriteRune(' ')
+		return
+	case r != '%':
+		s.WriteRune(r)
+		return
+	}
+	c, ok := readPercentHex(s)
+	switch {
+	case !ok:
+		return
+	case c < utf8.RuneSelf:
+		s.WriteBytes([]byte{c})
+		return
+	case c < 0xc2 || 0xf4 < c:
+		// Not the first byte of a valid UTF-8 encoding.
+		s.WriteRune(utf8.RuneError)
+		return
+	}
+
+	// Collect the remaining bytes of the UTF-8 encoding as long as they are
+	// valid.
+	buf := [utf8.UTFMax]byte{c}
+	n, size := 1, 2
+	lo, hi := byte(0x80), byte(0xbf)
+	switch {
+	case c == 0xe0:
+		lo, size = 0xa0, 3
+	case c == 0xed:
+		hi, size = 0x9f, 3
+	case c >= 0xf0:
+		size = 4
+		if c == 0xf0 {
+			lo = 0x90
+		} else if c == 0xf4 {
+			hi = 0x8f
+		}
+	case c >= 0xe0:
+		size = 3
+	}
+	for ; n < size; n++ {
+		if p, sz := s.PeekRune(); p != '%' {
+			if sz == 0 && !s.IsAtEOF() {
+				return // PeekRune set ErrShortSrc.
+			}
+			break
+		}
+		s.ReadRune()
+		c, ok := readPercentHex(s)
+		if !ok {
+			return
+		}
+		if c < lo || hi < c {
+			// Leave the sequence for the next call to Rewrite.
+			s.UnreadRune()
+			s.UnreadRune()
+			s.UnreadRune()
+			break
+		}
+		buf[n] = c
+		lo, hi = 0x80, 0xbf
+	}
+	if n < size {
+		s.WriteRune(utf8.RuneError)
+	} else {
+		s.WriteBytes(buf[:n])
+	}
+}
+
+// readPercentHex reads the two hexadecimal digits following a percent sign. It
+// sets ErrInvalidEscape if they are not valid.
+func readPercentHex(s State) (c byte, ok bool) {
+	for i := 0; i < 2; i++ {
+		h, _ := s.ReadRune()
+		d, ok := unhex(h)
+		if !ok {
+			// ErrShortSrc takes precedence if ReadRune reached the end of a
+			// buffer.
+			s.SetError(ErrInvalidEscape)
+			return 0, false
+		}
+		c = c<<4 | d
+	}
+	return c, true
+}
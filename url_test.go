@@ -0,0 +1,224 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"net/url"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestURLPercentEncoder(t *testing.T) {
+	path := NewTransformer(NewURLPercentEncoder("-._~"))
+	testCases := []transformTest{{
+		desc:    "unreserved",
+		szDst:   large,
+		atEOF:   true,
+		in:      "azAZ09-._~",
+		out:     "azAZ09-._~",
+		outFull: "azAZ09-._~",
+		t:       path,
+	}, {
+		desc:    "reserved",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a b/c?d=e&f%g+",
+		out:     "a%20b%2Fc%3Fd%3De%26f%25g%2B",
+		outFull: "a%20b%2Fc%3Fd%3De%26f%25g%2B",
+		t:       path,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "multi-byte",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u00e9\u2208\U0001f600",
+		out:     "%C3%A9%E2%88%88%F0%9F%98%80",
+		outFull: "%C3%A9%E2%88%88%F0%9F%98%80",
+		t:       path,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\xffb",
+		out:     "a%EF%BF%BDb",
+		outFull: "a%EF%BF%BDb",
+		t:       path,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "no extra safe characters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ab-._~ ",
+		out:     "ab%2D%2E%5F%7E%20",
+		outFull: "ab%2D%2E%5F%7E%20",
+		t:       NewTransformer(NewURLPercentEncoder("")),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "extra safe characters",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a/b:c d",
+		out:     "a/b:c%20d",
+		outFull: "a/b:c%20d",
+		t:       NewTransformer(NewURLPercentEncoder("/:")),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   7,
+		atEOF:   true,
+		in:      "ab\u00e9",
+		out:     "ab",
+		outFull: "ab%C3%A9",
+		err:     transform.ErrShortDst,
+		t:       path,
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	// The output must be decoded to the input by net/url.
+	in := "H\u00e9llo, w\u00f8rld! a+b=c&d/e?f#g%h \U0001f600"
+	if got, err := url.PathUnescape(path.String(in)); got != in || err != nil {
+		t.Errorf("decoding: got %q, %v; want %q, <nil>", got, err, in)
+	}
+}
+
+func TestURLPercentDecoder(t *testing.T) {
+	dec := NewTransformer(NewURLPercentDecoder())
+	query := NewTransformer(NewURLQueryDecoder())
+	testCases := []transformTest{{
+		desc:    "ASCII",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a%20b%2fc%3F%25%2B+",
+		out:     "a b/c?%++",
+		outFull: "a b/c?%++",
+		t:       dec,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "plus as space",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a+b%2B%20c",
+		out:     "a b+ c",
+		outFull: "a b+ c",
+		t:       query,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   1,
+	}, {
+		desc:    "multi-byte",
+		szDst:   large,
+		atEOF:   true,
+		in:      "%C3%A9%e2%88%88%F0%9F%98%80%EF%BF%BD",
+		out:     "\u00e9\u2208\U0001f600\ufffd",
+		outFull: "\u00e9\u2208\U0001f600\ufffd",
+		t:       dec,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "%FFa%E2%88b%E2%41%C3%C3%A9%80%ED%A0%80%E2%88",
+		out:     "\ufffda\ufffdb\ufffdA\ufffd\u00e9\ufffd\ufffd\ufffd\ufffd\ufffd",
+		outFull: "\ufffda\ufffdb\ufffdA\ufffd\u00e9\ufffd\ufffd\ufffd\ufffd\ufffd",
+		t:       dec,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "unescaped",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a/b?c=d&e \u00e9",
+		out:     "a/b?c=d&e \u00e9",
+		outFull: "a/b?c=d&e \u00e9",
+		t:       dec,
+	}, {
+		desc:    "malformed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ab%2G",
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrInvalidEscape,
+		t:       dec,
+		errSpan: ErrInvalidEscape,
+	}, {
+		desc:    "malformed at end of input",
+		szDst:   large,
+		atEOF:   true,
+		in:      "ab%C3%A",
+		out:     "ab",
+		outFull: "ab",
+		err:     ErrInvalidEscape,
+		t:       dec,
+		errSpan: ErrInvalidEscape,
+	}, {
+		desc:    "escape at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "ab%2",
+		out:     "ab",
+		outFull: "ab",
+		err:     transform.ErrShortSrc,
+		t:       dec,
+		errSpan: transform.ErrShortSrc,
+	}, {
+		desc:    "incomplete UTF-8 at end of buffer",
+		szDst:   large,
+		atEOF:   false,
+		in:      "ab%E2%88",
+		out:     "ab",
+		outFull: "ab\ufffd",
+		err:     transform.ErrShortSrc,
+		t:       dec,
+		errSpan: transform.ErrShortSrc,
+	}, {
+		desc:    "short destination",
+		szDst:   3,
+		atEOF:   true,
+		in:      "ab%E2%88%88",
+		out:     "ab",
+		outFull: "ab\u2208",
+		err:     transform.ErrShortDst,
+		t:       dec,
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	// UTF-8 encodings split across calls to Transform.
+	in := "a%F0%9F%98%80%E2%88%88+%20"
+	for _, sz := range [][2]int{{1, 4}, {2, 4}, {5, 8}, {100, 100}} {
+		got, err := transformChunks(query, in, sz[0], sz[1])
+		if want := "a\U0001f600\u2208  "; got != want || err != nil {
+			t.Errorf("%v: got %+q, %v; want %+q, <nil>", sz, got, err, want)
+		}
+	}
+
+	roundTrip(t, NewTransformer(NewURLPercentEncoder("")), dec, []string{
+		"",
+		"abc",
+		"a b/c?d=e&f%g+",
+		"H\u00e9llo, w\u00f8rld! \U0001f600\ufffd",
+	})
+}
+
+func TestURLPercentEncoderInvalid(t *testing.T) {
+	for _, safe := range []string{"%", "-\u00e9"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%q: did not panic", safe)
+				}
+			}()
+			NewURLPercentEncoder(safe)
+		}()
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "errors"
+
+var (
+	// ErrWatermarkIncomplete is returned by ExtractWatermark if the text did
+	// not contain enough letters to carry all bits of the watermark.
+	ErrWatermarkIncomplete = errors.New("textutil: text too short to hold watermark")
+
+	// ErrWatermarkCorrupt is returned by ExtractWatermark if repetitions of the
+	// watermark in the text do not match.
+	ErrWatermarkCorrupt = errors.New("textutil: inconsistent watermark")
+)
+
+// WatermarkText returns a Transformer that embeds id in text by replacing
+// Latin letters with visually identical Cyrillic letters, such as а (U+0430)
+// for a. The n-th Latin letter that has such a homoglyph carries bit n%64 of
+// id: it is replaced if the bit is set. The output appears unchanged when
+// rendered and is stable under Unicode normalization. An id of 0 leaves the
+// text unchanged.
+//
+// Text should contain at least 64 letters with homoglyphs to hold all bits of
+// id. Text that already contains the Cyrillic homoglyphs cannot be watermarked
+// reliably.
+func WatermarkText(id uint64) Transformer {
+	return NewTransformer(&watermark{id: id})
+}
+
+// RemoveWatermark returns a Transformer that replaces the homoglyphs inserted
+// by WatermarkText with the original Latin letters, recording the watermark
+// for use by ExtractWatermark.
+func RemoveWatermark() Transformer {
+	return NewTransformer(&watermarkExtractor{})
+}
+
+// ExtractWatermark returns the id embedded in the text processed by t since
+// its last Reset. The Transformer t must have been created by RemoveWatermark.
+func ExtractWatermark(t Transformer) (uint64, error) {
+	r, ok := t.SpanningTransformer.(*rewriter)
+	if !ok {
+		return 0, errors.New("textutil: Transformer not created by RemoveWatermark")
+	}
+	w, ok := r.rewrite.(*watermarkExtractor)
+	switch {
+	case !ok:
+		return 0, errors.New("textutil: Transformer not created by RemoveWatermark")
+	case w.conflict:
+		return 0, ErrWatermarkCorrupt
+	case w.n < 64:
+		return 0, ErrWatermarkIncomplete
+	}
+	return w.id, nil
+}
+
+// homoglyphs maps Latin letters to visually identical Cyrillic letters.
+var homoglyphs = map[rune]rune{
+	'a': '\u0430', // CYRILLIC SMALL LETTER A
+	'c': '\u0441', // CYRILLIC SMALL LETTER ES
+	'e': '\u0435', // CYRILLIC SMALL LETTER IE
+	'i': '\u0456', // CYRILLIC SMALL LETTER BYELORUSSIAN-UKRAINIAN I
+	'j': '\u0458', // CYRILLIC SMALL LETTER JE
+	'o': '\u043e', // CYRILLIC SMALL LETTER O
+	'p': '\u0440', // CYRILLIC SMALL LETTER ER
+	's': '\u0455', // CYRILLIC SMALL LETTER DZE
+	'x': '\u0445', // CYRILLIC SMALL LETTER HA
+	'y': '\u0443', // CYRILLIC SMALL LETTER U
+	'A': '\u0410', // CYRILLIC CAPITAL LETTER A
+	'B': '\u0412', // CYRILLIC CAPITAL LETTER VE
+	'C': '\u0421', // CYRILLIC CAPITAL LETTER ES
+	'E': '\u0415', // CYRILLIC CAPITAL LETTER IE
+	'H': '\u041d', // CYRILLIC CAPITAL LETTER EN
+	'I': '\u0406', // CYRILLIC CAPITAL LETTER BYELORUSSIAN-UKRAINIAN I
+	'J': '\u0408', // CYRILLIC CAPITAL LETTER JE
+	'K': '\u041a', // CYRILLIC CAPITAL LETTER KA
+	'M': '\u041c', // CYRILLIC CAPITAL LETTER EM
+	'O': '\u041e', // CYRILLIC CAPITAL LETTER O
+	'P': '\u0420', // CYRILLIC CAPITAL LETTER ER
+	'S': '\u0405', // CYRILLIC CAPITAL LETTER DZE
+	'T': '\u0422', // CYRILLIC CAPITAL LETTER TE
+	'X': '\u0425', // CYRILLIC CAPITAL LETTER HA
+}
+
+var latinHomoglyphs = invert(homoglyphs)
+
+type watermark struct {
+	id uint64
+	n  int // number of carrier letters written
+}
+
+func (w *watermark) Reset() { w.n = 0 }
+
+func (w *watermark) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	c, ok := homoglyphs[r]
+	if !ok {
+		s.WriteRune(r)
+		return
+	}
+	if w.id>>uint(w.n%64)&1 == 0 {
+		c = r
+	}
+	if s.WriteRune(c) {
+		w.n++
+	}
+}
+
+type watermarkExtractor struct {
+	id       uint64
+	n        int // number of carrier letters read
+	conflict bool
+}
+
+func (w *watermarkExtractor) Reset() { *w = watermarkExtractor{} }
+
+func (w *watermarkExtractor) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	var bit uint64
+	if c, ok := latinHomoglyphs[r]; ok {
+		r, bit = c, 1
+	} else if _, ok := homoglyphs[r]; !ok {
+		s.WriteRune(r)
+		return
+	}
+	if !s.WriteRune(r) {
+		return
+	}
+	i := uint(w.n % 64)
+	switch {
+	case w.n < 64:
+		w.id |= bit << i
+	case w.id>>i&1 != bit:
+		w.conflict = true
+	}
+	w.n++
+}
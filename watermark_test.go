@@ -0,0 +1,97 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+const watermarkText = "The quick brown fox jumps over the lazy dog. " +
+	"Pack my box with five dozen liquor jugs. " +
+	"Sphinx of black quartz, judge my vow. " +
+	"How vexingly quick daft zebras jump! " +
+	"Jackdaws love my big sphinx of quartz."
+
+func TestWatermark(t *testing.T) {
+	testCases := []transformTest{{
+		desc:    "bits",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Tocsin",
+		out:     "Тoсѕin",
+		outFull: "Тoсѕin",
+		t:       WatermarkText(0x2d),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "zero",
+		szDst:   large,
+		atEOF:   true,
+		in:      watermarkText,
+		out:     watermarkText,
+		outFull: watermarkText,
+		t:       WatermarkText(0),
+	}, {
+		desc:    "remove",
+		szDst:   large,
+		atEOF:   true,
+		in:      "Тoсѕin",
+		out:     "Tocsin",
+		outFull: "Tocsin",
+		t:       RemoveWatermark(),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestExtractWatermark(t *testing.T) {
+	ids := []uint64{0, 1, 2, 0x2d, 1 << 63, 0xdeadbeefcafebabe, ^uint64(0)}
+	for _, id := range ids {
+		marked := WatermarkText(id).String(watermarkText)
+		// The watermark survives normalization.
+		marked = NFC().String(NFD().String(marked))
+
+		rm := RemoveWatermark()
+		if got := rm.String(marked); got != watermarkText {
+			t.Errorf("%x: got %q; want %q", id, got, watermarkText)
+		}
+		if got, err := ExtractWatermark(rm); got != id || err != nil {
+			t.Errorf("%x: got %x, %v; want %x, <nil>", id, got, err, id)
+		}
+	}
+}
+
+func TestExtractWatermarkErrors(t *testing.T) {
+	rm := RemoveWatermark()
+	rm.String("too short")
+	if _, err := ExtractWatermark(rm); err != ErrWatermarkIncomplete {
+		t.Errorf("got %v; want %v", err, ErrWatermarkIncomplete)
+	}
+
+	a := WatermarkText(1).String(watermarkText)
+	b := WatermarkText(2).String(watermarkText)
+	rm.String(a + b)
+	if _, err := ExtractWatermark(rm); err != ErrWatermarkCorrupt {
+		t.Errorf("got %v; want %v", err, ErrWatermarkCorrupt)
+	}
+
+	if _, err := ExtractWatermark(NFC()); err == nil {
+		t.Errorf("got no error for foreign Transformer")
+	}
+	if _, err := ExtractWatermark(WatermarkText(1)); err == nil {
+		t.Errorf("got no error for WatermarkText Transformer")
+	}
+
+	// Repetitions of the same watermark are consistent.
+	id := uint64(0x1234567890)
+	rm.String(WatermarkText(id).String(strings.Repeat(watermarkText, 3)))
+	if got, err := ExtractWatermark(rm); got != id || err != nil {
+		t.Errorf("got %x, %v; want %x, <nil>", got, err, id)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+// NewFullwidthNormalizer returns a Rewriter that replaces the fullwidth forms
+// of ASCII characters (U+FF01–U+FF5E) with the corresponding ASCII characters
+// and the ideographic space (U+3000) with a space. Other characters, including
+// halfwidth and fullwidth forms outside this range, are left unchanged.
+func NewFullwidthNormalizer() Rewriter {
+	return rewriterFunc(func(s State) {
+		switch r, _ := s.ReadRune(); {
+		case '\uff01' <= r && r <= '\uff5e':
+			s.WriteRune(r - 0xfee0)
+		case r == '\u3000':
+			s.WriteRune(' ')
+		default:
+			s.WriteRune(r)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestFullwidthNormalizer(t *testing.T) {
+	halfwidth := NewTransformer(NewFullwidthNormalizer())
+	testCases := []transformTest{{
+		desc:    "fullwidth ASCII",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\uff21\uff22\uff23\u3000\uff41\uff42\uff43\uff01\uff10\uff5e",
+		out:     "ABC abc!0~",
+		outFull: "ABC abc!0~",
+		t:       halfwidth,
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "mixed",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\u96fb\u8a71\uff1a\uff10\uff11 (ok)",
+		out:     "\u96fb\u8a71:01 (ok)",
+		outFull: "\u96fb\u8a71:01 (ok)",
+		t:       halfwidth,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("\u96fb\u8a71"),
+	}, {
+		desc:    "outside range",
+		szDst:   large,
+		atEOF:   true,
+		in:      "\uff00\uff5f\uff60\uff61\uff71\u30a2\uffe0\uffe5\u3001 abc",
+		out:     "\uff00\uff5f\uff60\uff61\uff71\u30a2\uffe0\uffe5\u3001 abc",
+		outFull: "\uff00\uff5f\uff60\uff61\uff71\u30a2\uffe0\uffe5\u3001 abc",
+		t:       halfwidth,
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "a\u00e9\uff41",
+		out:     "a",
+		outFull: "a\u00e9a",
+		err:     transform.ErrShortDst,
+		t:       halfwidth,
+		errSpan: transform.ErrEndOfSpan,
+		nSpan:   len("a\u00e9"),
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+
+	// The transformation is idempotent.
+	var all []rune
+	for r := rune(0x3000); r < 0x3010; r++ {
+		all = append(all, r)
+	}
+	for r := rune(0xff00); r < 0xfff0; r++ {
+		all = append(all, r)
+	}
+	once := halfwidth.String(string(all))
+	if twice := halfwidth.String(once); twice != once {
+		t.Errorf("not idempotent: got %+q; want %+q", twice, once)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import "golang.org/x/text/transform"
+
+// A WindowFunc returns the replacement for the rune window[center], given the
+// runes surrounding it.
+type WindowFunc func(window []rune, center int) rune
+
+// NewWindowTransformer returns a Transformer that replaces each rune with the
+// result of calling f with a window of radius runes on each side of it. Each
+// window has 2*radius+1 runes and center is always radius: at the edges of the
+// input, positions before the start or after the end are filled with fill.
+// The window passed to f is only valid during the call.
+//
+// The Transformer buffers radius runes, so f is not called for a rune until
+// radius runes following it have been read or the end of input is reached.
+func NewWindowTransformer(radius int, fill rune, f WindowFunc) Transformer {
+	if radius < 0 {
+		panic("textutil: negative window radius")
+	}
+	return NewTransformer(&window{radius: radius, fill: fill, f: f})
+}
+
+type window struct {
+	radius int
+	fill   rune
+	f      WindowFunc
+
+	// buf holds the last runes read. The last pending runes of buf have not
+	// yet been written. They are preceded by up to radius runes of context.
+	buf     []rune
+	pending int
+
+	scratch []rune
+	win     []rune
+}
+
+func (w *window) Reset() {
+	w.buf = w.buf[:0]
+	w.pending = 0
+}
+
+func (w *window) Rewrite(s State) {
+	r, _ := s.ReadRune()
+	// Peek to detect the end of input, in which case all pending runes are
+	// written. If more input may follow, ReadRune has set ErrShortSrc and we
+	// will be called again.
+	_, size := s.ReadRune()
+	s.UnreadRune()
+
+	buf := append(append(w.scratch[:0], w.buf...), r)
+	w.scratch = buf[:0]
+	pending := w.pending + 1
+	for pending > w.radius || (size == 0 && pending > 0) {
+		if !s.WriteRune(w.apply(buf, len(buf)-pending)) {
+			return
+		}
+		pending--
+	}
+	if size == 0 {
+		// Leave the state unchanged: either the input is exhausted or all
+		// results will be discarded.
+		return
+	}
+	if pending > 0 && s.IsSpan() {
+		// Holding back runes ends a span.
+		s.SetError(transform.ErrEndOfSpan)
+		return
+	}
+	if n := len(buf) - pending - w.radius; n > 0 {
+		buf = buf[n:]
+	}
+	w.buf = append(w.buf[:0], buf...)
+	w.pending = pending
+}
+
+// apply calls f for the rune at buf[i], padding the window with w.fill where
+// it extends beyond buf.
+func (w *window) apply(buf []rune, i int) rune {
+	win := w.win[:0]
+	for j := i - w.radius; j <= i+w.radius; j++ {
+		if j < 0 || j >= len(buf) {
+			win = append(win, w.fill)
+		} else {
+			win = append(win, buf[j])
+		}
+	}
+	w.win = win
+	return w.f(win, w.radius)
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode"
+
+	"golang.org/x/text/transform"
+)
+
+// smooth replaces a rune by its neighbors if they are equal and not the fill
+// rune 0.
+func smooth(w []rune, c int) rune {
+	if w[c-1] == w[c+1] && w[c-1] != 0 {
+		return w[c-1]
+	}
+	return w[c]
+}
+
+func TestWindow(t *testing.T) {
+	upper := func(w []rune, c int) rune { return unicode.ToUpper(w[c]) }
+	testCases := []transformTest{{
+		desc:    "radius zero",
+		szDst:   large,
+		atEOF:   true,
+		in:      "abc",
+		out:     "ABC",
+		outFull: "ABC",
+		t:       NewWindowTransformer(0, '_', upper),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "window at buffer boundary",
+		szDst:   large,
+		atEOF:   false,
+		in:      "abcd",
+		out:     "AB",
+		outFull: "ABCD",
+		err:     transform.ErrShortSrc,
+		t:       NewWindowTransformer(1, '_', upper),
+		errSpan: transform.ErrEndOfSpan,
+	}, {
+		desc:    "short destination",
+		szDst:   2,
+		atEOF:   true,
+		in:      "abcd",
+		out:     "A",
+		outFull: "ABCD",
+		err:     transform.ErrShortDst,
+		t:       NewWindowTransformer(2, '_', upper),
+		errSpan: transform.ErrEndOfSpan,
+	}}
+	for i, tt := range testCases {
+		tt.check(t, i)
+	}
+}
+
+func TestWindowContents(t *testing.T) {
+	var got []string
+	tr := NewWindowTransformer(2, '_', func(w []rune, c int) rune {
+		got = append(got, fmt.Sprintf("%s:%d", string(w), c))
+		return w[c]
+	})
+	if out := tr.String("abcdef"); out != "abcdef" {
+		t.Errorf("got %q; want %q", out, "abcdef")
+	}
+	want := []string{"__abc:2", "_abcd:2", "abcde:2", "bcdef:2", "cdef_:2", "def__:2"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	got = got[:0]
+	if out := tr.String("\u00e9"); out != "\u00e9" {
+		t.Errorf("got %q; want %q", out, "\u00e9")
+	}
+	if want := []string{"__\u00e9__:2"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("single rune: got %v; want %v", got, want)
+	}
+}
+
+func TestWindowSmooth(t *testing.T) {
+	testCases := []struct{ in, out string }{
+		{"", ""},
+		{"a", "a"},
+		{"ab", "ab"},
+		{"aabaa", "aaaaa"},
+		{"aabaaccdcxyz", "aaaaaccccxyz"},
+		{"héh", "hhh"},
+		{"hhéhh", "hhhhh"},
+	}
+	for _, tc := range testCases {
+		if got := NewWindowTransformer(1, 0, smooth).String(tc.in); got != tc.out {
+			t.Errorf("%q: got %q; want %q", tc.in, got, tc.out)
+		}
+	}
+}
+
+func TestWindowBoundary(t *testing.T) {
+	// Runes are buffered across the internal buffers of transform.String.
+	in := strings.Repeat("ab", 150)
+	want := in[:1] + in[:len(in)-2] + in[len(in)-1:]
+	shift := NewWindowTransformer(1, 0, func(w []rune, c int) rune {
+		if w[c-1] != 0 && w[c+1] != 0 {
+			return w[c-1]
+		}
+		return w[c]
+	})
+	if got := shift.String(in); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestWindowSpan(t *testing.T) {
+	identity := func(w []rune, c int) rune { return w[c] }
+	for radius := 0; radius < 3; radius++ {
+		tr := NewWindowTransformer(radius, 0, identity)
+		if got, err := spanTransform(tr, "Hello"); got != "Hello" || err != nil {
+			t.Errorf("%d: got %q, %v; want %q, <nil>", radius, got, err, "Hello")
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// A WordFrequency holds the number of occurrences of each word seen by the
+// Transformer returned by WordFrequencyCounter.
+type WordFrequency struct {
+	// Freq maps lowercased words to their number of occurrences.
+	Freq map[string]int
+}
+
+// WordFrequencyCounter returns a WordFrequency and a Transformer that copies
+// its input verbatim and counts the words in it. Words are separated by white
+// space, as defined by unicode.IsSpace, and stripped of leading and trailing
+// runes that are neither letters nor digits. A word is counted once it is
+// followed by white space or the end of input. Reset clears the counts. See
+// Synchronized for reading the counts during a transformation.
+func WordFrequencyCounter() (*WordFrequency, Transformer) {
+	w := &wordCounter{freq: &WordFrequency{Freq: map[string]int{}}}
+	return w.freq, Transformer{w}
+}
+
+type wordCounter struct {
+	freq *WordFrequency
+	// word holds the lowercased runes of the current word.
+	word []byte
+}
+
+func (w *wordCounter) Reset() {
+	w.freq.Freq = map[string]int{}
+	w.word = w.word[:0]
+}
+
+func (w *wordCounter) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	nDst, nSrc, err = copyRunes(dst, src, atEOF, w.add)
+	if atEOF && nSrc == len(src) {
+		w.count()
+	}
+	return nDst, nSrc, err
+}
+
+// copyRunes copies as many complete runes of src to dst as fit and calls f for
+// each of them, for Transformers that only inspect their input. Invalid UTF-8
+// is passed to f as utf8.RuneError.
+func copyRunes(dst, src []byte, atEOF bool, f func(r rune)) (nDst, nSrc int, err error) {
+	n := len(src)
+	if n > len(dst) {
+		n, err = len(dst), transform.ErrShortDst
+	}
+	for nSrc < n {
+		r, size := rune(src[nSrc]), 1
+		if r >= utf8.RuneSelf {
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				if err == nil {
+					err = transform.ErrShortSrc
+				}
+				break
+			}
+			r, size = utf8.DecodeRune(src[nSrc:])
+			if nSrc+size > n {
+				break
+			}
+		}
+		f(r)
+		nSrc += size
+	}
+	return copy(dst, src[:nSrc]), nSrc, err
+}
+
+// add adds r to the current word or, if it is white space, counts the word.
+func (w *wordCounter) add(r rune) {
+	if unicode.IsSpace(r) {
+		w.count()
+	} else {
+		w.word = utf8.AppendRune(w.word, unicode.ToLower(r))
+	}
+}
+
+// count counts the current word, if any.
+func (w *wordCounter) count() {
+	word := w.word
+	for len(word) > 0 {
+		r, size := utf8.DecodeRune(word)
+		if !isNotAlnum(r) {
+			break
+		}
+		word = word[size:]
+	}
+	for len(word) > 0 {
+		r, size := utf8.DecodeLastRune(word)
+		if !isNotAlnum(r) {
+			break
+		}
+		word = word[:len(word)-size]
+	}
+	if len(word) > 0 {
+		w.freq.Freq[string(word)]++
+	}
+	w.word = w.word[:0]
+}
+
+// Span reports the entire input as unchanged. Words are only counted by
+// Transform.
+func (w *wordCounter) Span(src []byte, atEOF bool) (n int, err error) {
+	return len(src), nil
+}
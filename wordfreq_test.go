@@ -0,0 +1,125 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textutil
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/text/transform"
+)
+
+func TestWordFrequency(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want map[string]int
+	}{
+		{"", map[string]int{}},
+		{"the cat and the hat", map[string]int{"the": 2, "cat": 1, "and": 1, "hat": 1}},
+		{"Hello hello HELLO", map[string]int{"hello": 3}},
+		{"Hello, world! (world)", map[string]int{"hello": 1, "world": 2}},
+		{"don't -- stop...\n\tdon't", map[string]int{"don't": 2, "stop": 1}},
+		{"Ünïcode ÜNÏCODE\u3000日本", map[string]int{"ünïcode": 2, "日本": 1}},
+	}
+	for _, tc := range testCases {
+		freq, tr := WordFrequencyCounter()
+		if got := tr.String(tc.in); got != tc.in {
+			t.Errorf("%q: output %q differs from input", tc.in, got)
+		}
+		if !reflect.DeepEqual(freq.Freq, tc.want) {
+			t.Errorf("%q: got %v; want %v", tc.in, freq.Freq, tc.want)
+		}
+	}
+}
+
+func TestWordFrequencyPassThrough(t *testing.T) {
+	testPassThrough(t, func() Transformer {
+		_, tr := WordFrequencyCounter()
+		return tr
+	})
+}
+
+// testPassThrough checks that the Transformers returned by newT copy their
+// input verbatim.
+func testPassThrough(t *testing.T, newT func() Transformer) {
+	testCases := []transformTest{{
+		desc:    "pass through",
+		szDst:   large,
+		atEOF:   true,
+		in:      input,
+		out:     input,
+		outFull: input,
+	}, {
+		desc:    "short destination",
+		szDst:   9,
+		atEOF:   true,
+		in:      "Thé qüick",
+		out:     "Thé qüi",
+		outFull: "Thé qüick",
+		err:     transform.ErrShortDst,
+	}, {
+		desc:    "incomplete rune",
+		szDst:   large,
+		atEOF:   false,
+		in:      "Thé qü\xc3",
+		out:     "Thé qü",
+		outFull: "Thé qü\xc3",
+		err:     transform.ErrShortSrc,
+		nSpan:   len("Thé qü\xc3"),
+	}, {
+		desc:    "invalid UTF-8",
+		szDst:   large,
+		atEOF:   true,
+		in:      "a\x80b \xff",
+		out:     "a\x80b \xff",
+		outFull: "a\x80b \xff",
+	}}
+	for i, tt := range testCases {
+		tt.t = newT()
+		tt.check(t, i)
+	}
+}
+
+func TestWordFrequencyBoundary(t *testing.T) {
+	// Words spanning the internal buffers of transform.String.
+	in := strings.Repeat("thé qüick brøwn føx ", 100)
+	freq, tr := WordFrequencyCounter()
+	tr.String(in)
+	want := map[string]int{"thé": 100, "qüick": 100, "brøwn": 100, "føx": 100}
+	if !reflect.DeepEqual(freq.Freq, want) {
+		t.Errorf("got %v; want %v", freq.Freq, want)
+	}
+
+	tr.Reset()
+	if len(freq.Freq) != 0 {
+		t.Errorf("after Reset: got %v; want none", freq.Freq)
+	}
+}
+
+func TestWordFrequencySynchronized(t *testing.T) {
+	var mu sync.Mutex
+	freq, tr := WordFrequencyCounter()
+	tr = Synchronized(tr, &mu)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tr.String(strings.Repeat("a b ", 1000))
+	}()
+	for i := 0; i < 100; i++ {
+		mu.Lock()
+		if a, b := freq.Freq["a"], freq.Freq["b"]; a < b {
+			t.Errorf("got a: %d, b: %d; want a >= b", a, b)
+		}
+		mu.Unlock()
+	}
+	wg.Wait()
+	if a := freq.Freq["a"]; a != 1000 {
+		t.Errorf("got %d; want 1000", a)
+	}
+}